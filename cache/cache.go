@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"Enterprise/data"
+	"encoding/json"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/context"
+	"golang.org/x/sync/singleflight"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Namespaces group cached entries so that one write can invalidate all of them at once.
+const (
+	Products   = "products"
+	Categories = "categories"
+)
+
+const (
+	lockTTL      = 5 * time.Second
+	lockWait     = 2 * time.Second
+	lockPollTime = 50 * time.Millisecond
+)
+
+type Cache struct {
+	Client *redis.Client
+	TTL    time.Duration
+
+	group  singleflight.Group
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+type Stats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	Errors  int64   `json:"errors"`
+	HitRate float64 `json:"hitRate"`
+}
+
+type cachedResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	ETag    string          `json:"etag,omitempty"`
+}
+
+func NewCache(client *redis.Client, ttl time.Duration) *Cache {
+	return &Cache{Client: client, TTL: ttl}
+}
+
+// Remember returns the cached response stored under namespace/key, or runs load and caches its result.
+// Only 200 responses are cached. Concurrent misses for the same key share one load, in this process
+// through singleflight and across processes through a short-lived Redis lock.
+func (c *Cache) Remember(ctx context.Context, namespace string, key string, load func() *data.WebResponse) *data.WebResponse {
+	if c == nil || c.Client == nil {
+		return load()
+	}
+
+	version, err := c.Client.Get(ctx, versionKey(namespace)).Int64()
+	if err != nil && err != redis.Nil {
+		c.errors.Add(1)
+		return load()
+	}
+	fullKey := fmt.Sprintf("cache:%s:v%d:%s", namespace, version, key)
+
+	if response, ok := c.get(ctx, fullKey); ok {
+		c.hits.Add(1)
+		return response
+	}
+	c.misses.Add(1)
+
+	result, _, _ := c.group.Do(fullKey, func() (interface{}, error) {
+		locked, err := c.Client.SetNX(ctx, "lock:"+fullKey, 1, lockTTL).Result()
+		if err == nil && !locked {
+			// Another instance is already loading this key, give it a moment to fill the cache.
+			deadline := time.Now().Add(lockWait)
+			for time.Now().Before(deadline) {
+				time.Sleep(lockPollTime)
+				if response, ok := c.get(ctx, fullKey); ok {
+					return response, nil
+				}
+			}
+		}
+		if locked {
+			defer c.Client.Del(ctx, "lock:"+fullKey)
+		}
+
+		response := load()
+		if response.Code == http.StatusOK {
+			c.set(ctx, fullKey, response)
+		}
+		return response, nil
+	})
+	return result.(*data.WebResponse)
+}
+
+// Invalidate drops every entry in the given namespaces by moving them to a new version.
+func (c *Cache) Invalidate(ctx context.Context, namespaces ...string) {
+	if c == nil || c.Client == nil {
+		return
+	}
+	for _, namespace := range namespaces {
+		if err := c.Client.Incr(ctx, versionKey(namespace)).Err(); err != nil {
+			c.errors.Add(1)
+			log.Error().Err(err).Msgf("Invalidating %s cache failed", namespace)
+		}
+	}
+}
+
+func (c *Cache) Stats() Stats {
+	stats := Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+func (c *Cache) get(ctx context.Context, key string) (*data.WebResponse, bool) {
+	raw, err := c.Client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			c.errors.Add(1)
+		}
+		return nil, false
+	}
+	cached := cachedResponse{}
+	if err = json.Unmarshal(raw, &cached); err != nil {
+		return nil, false
+	}
+	return &data.WebResponse{
+		Code:    cached.Code,
+		Message: cached.Message,
+		Data:    cached.Data,
+		ETag:    cached.ETag,
+	}, true
+}
+
+func (c *Cache) set(ctx context.Context, key string, response *data.WebResponse) {
+	body, err := json.Marshal(response.Data)
+	if err != nil {
+		return
+	}
+	raw, err := json.Marshal(cachedResponse{
+		Code:    response.Code,
+		Message: response.Message,
+		Data:    body,
+		ETag:    response.ETag,
+	})
+	if err != nil {
+		return
+	}
+	// Jitter the expiry so that entries written together do not all expire together.
+	ttl := c.TTL + time.Duration(rand.Int63n(int64(c.TTL/10)+1))
+	if err = c.Client.Set(ctx, key, raw, ttl).Err(); err != nil {
+		c.errors.Add(1)
+	}
+}
+
+func versionKey(namespace string) string {
+	return "cache:version:" + namespace
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"golang.org/x/net/context"
 	"os"
+	"time"
 )
 
 // ConnectRedis func: helps reach the redis server
@@ -24,3 +25,27 @@ func ConnectRedis() (*redis.Client, error) {
 	}
 	return rdb, nil
 }
+
+// CatalogCacheTTL func: how long cached product and category reads live, from CACHE_TTL (default 5m). Cached
+// products carry signed attachment URLs, so the TTL is capped at a fifth of their expiry, which keeps it under
+// the ETag window of helpers.ExpiringETag even with the cache's jitter.
+func CatalogCacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("CACHE_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	if limit := AttachmentURLExpiry() / 5; ttl > limit {
+		return limit
+	}
+	return ttl
+}
+
+// IdempotencyTTL func: how long responses to requests with an Idempotency-Key are kept for replay, from
+// IDEMPOTENCY_TTL (default 24h)
+func IdempotencyTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_TTL"))
+	if err != nil || ttl <= 0 {
+		return 24 * time.Hour
+	}
+	return ttl
+}
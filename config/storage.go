@@ -0,0 +1,45 @@
+package config
+
+import (
+	"Enterprise/storage"
+	"github.com/rs/zerolog/log"
+	"os"
+	"time"
+)
+
+// ConnectStorage func: picks the file storage backend from STORAGE_DRIVER ("local" or "s3")
+func ConnectStorage() (storage.Storage, error) {
+	signingKey := os.Getenv("STORAGE_SIGNING_KEY")
+	if signingKey == "" {
+		signingKey = os.Getenv("JWT_KEY")
+	}
+
+	switch os.Getenv("STORAGE_DRIVER") {
+	case "s3":
+		log.Printf("Using S3 storage bucket %s at %s", os.Getenv("S3_BUCKET"), os.Getenv("S3_ENDPOINT"))
+		return storage.NewS3Storage(
+			os.Getenv("S3_ENDPOINT"),
+			os.Getenv("S3_ACCESS_KEY"),
+			os.Getenv("S3_SECRET_KEY"),
+			os.Getenv("S3_BUCKET"),
+			os.Getenv("S3_REGION"),
+			os.Getenv("S3_USE_SSL") != "false",
+		)
+	default:
+		basePath := os.Getenv("STORAGE_LOCAL_PATH")
+		if basePath == "" {
+			basePath = "uploads"
+		}
+		log.Printf("Using local storage at %s", basePath)
+		return storage.NewLocalStorage(basePath, os.Getenv("BACKEND_URL"), []byte(signingKey))
+	}
+}
+
+// AttachmentURLExpiry func: how long signed attachment download URLs stay valid, from ATTACHMENT_URL_EXPIRY (default 15m)
+func AttachmentURLExpiry() time.Duration {
+	expiry, err := time.ParseDuration(os.Getenv("ATTACHMENT_URL_EXPIRY"))
+	if err != nil || expiry <= 0 {
+		return 15 * time.Minute
+	}
+	return expiry
+}
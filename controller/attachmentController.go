@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type AttachmentController struct {
+	AttachmentService *service.AttachmentService
+}
+
+func NewAttachmentController(attachmentService *service.AttachmentService) *AttachmentController {
+	return &AttachmentController{
+		AttachmentService: attachmentService,
+	}
+}
+
+func (controller AttachmentController) UploadAttachment(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxAttachmentSize()+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		helpers.WriteResponseBody(w, &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "A file must be sent in the \"file\" form field: " + err.Error(),
+			Data:    nil,
+		}, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		helpers.WriteResponseBody(w, &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Data:    nil,
+		}, http.StatusBadRequest)
+		return
+	}
+
+	uploadModel := model.AttachmentUploadModel{
+		FileName: filepath.Base(header.Filename),
+		Content:  content,
+	}
+	userId := r.Context().Value("userId").(int)
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	uploadModel.ProductId = id
+	uploadModel.UserId = userId
+	webResponse := controller.AttachmentService.UploadAttachment(r.Context(), &uploadModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller AttachmentController) DeleteAttachment(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	attachmentId := params.ByName("attachmentId")
+	id, _ := strconv.Atoi(attachmentId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.AttachmentService.DeleteAttachment(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller AttachmentController) DownloadFile(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	key := strings.TrimPrefix(params.ByName("key"), "/")
+	query := r.URL.Query()
+	file, webResponse := controller.AttachmentService.GetFile(r.Context(), key, query.Get("expires"), query.Get("signature"))
+	writeFile(w, file, webResponse, "inline")
+}
+
+// writeFile streams a file produced by a service, or its error response when there is no file.
+func writeFile(w http.ResponseWriter, file *model.FileDownload, webResponse *data.WebResponse, disposition string) {
+	if webResponse != nil {
+		helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+		return
+	}
+	defer file.Body.Close()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, file.FileName))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, file.Body)
+}
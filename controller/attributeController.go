@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type AttributeController struct {
+	AttributeService *service.AttributeService
+}
+
+func NewAttributeController(attributeService *service.AttributeService) *AttributeController {
+	return &AttributeController{
+		AttributeService: attributeService,
+	}
+}
+
+func (controller AttributeController) SetCategoryAttributes(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	attributesModel := model.CategoryAttributesModel{}
+	helpers.ReadRequestBody(r, &attributesModel)
+	userId := r.Context().Value("userId").(int)
+	categoryId := params.ByName("categoryId")
+	id, _ := strconv.Atoi(categoryId)
+	attributesModel.CategoryId = id
+	attributesModel.UserId = userId
+	webResponse := controller.AttributeService.SetCategoryAttributes(r.Context(), &attributesModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller AttributeController) GetCategoryAttributes(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	categoryId := params.ByName("categoryId")
+	id, _ := strconv.Atoi(categoryId)
+	webResponse := controller.AttributeService.GetCategoryAttributes(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type BundleController struct {
+	BundleService *service.BundleService
+}
+
+func NewBundleController(bundleService *service.BundleService) *BundleController {
+	return &BundleController{
+		BundleService: bundleService,
+	}
+}
+
+func (controller BundleController) SetBundleComponents(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	bundleModel := model.BundleModel{}
+	helpers.ReadRequestBody(r, &bundleModel)
+	userId := r.Context().Value("userId").(int)
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	bundleModel.ProductId = id
+	bundleModel.UserId = userId
+	webResponse := controller.BundleService.SetBundleComponents(r.Context(), &bundleModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller BundleController) RemoveBundle(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.BundleService.RemoveBundle(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller BundleController) GetBundle(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	webResponse := controller.BundleService.GetBundle(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
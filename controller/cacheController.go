@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+type CacheController struct {
+	Cache *cache.Cache
+}
+
+func NewCacheController(catalogCache *cache.Cache) *CacheController {
+	return &CacheController{
+		Cache: catalogCache,
+	}
+}
+
+func (controller CacheController) CacheStats(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	webResponse := &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Cache statistics",
+		Data:    controller.Cache.Stats(),
+	}
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
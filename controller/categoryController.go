@@ -33,20 +33,21 @@ func (controller CategoryController) GetCategoryById(w http.ResponseWriter, r *h
 	categoryId := params.ByName("categoryId")
 	id, _ := strconv.Atoi(categoryId)
 	webResponse := controller.CategoryService.GetCategoryById(r.Context(), id)
-	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+	helpers.WriteConditionalResponse(w, r, webResponse)
 }
 
 func (controller CategoryController) DeleteCategory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	categoryId := params.ByName("categoryId")
 	userId := r.Context().Value("userId").(int)
 	id, _ := strconv.Atoi(categoryId)
-	webResponse := controller.CategoryService.DeleteCategory(r.Context(), id, userId)
+	webResponse := controller.CategoryService.DeleteCategory(r.Context(), id, userId, r.Header.Get("If-Match"))
 	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
 }
 
 func (controller CategoryController) GetAllCategories(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	webResponse := controller.CategoryService.GetAllCategories(r.Context())
-	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+	tree := r.URL.Query().Get("tree") == "true"
+	webResponse := controller.CategoryService.GetAllCategories(r.Context(), tree)
+	helpers.WriteConditionalResponse(w, r, webResponse)
 }
 
 func (controller CategoryController) AuditLogs(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
@@ -62,6 +63,47 @@ func (controller CategoryController) UpdateCategory(w http.ResponseWriter, r *ht
 	categoryDto.UserId = userId
 	id, _ := strconv.Atoi(categoryId)
 	categoryDto.CategoryId = id
+	categoryDto.IfMatch = r.Header.Get("If-Match")
 	webResponse := controller.CategoryService.UpdateCategory(r.Context(), &categoryDto)
 	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
 }
+
+func (controller CategoryController) RestoreCategory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	categoryId := params.ByName("categoryId")
+	userId := r.Context().Value("userId").(int)
+	id, _ := strconv.Atoi(categoryId)
+	webResponse := controller.CategoryService.RestoreCategory(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller CategoryController) GetDeletedCategories(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	webResponse := controller.CategoryService.GetDeletedCategories(r.Context())
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller CategoryController) MoveCategory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	categoryId := params.ByName("categoryId")
+	moveDto := model.CategoryMoveModel{}
+	helpers.ReadRequestBody(r, &moveDto)
+	userId := r.Context().Value("userId").(int)
+	moveDto.UserId = userId
+	id, _ := strconv.Atoi(categoryId)
+	moveDto.CategoryId = id
+	moveDto.IfMatch = r.Header.Get("If-Match")
+	webResponse := controller.CategoryService.MoveCategory(r.Context(), &moveDto)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller CategoryController) GetCategoryBreadcrumb(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	categoryId := params.ByName("categoryId")
+	id, _ := strconv.Atoi(categoryId)
+	webResponse := controller.CategoryService.GetCategoryBreadcrumb(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller CategoryController) GetCategoryProducts(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	categoryId := params.ByName("categoryId")
+	id, _ := strconv.Atoi(categoryId)
+	webResponse := controller.CategoryService.GetCategoryProducts(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type LabelController struct {
+	LabelService *service.LabelService
+}
+
+func NewLabelController(labelService *service.LabelService) *LabelController {
+	return &LabelController{
+		LabelService: labelService,
+	}
+}
+
+func (controller LabelController) ProductBarcode(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	query := r.URL.Query()
+	file, webResponse := controller.LabelService.ProductBarcode(r.Context(), id, query.Get("type"), query.Get("format"))
+	writeFile(w, file, webResponse, "inline")
+}
+
+func (controller LabelController) VariantBarcode(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	query := r.URL.Query()
+	file, webResponse := controller.LabelService.VariantBarcode(r.Context(), params.ByName("sku"), query.Get("type"), query.Get("format"))
+	writeFile(w, file, webResponse, "inline")
+}
+
+func (controller LabelController) LabelSheet(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	sheetModel := model.LabelSheetModel{}
+	helpers.ReadRequestBody(r, &sheetModel)
+	file, webResponse := controller.LabelService.LabelSheet(r.Context(), &sheetModel)
+	writeFile(w, file, webResponse, "attachment")
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type LotController struct {
+	LotService *service.LotService
+}
+
+func NewLotController(lotService *service.LotService) *LotController {
+	return &LotController{
+		LotService: lotService,
+	}
+}
+
+func (controller LotController) GetProductLots(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	warehouseId, _ := strconv.Atoi(r.URL.Query().Get("warehouseId"))
+	webResponse := controller.LotService.GetProductLots(r.Context(), id, warehouseId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller LotController) GetExpiringLots(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, _ = strconv.Atoi(value)
+	}
+	warehouseId, _ := strconv.Atoi(r.URL.Query().Get("warehouseId"))
+	webResponse := controller.LotService.GetExpiringLots(r.Context(), days, warehouseId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller LotController) GetLotRecall(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	webResponse := controller.LotService.GetLotRecall(r.Context(), id, r.URL.Query().Get("lotNumber"))
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
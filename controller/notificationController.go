@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type NotificationController struct {
+	NotificationService *service.NotificationService
+}
+
+func NewNotificationController(notificationService *service.NotificationService) *NotificationController {
+	return &NotificationController{
+		NotificationService: notificationService,
+	}
+}
+
+func (controller NotificationController) GetNotifications(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userId := r.Context().Value("userId").(int)
+	unreadOnly, _ := strconv.ParseBool(r.URL.Query().Get("unread"))
+	webResponse := controller.NotificationService.GetNotifications(r.Context(), userId, unreadOnly)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller NotificationController) MarkNotificationRead(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userId := r.Context().Value("userId").(int)
+	notificationId := params.ByName("notificationId")
+	id, _ := strconv.Atoi(notificationId)
+	webResponse := controller.NotificationService.MarkNotificationRead(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller NotificationController) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.NotificationService.MarkAllNotificationsRead(r.Context(), userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type OrderController struct {
+	OrderService *service.OrderService
+}
+
+func NewOrderController(orderService *service.OrderService) *OrderController {
+	return &OrderController{
+		OrderService: orderService,
+	}
+}
+
+func (controller OrderController) CreateOrder(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	orderModel := model.OrderModel{}
+	helpers.ReadRequestBody(r, &orderModel)
+	userId := r.Context().Value("userId").(int)
+	orderModel.UserId = userId
+	webResponse := controller.OrderService.CreateOrder(r.Context(), &orderModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller OrderController) UpdateOrderStatus(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	statusModel := model.OrderStatusModel{}
+	helpers.ReadRequestBody(r, &statusModel)
+	userId := r.Context().Value("userId").(int)
+	orderId := params.ByName("orderId")
+	id, _ := strconv.Atoi(orderId)
+	statusModel.OrderId = id
+	statusModel.UserId = userId
+	webResponse := controller.OrderService.UpdateOrderStatus(r.Context(), &statusModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller OrderController) CancelOrder(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	statusModel := model.OrderStatusModel{}
+	helpers.ReadRequestBody(r, &statusModel)
+	userId := r.Context().Value("userId").(int)
+	orderId := params.ByName("orderId")
+	id, _ := strconv.Atoi(orderId)
+	statusModel.OrderId = id
+	statusModel.UserId = userId
+	webResponse := controller.OrderService.CancelOrder(r.Context(), &statusModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller OrderController) GetOrderHistory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userId := r.Context().Value("userId").(int)
+	orderId := params.ByName("orderId")
+	id, _ := strconv.Atoi(orderId)
+	webResponse := controller.OrderService.GetOrderHistory(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller OrderController) GetOrders(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	filter, ok := orderFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.UserId, ok = intParam(w, r, "userId")
+	if !ok {
+		return
+	}
+	controller.writeOrders(w, r, &filter)
+}
+
+func (controller OrderController) GetMyOrders(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	filter, ok := orderFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.UserId = r.Context().Value("userId").(int)
+	controller.writeOrders(w, r, &filter)
+}
+
+func (controller OrderController) GetOrderById(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userId := r.Context().Value("userId").(int)
+	orderId := params.ByName("orderId")
+	id, _ := strconv.Atoi(orderId)
+	webResponse := controller.OrderService.GetOrderById(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller OrderController) writeOrders(w http.ResponseWriter, r *http.Request, filter *model.OrderFilterModel) {
+	webResponse := controller.OrderService.GetOrders(r.Context(), filter)
+	if meta, ok := webResponse.Meta.(data.Meta); ok {
+		webResponse.Links = helpers.PaginationLinks(r.URL, meta)
+	}
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+// orderFilter reads the order listing filters from the query.
+func orderFilter(w http.ResponseWriter, r *http.Request) (model.OrderFilterModel, bool) {
+	query := r.URL.Query()
+	filter := model.OrderFilterModel{
+		Status: query.Get("status"),
+		Search: query.Get("search"),
+	}
+	var ok bool
+	if filter.Page, ok = intParam(w, r, "page"); !ok {
+		return filter, false
+	}
+	if filter.PerPage, ok = intParam(w, r, "per_page"); !ok {
+		return filter, false
+	}
+	if filter.MinAmount, ok = amountParam(w, r, "minAmount"); !ok {
+		return filter, false
+	}
+	if filter.MaxAmount, ok = amountParam(w, r, "maxAmount"); !ok {
+		return filter, false
+	}
+	from, ok := timeParam(w, r, "from", time.Time{})
+	if !ok {
+		return filter, false
+	}
+	if !from.IsZero() {
+		filter.From = &from
+	}
+	to, ok := timeParam(w, r, "to", time.Time{})
+	if !ok {
+		return filter, false
+	}
+	if !to.IsZero() {
+		filter.To = &to
+	}
+	return filter, true
+}
+
+// intParam reads a whole number from the query, 0 when it is absent, answering 400 when it does not parse.
+func intParam(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, true
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		helpers.WriteResponseBody(w, &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: name + " must be a whole number",
+			Data:    nil,
+		}, http.StatusBadRequest)
+		return 0, false
+	}
+	return parsed, true
+}
+
+// amountParam reads an amount from the query, nil when it is absent, answering 400 when it does not parse.
+func amountParam(w http.ResponseWriter, r *http.Request, name string) (*float64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+		helpers.WriteResponseBody(w, &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: name + " must be a number",
+			Data:    nil,
+		}, http.StatusBadRequest)
+		return nil, false
+	}
+	return &parsed, true
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type PriceController struct {
+	PriceService *service.PriceService
+}
+
+func NewPriceController(priceService *service.PriceService) *PriceController {
+	return &PriceController{
+		PriceService: priceService,
+	}
+}
+
+func (controller PriceController) SchedulePriceChange(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	priceModel := model.PriceChangeModel{}
+	helpers.ReadRequestBody(r, &priceModel)
+	userId := r.Context().Value("userId").(int)
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	priceModel.ProductId = id
+	priceModel.UserId = userId
+	webResponse := controller.PriceService.SchedulePriceChange(r.Context(), &priceModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PriceController) CancelPriceChange(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	priceChangeId := params.ByName("priceChangeId")
+	id, _ := strconv.Atoi(priceChangeId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.PriceService.CancelPriceChange(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PriceController) GetPriceHistory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	webResponse := controller.PriceService.GetPriceHistory(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PriceController) GetEffectivePrice(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	at := time.Now()
+	if value := r.URL.Query().Get("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			helpers.WriteResponseBody(w, &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: "at must be an RFC3339 timestamp",
+				Data:    nil,
+			}, http.StatusBadRequest)
+			return
+		}
+		at = parsed
+	}
+	webResponse := controller.PriceService.GetEffectivePrice(r.Context(), id, at)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type PriceListController struct {
+	PriceListService *service.PriceListService
+}
+
+func NewPriceListController(priceListService *service.PriceListService) *PriceListController {
+	return &PriceListController{
+		PriceListService: priceListService,
+	}
+}
+
+func (controller PriceListController) CreatePriceList(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	priceListModel := model.PriceListModel{}
+	helpers.ReadRequestBody(r, &priceListModel)
+	userId := r.Context().Value("userId").(int)
+	priceListModel.UserId = userId
+	webResponse := controller.PriceListService.CreatePriceList(r.Context(), &priceListModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PriceListController) UpdatePriceList(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	priceListModel := model.PriceListModel{}
+	helpers.ReadRequestBody(r, &priceListModel)
+	userId := r.Context().Value("userId").(int)
+	priceListId := params.ByName("priceListId")
+	id, _ := strconv.Atoi(priceListId)
+	priceListModel.PriceListId = id
+	priceListModel.UserId = userId
+	webResponse := controller.PriceListService.UpdatePriceList(r.Context(), &priceListModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PriceListController) DeletePriceList(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	priceListId := params.ByName("priceListId")
+	id, _ := strconv.Atoi(priceListId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.PriceListService.DeletePriceList(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PriceListController) GetPriceLists(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	webResponse := controller.PriceListService.GetPriceLists(r.Context())
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PriceListController) GetPriceListById(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	priceListId := params.ByName("priceListId")
+	id, _ := strconv.Atoi(priceListId)
+	webResponse := controller.PriceListService.GetPriceListById(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PriceListController) SetPriceListItems(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	itemsModel := model.PriceListItemsModel{}
+	helpers.ReadRequestBody(r, &itemsModel)
+	userId := r.Context().Value("userId").(int)
+	priceListId := params.ByName("priceListId")
+	id, _ := strconv.Atoi(priceListId)
+	itemsModel.PriceListId = id
+	itemsModel.UserId = userId
+	webResponse := controller.PriceListService.SetPriceListItems(r.Context(), &itemsModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PriceListController) CreateExchangeRate(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	rateModel := model.ExchangeRateModel{}
+	helpers.ReadRequestBody(r, &rateModel)
+	userId := r.Context().Value("userId").(int)
+	rateModel.UserId = userId
+	webResponse := controller.PriceListService.CreateExchangeRate(r.Context(), &rateModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PriceListController) GetExchangeRates(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	webResponse := controller.PriceListService.GetExchangeRates(r.Context())
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+// ResolvePrice quotes a product, or its ?variantId=, for ?quantity= units in ?currency=, for the customer group
+// given by ?customerGroup= or else that of ?userId= (the caller by default).
+func (controller PriceListController) ResolvePrice(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	query := r.URL.Query()
+	userId := r.Context().Value("userId").(int)
+	if value := query.Get("userId"); value != "" {
+		userId, _ = strconv.Atoi(value)
+	}
+	var variantId *int
+	if value, err := strconv.Atoi(query.Get("variantId")); err == nil {
+		variantId = &value
+	}
+	quantity, _ := strconv.Atoi(query.Get("quantity"))
+	group := strings.ToUpper(query.Get("customerGroup"))
+	currency := strings.ToUpper(query.Get("currency"))
+	webResponse := controller.PriceListService.ResolvePrice(r.Context(), id, variantId, userId, group, quantity, currency)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
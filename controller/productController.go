@@ -7,6 +7,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 type ProductController struct {
@@ -36,6 +37,7 @@ func (controller ProductController) UpdateProduct(w http.ResponseWriter, r *http
 	id, _ := strconv.Atoi(productId)
 	productModel.ProductId = id
 	productModel.UserId = userId
+	productModel.IfMatch = r.Header.Get("If-Match")
 	webResponse := controller.ProductService.UpdateProduct(r.Context(), &productModel)
 	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
 }
@@ -44,20 +46,26 @@ func (controller ProductController) GetProductById(w http.ResponseWriter, r *htt
 	productId := params.ByName("productId")
 	id, _ := strconv.Atoi(productId)
 	webResponse := controller.ProductService.GetProductById(r.Context(), id)
-	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+	helpers.WriteConditionalResponse(w, r, webResponse)
 }
 
 func (controller ProductController) DeleteProductById(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	productId := params.ByName("productId")
 	id, _ := strconv.Atoi(productId)
 	userId := r.Context().Value("userId").(int)
-	webResponse := controller.ProductService.DeleteProductById(r.Context(), id, userId)
+	webResponse := controller.ProductService.DeleteProductById(r.Context(), id, userId, r.Header.Get("If-Match"))
 	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
 }
 
 func (controller ProductController) GetAllProducts(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	webResponse := controller.ProductService.GetAllProducts(r.Context())
-	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+	filters := map[string]string{}
+	for key, values := range r.URL.Query() {
+		if name, ok := strings.CutPrefix(key, "attr."); ok && name != "" && len(values) > 0 {
+			filters[name] = values[0]
+		}
+	}
+	webResponse := controller.ProductService.GetAllProducts(r.Context(), filters)
+	helpers.WriteConditionalResponse(w, r, webResponse)
 }
 
 func (controller ProductController) UpdateProductStock(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
@@ -68,6 +76,20 @@ func (controller ProductController) UpdateProductStock(w http.ResponseWriter, r
 	id, _ := strconv.Atoi(productId)
 	productModel.ProductId = id
 	productModel.UserId = userId
+	productModel.IfMatch = r.Header.Get("If-Match")
 	webResponse := controller.ProductService.UpdateProductStock(r.Context(), &productModel)
 	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
 }
+
+func (controller ProductController) RestoreProduct(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.ProductService.RestoreProduct(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller ProductController) GetDeletedProducts(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	webResponse := controller.ProductService.GetDeletedProducts(r.Context())
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
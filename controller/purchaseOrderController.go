@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type PurchaseOrderController struct {
+	PurchaseOrderService *service.PurchaseOrderService
+}
+
+func NewPurchaseOrderController(purchaseOrderService *service.PurchaseOrderService) *PurchaseOrderController {
+	return &PurchaseOrderController{
+		PurchaseOrderService: purchaseOrderService,
+	}
+}
+
+func (controller PurchaseOrderController) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	purchaseOrderModel := model.PurchaseOrderModel{}
+	helpers.ReadRequestBody(r, &purchaseOrderModel)
+	userId := r.Context().Value("userId").(int)
+	purchaseOrderModel.UserId = userId
+	webResponse := controller.PurchaseOrderService.CreatePurchaseOrder(r.Context(), &purchaseOrderModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PurchaseOrderController) UpdatePurchaseOrder(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	purchaseOrderModel := model.PurchaseOrderModel{}
+	helpers.ReadRequestBody(r, &purchaseOrderModel)
+	userId := r.Context().Value("userId").(int)
+	purchaseOrderId := params.ByName("purchaseOrderId")
+	id, _ := strconv.Atoi(purchaseOrderId)
+	purchaseOrderModel.PurchaseOrderId = id
+	purchaseOrderModel.UserId = userId
+	webResponse := controller.PurchaseOrderService.UpdatePurchaseOrder(r.Context(), &purchaseOrderModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PurchaseOrderController) SendPurchaseOrder(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	purchaseOrderId := params.ByName("purchaseOrderId")
+	id, _ := strconv.Atoi(purchaseOrderId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.PurchaseOrderService.SendPurchaseOrder(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PurchaseOrderController) ReceiveGoods(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	receiptModel := model.GoodsReceiptModel{}
+	helpers.ReadRequestBody(r, &receiptModel)
+	userId := r.Context().Value("userId").(int)
+	purchaseOrderId := params.ByName("purchaseOrderId")
+	id, _ := strconv.Atoi(purchaseOrderId)
+	receiptModel.PurchaseOrderId = id
+	receiptModel.UserId = userId
+	webResponse := controller.PurchaseOrderService.ReceiveGoods(r.Context(), &receiptModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PurchaseOrderController) ClosePurchaseOrder(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	purchaseOrderId := params.ByName("purchaseOrderId")
+	id, _ := strconv.Atoi(purchaseOrderId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.PurchaseOrderService.ClosePurchaseOrder(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PurchaseOrderController) GetPurchaseOrderById(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	purchaseOrderId := params.ByName("purchaseOrderId")
+	id, _ := strconv.Atoi(purchaseOrderId)
+	webResponse := controller.PurchaseOrderService.GetPurchaseOrderById(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller PurchaseOrderController) GetPurchaseOrders(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	query := r.URL.Query()
+	supplierId, _ := strconv.Atoi(query.Get("supplierId"))
+	warehouseId, _ := strconv.Atoi(query.Get("warehouseId"))
+	webResponse := controller.PurchaseOrderService.GetPurchaseOrders(r.Context(), query.Get("status"), supplierId, warehouseId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type ReorderController struct {
+	ReorderService *service.ReorderService
+}
+
+func NewReorderController(reorderService *service.ReorderService) *ReorderController {
+	return &ReorderController{
+		ReorderService: reorderService,
+	}
+}
+
+func (controller ReorderController) SetReorderPoint(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	reorderModel := model.ReorderPointModel{}
+	helpers.ReadRequestBody(r, &reorderModel)
+	userId := r.Context().Value("userId").(int)
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	reorderModel.ProductId = id
+	reorderModel.UserId = userId
+	webResponse := controller.ReorderService.SetReorderPoint(r.Context(), &reorderModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller ReorderController) GetLowStock(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	warehouseId, _ := strconv.Atoi(r.URL.Query().Get("warehouseId"))
+	webResponse := controller.ReorderService.GetLowStock(r.Context(), warehouseId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller ReorderController) GetPurchaseSuggestions(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	warehouseId, _ := strconv.Atoi(r.URL.Query().Get("warehouseId"))
+	webResponse := controller.ReorderService.GetPurchaseSuggestions(r.Context(), warehouseId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
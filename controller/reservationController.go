@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type ReservationController struct {
+	ReservationService *service.ReservationService
+}
+
+func NewReservationController(reservationService *service.ReservationService) *ReservationController {
+	return &ReservationController{
+		ReservationService: reservationService,
+	}
+}
+
+func (controller ReservationController) CreateReservation(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	reservationModel := model.ReservationModel{}
+	helpers.ReadRequestBody(r, &reservationModel)
+	userId := r.Context().Value("userId").(int)
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	reservationModel.ProductId = id
+	reservationModel.UserId = userId
+	webResponse := controller.ReservationService.CreateReservation(r.Context(), &reservationModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller ReservationController) ReleaseReservation(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userId := r.Context().Value("userId").(int)
+	reservationId := params.ByName("reservationId")
+	id, _ := strconv.Atoi(reservationId)
+	webResponse := controller.ReservationService.ReleaseReservation(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller ReservationController) GetProductReservations(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	activeOnly, _ := strconv.ParseBool(r.URL.Query().Get("active"))
+	webResponse := controller.ReservationService.GetProductReservations(r.Context(), id, activeOnly)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type StockMovementController struct {
+	StockMovementService *service.StockMovementService
+}
+
+func NewStockMovementController(stockMovementService *service.StockMovementService) *StockMovementController {
+	return &StockMovementController{
+		StockMovementService: stockMovementService,
+	}
+}
+
+func (controller StockMovementController) RecordStockMovement(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	movementModel := model.StockMovementModel{}
+	helpers.ReadRequestBody(r, &movementModel)
+	userId := r.Context().Value("userId").(int)
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	movementModel.ProductId = id
+	movementModel.UserId = userId
+	webResponse := controller.StockMovementService.RecordStockMovement(r.Context(), &movementModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller StockMovementController) GetStockMovements(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	warehouseId, _ := strconv.Atoi(r.URL.Query().Get("warehouseId"))
+	webResponse := controller.StockMovementService.GetStockMovements(r.Context(), id, warehouseId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+// ReconcileStock reports discrepancies on GET and corrects them on POST.
+func (controller StockMovementController) ReconcileStock(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userId := r.Context().Value("userId").(int)
+	apply := r.Method == http.MethodPost
+	webResponse := controller.StockMovementService.ReconcileStock(r.Context(), userId, apply)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
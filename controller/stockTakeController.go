@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type StockTakeController struct {
+	StockTakeService *service.StockTakeService
+}
+
+func NewStockTakeController(stockTakeService *service.StockTakeService) *StockTakeController {
+	return &StockTakeController{
+		StockTakeService: stockTakeService,
+	}
+}
+
+func (controller StockTakeController) CreateStockTake(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	stockTakeModel := model.StockTakeModel{}
+	helpers.ReadRequestBody(r, &stockTakeModel)
+	userId := r.Context().Value("userId").(int)
+	stockTakeModel.UserId = userId
+	webResponse := controller.StockTakeService.CreateStockTake(r.Context(), &stockTakeModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller StockTakeController) SubmitCounts(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	countModel := model.StockTakeCountModel{}
+	helpers.ReadRequestBody(r, &countModel)
+	userId := r.Context().Value("userId").(int)
+	stockTakeId := params.ByName("stockTakeId")
+	id, _ := strconv.Atoi(stockTakeId)
+	countModel.StockTakeId = id
+	countModel.UserId = userId
+	webResponse := controller.StockTakeService.SubmitCounts(r.Context(), &countModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller StockTakeController) GetStockTakeSheet(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	stockTakeId := params.ByName("stockTakeId")
+	id, _ := strconv.Atoi(stockTakeId)
+	webResponse := controller.StockTakeService.GetStockTakeSheet(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller StockTakeController) CloseCounting(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	stockTakeId := params.ByName("stockTakeId")
+	id, _ := strconv.Atoi(stockTakeId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.StockTakeService.CloseCounting(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller StockTakeController) ApproveStockTake(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	stockTakeId := params.ByName("stockTakeId")
+	id, _ := strconv.Atoi(stockTakeId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.StockTakeService.ApproveStockTake(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller StockTakeController) CancelStockTake(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	stockTakeId := params.ByName("stockTakeId")
+	id, _ := strconv.Atoi(stockTakeId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.StockTakeService.CancelStockTake(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller StockTakeController) GetStockTakeById(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	stockTakeId := params.ByName("stockTakeId")
+	id, _ := strconv.Atoi(stockTakeId)
+	webResponse := controller.StockTakeService.GetStockTakeById(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller StockTakeController) GetStockTakes(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	query := r.URL.Query()
+	warehouseId, _ := strconv.Atoi(query.Get("warehouseId"))
+	webResponse := controller.StockTakeService.GetStockTakes(r.Context(), query.Get("status"), warehouseId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type SupplierController struct {
+	SupplierService *service.SupplierService
+}
+
+func NewSupplierController(supplierService *service.SupplierService) *SupplierController {
+	return &SupplierController{
+		SupplierService: supplierService,
+	}
+}
+
+func (controller SupplierController) CreateSupplier(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	supplierModel := model.SupplierModel{}
+	helpers.ReadRequestBody(r, &supplierModel)
+	userId := r.Context().Value("userId").(int)
+	supplierModel.UserId = userId
+	webResponse := controller.SupplierService.CreateSupplier(r.Context(), &supplierModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller SupplierController) UpdateSupplier(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	supplierModel := model.SupplierModel{}
+	helpers.ReadRequestBody(r, &supplierModel)
+	userId := r.Context().Value("userId").(int)
+	supplierId := params.ByName("supplierId")
+	id, _ := strconv.Atoi(supplierId)
+	supplierModel.SupplierId = id
+	supplierModel.UserId = userId
+	webResponse := controller.SupplierService.UpdateSupplier(r.Context(), &supplierModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller SupplierController) DeleteSupplier(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	supplierId := params.ByName("supplierId")
+	id, _ := strconv.Atoi(supplierId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.SupplierService.DeleteSupplier(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller SupplierController) GetSupplierById(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	supplierId := params.ByName("supplierId")
+	id, _ := strconv.Atoi(supplierId)
+	webResponse := controller.SupplierService.GetSupplierById(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller SupplierController) GetAllSuppliers(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	activeOnly, _ := strconv.ParseBool(r.URL.Query().Get("active"))
+	webResponse := controller.SupplierService.GetAllSuppliers(r.Context(), activeOnly)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type TransferController struct {
+	TransferService *service.TransferService
+}
+
+func NewTransferController(transferService *service.TransferService) *TransferController {
+	return &TransferController{
+		TransferService: transferService,
+	}
+}
+
+func (controller TransferController) CreateTransfer(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	transferModel := model.TransferModel{}
+	helpers.ReadRequestBody(r, &transferModel)
+	userId := r.Context().Value("userId").(int)
+	transferModel.UserId = userId
+	webResponse := controller.TransferService.CreateTransfer(r.Context(), &transferModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller TransferController) UpdateTransferItems(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	itemsModel := model.TransferItemsModel{}
+	helpers.ReadRequestBody(r, &itemsModel)
+	userId := r.Context().Value("userId").(int)
+	transferId := params.ByName("transferId")
+	id, _ := strconv.Atoi(transferId)
+	itemsModel.TransferId = id
+	itemsModel.UserId = userId
+	webResponse := controller.TransferService.UpdateTransferItems(r.Context(), &itemsModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller TransferController) DispatchTransfer(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	transferId := params.ByName("transferId")
+	id, _ := strconv.Atoi(transferId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.TransferService.DispatchTransfer(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller TransferController) ReceiveTransfer(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	receiptModel := model.TransferReceiptModel{}
+	helpers.ReadRequestBody(r, &receiptModel)
+	userId := r.Context().Value("userId").(int)
+	transferId := params.ByName("transferId")
+	id, _ := strconv.Atoi(transferId)
+	receiptModel.TransferId = id
+	receiptModel.UserId = userId
+	webResponse := controller.TransferService.ReceiveTransfer(r.Context(), &receiptModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller TransferController) CancelTransfer(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	transferId := params.ByName("transferId")
+	id, _ := strconv.Atoi(transferId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.TransferService.CancelTransfer(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller TransferController) GetTransferById(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	transferId := params.ByName("transferId")
+	id, _ := strconv.Atoi(transferId)
+	webResponse := controller.TransferService.GetTransferById(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller TransferController) GetTransfers(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	query := r.URL.Query()
+	warehouseId, _ := strconv.Atoi(query.Get("warehouseId"))
+	webResponse := controller.TransferService.GetTransfers(r.Context(), query.Get("status"), warehouseId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
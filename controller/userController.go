@@ -54,19 +54,20 @@ func (controller *UserController) UpdateUserInfo(w http.ResponseWriter, r *http.
 	userId := params.ByName("userId")
 	id, _ := strconv.Atoi(userId)
 	userDto.UserId = id
+	userDto.IfMatch = r.Header.Get("If-Match")
 	webResponse := controller.UserService.UpdateUserInfo(r.Context(), &userDto)
 	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
 }
 
 func (controller *UserController) DeactivateUser(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	userId, _ := strconv.Atoi(params.ByName("userId"))
-	webResponse := controller.UserService.DeactivateUser(r.Context(), userId)
+	webResponse := controller.UserService.DeactivateUser(r.Context(), userId, r.Header.Get("If-Match"))
 	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
 }
 
 func (controller *UserController) DeleteUser(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	userId, _ := strconv.Atoi(params.ByName("userId"))
-	webResponse := controller.UserService.DeleteUser(r.Context(), userId)
+	webResponse := controller.UserService.DeleteUser(r.Context(), userId, r.Header.Get("If-Match"))
 	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
 }
 
@@ -82,11 +83,24 @@ func (controller *UserController) ChangeUserInfo(w http.ResponseWriter, r *http.
 	helpers.ReadRequestBody(r, &userDto)
 	userId := r.Context().Value("userId").(int)
 	userDto.UserId = userId
+	userDto.IfMatch = r.Header.Get("If-Match")
 	webResponse := controller.UserService.ChangeUserInfo(r.Context(), &userDto)
 	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
 }
 
+func (controller *UserController) GetUserById(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userId, _ := strconv.Atoi(params.ByName("userId"))
+	webResponse := controller.UserService.GetUserById(r.Context(), userId)
+	helpers.WriteConditionalResponse(w, r, webResponse)
+}
+
+func (controller *UserController) GetCurrentUser(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.UserService.GetUserById(r.Context(), userId)
+	helpers.WriteConditionalResponse(w, r, webResponse)
+}
+
 func (controller *UserController) GetAllUsers(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	webResponse := controller.UserService.GetAllUsers(r.Context())
-	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+	helpers.WriteConditionalResponse(w, r, webResponse)
 }
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ValuationController struct {
+	ValuationService *service.ValuationService
+}
+
+func NewValuationController(valuationService *service.ValuationService) *ValuationController {
+	return &ValuationController{
+		ValuationService: valuationService,
+	}
+}
+
+func (controller ValuationController) GetValuationMethod(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	webResponse := controller.ValuationService.GetValuationMethod(r.Context())
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller ValuationController) SetValuationMethod(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	methodModel := model.ValuationMethodModel{}
+	helpers.ReadRequestBody(r, &methodModel)
+	userId := r.Context().Value("userId").(int)
+	methodModel.UserId = userId
+	webResponse := controller.ValuationService.SetValuationMethod(r.Context(), &methodModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller ValuationController) GetInventoryValuation(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	asOf, ok := timeParam(w, r, "asOf", time.Now())
+	if !ok {
+		return
+	}
+	webResponse := controller.ValuationService.GetInventoryValuation(r.Context(), asOf, r.URL.Query().Get("method"), valuationFilter(r))
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller ValuationController) GetCostOfGoodsSold(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	to, ok := timeParam(w, r, "to", time.Now())
+	if !ok {
+		return
+	}
+	from, ok := timeParam(w, r, "from", to.AddDate(0, -1, 0))
+	if !ok {
+		return
+	}
+	webResponse := controller.ValuationService.GetCostOfGoodsSold(r.Context(), from, to, r.URL.Query().Get("method"), valuationFilter(r))
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func valuationFilter(r *http.Request) model.ValuationFilterModel {
+	productId, _ := strconv.Atoi(r.URL.Query().Get("productId"))
+	warehouseId, _ := strconv.Atoi(r.URL.Query().Get("warehouseId"))
+	return model.ValuationFilterModel{ProductId: productId, WarehouseId: warehouseId}
+}
+
+// timeParam reads an RFC3339 timestamp from the query, answering 400 when it does not parse.
+func timeParam(w http.ResponseWriter, r *http.Request, name string, fallback time.Time) (time.Time, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, true
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		helpers.WriteResponseBody(w, &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: name + " must be an RFC3339 timestamp",
+			Data:    nil,
+		}, http.StatusBadRequest)
+		return time.Time{}, false
+	}
+	return parsed, true
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type VariantController struct {
+	VariantService *service.VariantService
+}
+
+func NewVariantController(variantService *service.VariantService) *VariantController {
+	return &VariantController{
+		VariantService: variantService,
+	}
+}
+
+func (controller VariantController) SetProductOptions(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	optionsModel := model.ProductOptionsModel{}
+	helpers.ReadRequestBody(r, &optionsModel)
+	userId := r.Context().Value("userId").(int)
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	optionsModel.ProductId = id
+	optionsModel.UserId = userId
+	webResponse := controller.VariantService.SetProductOptions(r.Context(), &optionsModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller VariantController) CreateVariant(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	variantModel := model.ProductVariantModel{}
+	helpers.ReadRequestBody(r, &variantModel)
+	userId := r.Context().Value("userId").(int)
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	variantModel.ProductId = id
+	variantModel.UserId = userId
+	webResponse := controller.VariantService.CreateVariant(r.Context(), &variantModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller VariantController) UpdateVariant(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	variantModel := model.ProductVariantModel{}
+	helpers.ReadRequestBody(r, &variantModel)
+	userId := r.Context().Value("userId").(int)
+	variantId := params.ByName("variantId")
+	id, _ := strconv.Atoi(variantId)
+	variantModel.VariantId = id
+	variantModel.UserId = userId
+	webResponse := controller.VariantService.UpdateVariant(r.Context(), &variantModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller VariantController) DeleteVariant(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	variantId := params.ByName("variantId")
+	id, _ := strconv.Atoi(variantId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.VariantService.DeleteVariant(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller VariantController) GetVariantMatrix(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	productId := params.ByName("productId")
+	id, _ := strconv.Atoi(productId)
+	webResponse := controller.VariantService.GetVariantMatrix(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/service"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type WarehouseController struct {
+	WarehouseService *service.WarehouseService
+}
+
+func NewWarehouseController(warehouseService *service.WarehouseService) *WarehouseController {
+	return &WarehouseController{
+		WarehouseService: warehouseService,
+	}
+}
+
+func (controller WarehouseController) CreateWarehouse(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	warehouseModel := model.WarehouseModel{}
+	helpers.ReadRequestBody(r, &warehouseModel)
+	userId := r.Context().Value("userId").(int)
+	warehouseModel.UserId = userId
+	webResponse := controller.WarehouseService.CreateWarehouse(r.Context(), &warehouseModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller WarehouseController) UpdateWarehouse(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	warehouseModel := model.WarehouseModel{}
+	helpers.ReadRequestBody(r, &warehouseModel)
+	userId := r.Context().Value("userId").(int)
+	warehouseId := params.ByName("warehouseId")
+	id, _ := strconv.Atoi(warehouseId)
+	warehouseModel.WarehouseId = id
+	warehouseModel.UserId = userId
+	webResponse := controller.WarehouseService.UpdateWarehouse(r.Context(), &warehouseModel)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller WarehouseController) DeleteWarehouse(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	warehouseId := params.ByName("warehouseId")
+	id, _ := strconv.Atoi(warehouseId)
+	userId := r.Context().Value("userId").(int)
+	webResponse := controller.WarehouseService.DeleteWarehouse(r.Context(), id, userId)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller WarehouseController) GetWarehouseById(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	warehouseId := params.ByName("warehouseId")
+	id, _ := strconv.Atoi(warehouseId)
+	webResponse := controller.WarehouseService.GetWarehouseById(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller WarehouseController) GetAllWarehouses(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	activeOnly, _ := strconv.ParseBool(r.URL.Query().Get("active"))
+	webResponse := controller.WarehouseService.GetAllWarehouses(r.Context(), activeOnly)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func (controller WarehouseController) GetWarehouseStock(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	warehouseId := params.ByName("warehouseId")
+	id, _ := strconv.Atoi(warehouseId)
+	webResponse := controller.WarehouseService.GetWarehouseStock(r.Context(), id)
+	helpers.WriteResponseBody(w, webResponse, webResponse.Code)
+}
@@ -4,6 +4,8 @@ type WebResponse struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
+	// ETag is sent as a header, not in the body, by helpers.WriteConditionalResponse.
+	ETag string `json:"-"`
 }
 
 type MailInputs struct {
@@ -0,0 +1,93 @@
+package helpers
+
+import (
+	"Enterprise/data"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ETag derives a strong entity tag from a row's updatedAt column.
+func ETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMilli(), 36) + `"`
+}
+
+// ExpiringETag is the ETag of a response that embeds links valid for validity, like signed download URLs. It
+// also changes every quarter of validity, so a copy revalidated with a 304 still has at least half of the
+// validity left as long as the response is not cached for longer than that quarter.
+func ExpiringETag(updatedAt time.Time, validity time.Duration) string {
+	window := validity / 4
+	if window <= 0 {
+		return ETag(updatedAt)
+	}
+	bucket := time.Now().UnixMilli() / window.Milliseconds()
+	return `"` + strconv.FormatInt(updatedAt.UnixMilli(), 36) + "." + strconv.FormatInt(bucket, 36) + `"`
+}
+
+// CheckETag compares the client's If-Match value with the row's current ETag and returns a 412 response on mismatch.
+// The window part of an ExpiringETag is ignored, only the row version has to match.
+func CheckETag(ifMatch string, updatedAt time.Time, resource string) *data.WebResponse {
+	if matchesETag(withoutWindow(ifMatch), ETag(updatedAt)) {
+		return nil
+	}
+	return PreconditionFailed(resource)
+}
+
+// PreconditionFailed is the 412 response for a write based on an outdated copy of the resource.
+func PreconditionFailed(resource string) *data.WebResponse {
+	return &data.WebResponse{
+		Code:    http.StatusPreconditionFailed,
+		Message: resource + " was changed by someone else, reload it and try again",
+		Data:    nil,
+	}
+}
+
+// WriteConditionalResponse writes a GET response together with its ETag, or 304 when the client's copy is still current.
+// Responses without an ETag of their own get a weak one computed from their data.
+func WriteConditionalResponse(w http.ResponseWriter, r *http.Request, webResponse *data.WebResponse) {
+	if webResponse.Code == http.StatusOK {
+		etag := webResponse.ETag
+		if etag == "" {
+			if body, err := json.Marshal(webResponse.Data); err == nil {
+				sum := sha256.Sum256(body)
+				etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			}
+		}
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+			if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && matchesETag(ifNoneMatch, etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+	}
+	WriteResponseBody(w, webResponse, webResponse.Code)
+}
+
+func matchesETag(header string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutWindow drops the window suffix that ExpiringETag adds from every tag in an If-Match header.
+func withoutWindow(header string) string {
+	candidates := strings.Split(header, ",")
+	for i, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if dot := strings.LastIndex(candidate, "."); dot >= 0 && strings.HasSuffix(candidate, `"`) {
+			candidate = candidate[:dot] + `"`
+		}
+		candidates[i] = candidate
+	}
+	return strings.Join(candidates, ",")
+}
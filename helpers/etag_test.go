@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"Enterprise/data"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchesETag(t *testing.T) {
+	tests := []struct {
+		header string
+		etag   string
+		want   bool
+	}{
+		{`"abc"`, `"abc"`, true},
+		{`"abc"`, `"abd"`, false},
+		{`W/"abc"`, `"abc"`, true},
+		{`"abc"`, `W/"abc"`, true},
+		{`"x", "abc"`, `"abc"`, true},
+		{`"x","y"`, `"abc"`, false},
+		{`*`, `"abc"`, true},
+		{``, `"abc"`, false},
+	}
+	for _, test := range tests {
+		t.Run(test.header, func(t *testing.T) {
+			if got := matchesETag(test.header, test.etag); got != test.want {
+				t.Errorf("matchesETag(%q, %q) = %v, want %v", test.header, test.etag, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckETag(t *testing.T) {
+	updatedAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    bool
+	}{
+		{"current etag", ETag(updatedAt), true},
+		{"current expiring etag", ExpiringETag(updatedAt, time.Hour), true},
+		{"expiring etag of an older window", strings.TrimSuffix(ETag(updatedAt), `"`) + `.1"`, true},
+		{"one of several", `"old", ` + ETag(updatedAt), true},
+		{"wildcard", "*", true},
+		{"outdated etag", ETag(updatedAt.Add(-time.Second)), false},
+		{"outdated expiring etag", ExpiringETag(updatedAt.Add(-time.Second), time.Hour), false},
+		{"empty", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := CheckETag(test.ifMatch, updatedAt, "Product")
+			if (response == nil) != test.want {
+				t.Fatalf("CheckETag(%q) = %v, want match %v", test.ifMatch, response, test.want)
+			}
+			if response != nil && response.Code != http.StatusPreconditionFailed {
+				t.Errorf("got status %d, want %d", response.Code, http.StatusPreconditionFailed)
+			}
+		})
+	}
+}
+
+func TestExpiringETag(t *testing.T) {
+	updatedAt := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	if got := ExpiringETag(updatedAt, 0); got != ETag(updatedAt) {
+		t.Errorf("ExpiringETag without validity = %s, want %s", got, ETag(updatedAt))
+	}
+	if got := withoutWindow(ExpiringETag(updatedAt, time.Hour)); got != ETag(updatedAt) {
+		t.Errorf("ExpiringETag without its window = %s, want %s", got, ETag(updatedAt))
+	}
+}
+
+func TestWriteConditionalResponse(t *testing.T) {
+	response := &data.WebResponse{Code: http.StatusOK, Message: "Product", ETag: `"abc"`}
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"no copy", "", http.StatusOK},
+		{"current copy", `"abc"`, http.StatusNotModified},
+		{"weak current copy", `W/"abc"`, http.StatusNotModified},
+		{"outdated copy", `"old"`, http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+			if test.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", test.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			WriteConditionalResponse(w, r, response)
+			if w.Code != test.want {
+				t.Errorf("got status %d, want %d", w.Code, test.want)
+			}
+			if w.Header().Get("ETag") != `"abc"` {
+				t.Errorf("got ETag %q, want %q", w.Header().Get("ETag"), `"abc"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+// MaxImagePixels bounds the images GenerateThumbnail decodes, a small file can declare a size that takes far
+// more memory to decode than the upload limit suggests.
+const MaxImagePixels = 40_000_000
+
+var ErrImageTooLarge = errors.New("image is too large")
+
+// GenerateThumbnail scales an image down so that its longest side is at most maxSize pixels and encodes it as JPEG.
+// Images of more than MaxImagePixels are refused with ErrImageTooLarge before they are decoded.
+func GenerateThumbnail(content []byte, maxSize int) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels, at most %d are allowed", ErrImageTooLarge, config.Width, config.Height, MaxImagePixels)
+	}
+	source, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			height = height * maxSize / width
+			width = maxSize
+		} else {
+			width = width * maxSize / height
+			height = maxSize
+		}
+	}
+	width, height = max(width, 1), max(height, 1)
+
+	// JPEG has no alpha channel, so transparent areas are flattened onto white.
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(thumbnail, thumbnail.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), source, bounds, draw.Over, nil)
+
+	var body bytes.Buffer
+	err = jpeg.Encode(&body, thumbnail, &jpeg.Options{Quality: 80})
+	if err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
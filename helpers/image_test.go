@@ -0,0 +1,77 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// declaredPNG is a 2x2 PNG whose header claims width x height pixels.
+func declaredPNG(t *testing.T, width uint32, height uint32) []byte {
+	source := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	source.Set(0, 0, color.Black)
+	var body bytes.Buffer
+	if err := png.Encode(&body, source); err != nil {
+		t.Fatal(err)
+	}
+	content := body.Bytes()
+	// The IHDR chunk follows the 8 byte signature: length, type, width, height, ..., CRC over type and data.
+	binary.BigEndian.PutUint32(content[16:20], width)
+	binary.BigEndian.PutUint32(content[20:24], height)
+	binary.BigEndian.PutUint32(content[29:33], crc32.ChecksumIEEE(content[12:29]))
+	return content
+}
+
+func TestGenerateThumbnail(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    []byte
+		wantWidth  int
+		wantHeight int
+		wantErr    error
+	}{
+		{
+			name:       "small image keeps its size",
+			content:    declaredPNG(t, 2, 2),
+			wantWidth:  2,
+			wantHeight: 2,
+		},
+		{
+			name:    "declared size above the pixel limit",
+			content: declaredPNG(t, 50000, 50000),
+			wantErr: ErrImageTooLarge,
+		},
+		{
+			name:    "one side far beyond the limit",
+			content: declaredPNG(t, MaxImagePixels+1, 1),
+			wantErr: ErrImageTooLarge,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			thumbnail, err := GenerateThumbnail(test.content, 320)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("got error %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			config, err := jpeg.DecodeConfig(bytes.NewReader(thumbnail))
+			if err != nil {
+				t.Fatalf("thumbnail is not a JPEG: %v", err)
+			}
+			if config.Width != test.wantWidth || config.Height != test.wantHeight {
+				t.Errorf("got %dx%d, want %dx%d", config.Width, config.Height, test.wantWidth, test.wantHeight)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package helpers
+
+import (
+	"Enterprise/data"
+	"net/url"
+	"strconv"
+)
+
+// PaginationMeta describes one page of a listing of totalCount items.
+func PaginationMeta(page int, perPage int, itemCount int, totalCount int) data.Meta {
+	totalPages := (totalCount + perPage - 1) / perPage
+	return data.Meta{
+		CurrentPage:  page,
+		ItemsPerPage: perPage,
+		ItemCount:    itemCount,
+		TotalCount:   totalCount,
+		TotalPages:   totalPages,
+	}
+}
+
+// PaginationLinks are the links to the first, previous, next and last page of a listing, keeping its other query
+// parameters. Previous and next are empty on the first and last page.
+func PaginationLinks(requestUrl *url.URL, meta data.Meta) data.Links {
+	link := func(page int) string {
+		query := requestUrl.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(meta.ItemsPerPage))
+		return requestUrl.Path + "?" + query.Encode()
+	}
+	lastPage := max(meta.TotalPages, 1)
+	links := data.Links{First: link(1), Last: link(lastPage)}
+	if meta.CurrentPage > 1 {
+		links.Previous = link(min(meta.CurrentPage-1, lastPage))
+	}
+	if meta.CurrentPage < meta.TotalPages {
+		links.Next = link(meta.CurrentPage + 1)
+	}
+	return links
+}
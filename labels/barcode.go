@@ -0,0 +1,128 @@
+package labels
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"image/color"
+	"image/png"
+)
+
+// Symbologies a label can be printed in.
+const (
+	EAN13   = "ean13"
+	Code128 = "code128"
+	QR      = "qr"
+)
+
+// Formats a single barcode can be rendered to.
+const (
+	PNG = "png"
+	SVG = "svg"
+)
+
+// ValidEAN13 reports whether code is thirteen digits ending in the correct check digit.
+func ValidEAN13(code string) bool {
+	if len(code) != 13 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := code[i]
+		if digit < '0' || digit > '9' {
+			return false
+		}
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(digit-'0') * weight
+	}
+	return code[12] == byte('0'+(10-sum%10)%10)
+}
+
+// Encode builds the unscaled barcode for content. EAN-13 content must already carry a valid check digit.
+func Encode(content string, symbology string) (barcode.Barcode, error) {
+	switch symbology {
+	case EAN13:
+		if !ValidEAN13(content) {
+			return nil, fmt.Errorf("%s is not a valid EAN-13 code", content)
+		}
+		return ean.Encode(content)
+	case Code128:
+		return code128.Encode(content)
+	case QR:
+		return qr.Encode(content, qr.M, qr.Auto)
+	default:
+		return nil, fmt.Errorf("unknown barcode type %s, use %s, %s or %s", symbology, EAN13, Code128, QR)
+	}
+}
+
+// Render draws code as a PNG or SVG of roughly width by height pixels and returns the bytes with their content type.
+func Render(code barcode.Barcode, format string, width int, height int) ([]byte, string, error) {
+	if is2D(code) {
+		height = width
+	}
+	switch format {
+	case SVG:
+		return renderSVG(code, width, height), "image/svg+xml", nil
+	case PNG, "":
+		bounds := code.Bounds()
+		width = max(width, bounds.Dx())
+		height = max(height, bounds.Dy())
+		scaled, err := barcode.Scale(code, width, height)
+		if err != nil {
+			return nil, "", err
+		}
+		buffer := bytes.Buffer{}
+		if err = png.Encode(&buffer, scaled); err != nil {
+			return nil, "", err
+		}
+		return buffer.Bytes(), "image/png", nil
+	default:
+		return nil, "", fmt.Errorf("unknown image format %s, use %s or %s", format, PNG, SVG)
+	}
+}
+
+// renderSVG emits one rectangle per run of dark modules. 1D codes are a single row stretched to the full height.
+func renderSVG(code barcode.Barcode, width int, height int) []byte {
+	bounds := code.Bounds()
+	buffer := bytes.Buffer{}
+	fmt.Fprintf(&buffer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" preserveAspectRatio="none" shape-rendering="crispEdges">`,
+		width, height, bounds.Dx(), bounds.Dy())
+	fmt.Fprintf(&buffer, `<rect width="%d" height="%d" fill="#fff"/>`, bounds.Dx(), bounds.Dy())
+	forEachRun(code, func(x, y, length int) {
+		fmt.Fprintf(&buffer, `<rect x="%d" y="%d" width="%d" height="1" fill="#000"/>`, x, y, length)
+	})
+	buffer.WriteString(`</svg>`)
+	return buffer.Bytes()
+}
+
+// forEachRun calls draw for every horizontal run of dark modules in the unscaled code.
+func forEachRun(code barcode.Barcode, draw func(x, y, length int)) {
+	bounds := code.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		start := -1
+		for x := bounds.Min.X; x <= bounds.Max.X; x++ {
+			dark := x < bounds.Max.X && isDark(code.At(x, y))
+			if dark && start < 0 {
+				start = x
+			} else if !dark && start >= 0 {
+				draw(start-bounds.Min.X, y-bounds.Min.Y, x-start)
+				start = -1
+			}
+		}
+	}
+}
+
+func isDark(c color.Color) bool {
+	gray := color.GrayModel.Convert(c).(color.Gray)
+	return gray.Y < 128
+}
+
+func is2D(code barcode.Barcode) bool {
+	return code.Metadata().Dimensions == 2
+}
@@ -0,0 +1,27 @@
+package labels
+
+import "testing"
+
+func TestValidEAN13(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"4006381333931", true},
+		{"5901234123457", true},
+		{"0000000000000", true},
+		{"4006381333932", false},
+		{"400638133393", false},
+		{"40063813339310", false},
+		{"40063813339a1", false},
+		{"400638133393X", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			if got := ValidEAN13(test.code); got != test.want {
+				t.Errorf("ValidEAN13(%q) = %v, want %v", test.code, got, test.want)
+			}
+		})
+	}
+}
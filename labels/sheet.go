@@ -0,0 +1,73 @@
+package labels
+
+import (
+	"bytes"
+	"github.com/boombuler/barcode"
+	"github.com/go-pdf/fpdf"
+)
+
+// Label is one sticker on a label sheet.
+type Label struct {
+	Title   string
+	Caption string
+	Code    barcode.Barcode
+}
+
+// A4 sheet of 3 x 8 labels, 70 x 37 mm each.
+const (
+	sheetColumns = 3
+	sheetRows    = 8
+	labelWidth   = 70.0
+	labelHeight  = 37.0
+	labelPadding = 3.0
+	sheetTop     = 0.5
+)
+
+// Sheet lays the labels out on as many A4 pages as needed and returns the PDF.
+func Sheet(labels []Label) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetFont("Helvetica", "", 9)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	perPage := sheetColumns * sheetRows
+	for i, label := range labels {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+		slot := i % perPage
+		x := float64(slot%sheetColumns) * labelWidth
+		y := sheetTop + float64(slot/sheetColumns)*labelHeight
+
+		pdf.SetXY(x+labelPadding, y+labelPadding)
+		pdf.CellFormat(labelWidth-2*labelPadding, 4, translate(label.Title), "", 0, "L", false, 0, "")
+		pdf.SetXY(x+labelPadding, y+labelHeight-labelPadding-4)
+		pdf.CellFormat(labelWidth-2*labelPadding, 4, translate(label.Caption), "", 0, "C", false, 0, "")
+
+		codeTop := y + labelPadding + 5
+		codeHeight := labelHeight - 2*labelPadding - 10
+		codeWidth := labelWidth - 2*labelPadding
+		if is2D(label.Code) {
+			codeWidth = codeHeight
+		}
+		drawCode(pdf, label.Code, x+(labelWidth-codeWidth)/2, codeTop, codeWidth, codeHeight)
+	}
+
+	buffer := bytes.Buffer{}
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// drawCode paints the barcode as vector rectangles so it stays sharp at any print resolution.
+func drawCode(pdf *fpdf.Fpdf, code barcode.Barcode, x, y, width, height float64) {
+	bounds := code.Bounds()
+	moduleWidth := width / float64(bounds.Dx())
+	moduleHeight := height / float64(bounds.Dy())
+	pdf.SetFillColor(0, 0, 0)
+	forEachRun(code, func(runX, runY, length int) {
+		pdf.Rect(x+float64(runX)*moduleWidth, y+float64(runY)*moduleHeight, float64(length)*moduleWidth, moduleHeight, "F")
+	})
+}
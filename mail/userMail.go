@@ -117,3 +117,17 @@ func ResetPassword(emailDto *data.MailInputs) error {
 	}
 	return EmailLogics("Reset Password", "mail/templates/reset.html", emailDto, templateData)
 }
+
+// LowStockAlert sends a manager the digest of items that fell to their reorder point.
+func LowStockAlert(emailDto *data.MailInputs, items interface{}) error {
+	templateData := struct {
+		Username string
+		Items    interface{}
+		Link     string
+	}{
+		Username: emailDto.Username,
+		Items:    items,
+		Link:     os.Getenv("FRONTEND_URL") + "/inventory/low-stock",
+	}
+	return EmailLogics("Low Stock Alert", "mail/templates/lowStock.html", emailDto, templateData)
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"Enterprise/cache"
 	"Enterprise/config"
 	"Enterprise/controller"
 	"Enterprise/helpers"
+	"Enterprise/middleware"
 	"Enterprise/router"
 	"Enterprise/service"
+	"context"
 	"fmt"
 	"github.com/joho/godotenv"
 	"log"
@@ -28,17 +31,70 @@ func main() {
 		helpers.PanicAllErrors(err)
 	}
 	redisClient, err := config.ConnectRedis()
+	if err != nil {
+		helpers.PanicAllErrors(err)
+	}
+	fileStorage, err := config.ConnectStorage()
+	if err != nil {
+		helpers.PanicAllErrors(err)
+	}
 
 	defer db.Prisma.Disconnect()
 
+	catalogCache := cache.NewCache(redisClient, config.CatalogCacheTTL())
+
 	userService := service.NewUserService(db, redisClient)
 	userController := controller.NewUserController(userService)
-	categoryService := service.NewCategoryService(db)
+	categoryService := service.NewCategoryService(db, catalogCache)
 	categoryController := controller.NewCategoryController(categoryService)
-	productService := service.NewProductService(db)
+	productService := service.NewProductService(db, fileStorage, catalogCache)
 	productController := controller.NewProductController(productService)
+	variantService := service.NewVariantService(db, catalogCache)
+	variantController := controller.NewVariantController(variantService)
+	attachmentService := service.NewAttachmentService(db, fileStorage, catalogCache)
+	attachmentController := controller.NewAttachmentController(attachmentService)
+	priceService := service.NewPriceService(db, catalogCache)
+	priceController := controller.NewPriceController(priceService)
+	attributeService := service.NewAttributeService(db, catalogCache)
+	attributeController := controller.NewAttributeController(attributeService)
+	bundleService := service.NewBundleService(db, catalogCache)
+	bundleController := controller.NewBundleController(bundleService)
+	labelService := service.NewLabelService(db)
+	labelController := controller.NewLabelController(labelService)
+	priceListService := service.NewPriceListService(db)
+	priceListController := controller.NewPriceListController(priceListService)
+	warehouseService := service.NewWarehouseService(db)
+	warehouseController := controller.NewWarehouseController(warehouseService)
+	stockMovementService := service.NewStockMovementService(db, catalogCache)
+	stockMovementController := controller.NewStockMovementController(stockMovementService)
+	transferService := service.NewTransferService(db, catalogCache)
+	transferController := controller.NewTransferController(transferService)
+	reservationService := service.NewReservationService(db, catalogCache)
+	reservationController := controller.NewReservationController(reservationService)
+	reorderService := service.NewReorderService(db)
+	reorderController := controller.NewReorderController(reorderService)
+	notificationService := service.NewNotificationService(db)
+	notificationController := controller.NewNotificationController(notificationService)
+	supplierService := service.NewSupplierService(db)
+	supplierController := controller.NewSupplierController(supplierService)
+	purchaseOrderService := service.NewPurchaseOrderService(db, catalogCache)
+	purchaseOrderController := controller.NewPurchaseOrderController(purchaseOrderService)
+	stockTakeService := service.NewStockTakeService(db, catalogCache)
+	stockTakeController := controller.NewStockTakeController(stockTakeService)
+	lotService := service.NewLotService(db)
+	lotController := controller.NewLotController(lotService)
+	valuationService := service.NewValuationService(db)
+	valuationController := controller.NewValuationController(valuationService)
+	orderService := service.NewOrderService(db, catalogCache)
+	orderController := controller.NewOrderController(orderService)
+	cacheController := controller.NewCacheController(catalogCache)
+
+	go priceService.RunPriceScheduler(context.Background(), time.Minute)
+	go reservationService.RunReservationExpiry(context.Background(), time.Minute)
+	go reorderService.RunLowStockMonitor(context.Background(), 15*time.Minute)
 
-	routes := router.NewRouter(userController, categoryController, productController)
+	middleware.EnableIdempotency(redisClient, config.IdempotencyTTL())
+	routes := router.NewRouter(userController, categoryController, productController, variantController, attachmentController, priceController, attributeController, bundleController, labelController, priceListController, warehouseController, stockMovementController, transferController, reservationController, reorderController, notificationController, supplierController, purchaseOrderController, stockTakeController, lotController, valuationController, orderController, cacheController)
 
 	server := http.Server{
 		Addr:           os.Getenv("PORT"),
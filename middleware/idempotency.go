@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// pendingTTL bounds how long a request in progress holds its key, so a crashed request does not block
+// retries for the whole idempotency window.
+const pendingTTL = time.Minute
+
+// replayedHeaders are the response headers stored with an idempotent response and sent again on replay.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// idempotencyStore is the part of the Redis client the middleware keeps its keys in.
+type idempotencyStore interface {
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+type idempotentResponse struct {
+	Fingerprint string            `json:"fingerprint"`
+	Code        int               `json:"code"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+func (recorder *responseRecorder) WriteHeader(code int) {
+	recorder.code = code
+	recorder.ResponseWriter.WriteHeader(code)
+}
+
+func (recorder *responseRecorder) Write(body []byte) (int, error) {
+	recorder.body.Write(body)
+	return recorder.ResponseWriter.Write(body)
+}
+
+// maxIdempotentBody bounds the request bodies read and kept for an Idempotency-Key.
+const maxIdempotentBody = 1 << 20
+
+// idempotencyKeys and idempotencyTTL are where and for how long RoleBasedAuthMiddleware keeps idempotent
+// responses, set by EnableIdempotency.
+var (
+	idempotencyKeys idempotencyStore
+	idempotencyTTL  time.Duration
+)
+
+// EnableIdempotency makes POST and PUT requests on authenticated routes that carry an Idempotency-Key header
+// safe to retry, see idempotency. Without a Redis client the header is ignored.
+func EnableIdempotency(client *redis.Client, ttl time.Duration) {
+	if client == nil {
+		return
+	}
+	idempotencyKeys = client
+	idempotencyTTL = ttl
+}
+
+// idempotency runs the first request with a key and keeps its response for ttl, later requests with the same
+// key and the same method, path and body get that response replayed without running again. Reusing a key for a
+// different request is refused, as is a repeat that arrives while the first is still running. Server errors are
+// not kept so they can be retried. Keys are scoped to the signed in user, so it only runs after authentication.
+func idempotency(client idempotencyStore, ttl time.Duration, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPut) {
+			next(w, r, params)
+			return
+		}
+		if len(key) > 255 {
+			helpers.WriteResponseBody(w, &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Idempotency-Key must be at most 255 characters",
+				Data:    nil,
+			}, http.StatusBadRequest)
+			return
+		}
+		userId, ok := r.Context().Value("userId").(int)
+		if !ok {
+			helpers.WriteResponseBody(w, &data.WebResponse{
+				Code:    http.StatusUnauthorized,
+				Message: "Idempotency-Key is only accepted from signed in users",
+				Data:    nil,
+			}, http.StatusUnauthorized)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			helpers.WriteResponseBody(w, &data.WebResponse{
+				Code:    http.StatusRequestEntityTooLarge,
+				Message: fmt.Sprintf("Requests with an Idempotency-Key can be at most %d bytes", maxIdempotentBody),
+				Data:    nil,
+			}, http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			helpers.WriteResponseBody(w, &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Request body could not be read",
+				Data:    nil,
+			}, http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(append([]byte(r.Method+" "+r.URL.RequestURI()+"\n"), body...))
+		fingerprint := hex.EncodeToString(sum[:])
+		redisKey := fmt.Sprintf("idempotency:user:%d:%s", userId, key)
+		ctx := r.Context()
+
+		pending, _ := json.Marshal(idempotentResponse{Fingerprint: fingerprint})
+		claimed, err := client.SetNX(ctx, redisKey, pending, pendingTTL).Result()
+		if err != nil {
+			// Without Redis the request is served unprotected rather than refused.
+			log.Error().Err(err).Msg("Claiming idempotency key failed")
+			next(w, r, params)
+			return
+		}
+		if !claimed {
+			replayIdempotentResponse(ctx, w, client, redisKey, fingerprint)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, code: http.StatusOK}
+		stored := false
+		defer func() {
+			if !stored {
+				client.Del(context.Background(), redisKey)
+			}
+		}()
+		next(recorder, r, params)
+		if recorder.code >= http.StatusInternalServerError {
+			return
+		}
+
+		response := idempotentResponse{
+			Fingerprint: fingerprint,
+			Code:        recorder.code,
+			Headers:     map[string]string{},
+			Body:        recorder.body.Bytes(),
+		}
+		for _, header := range replayedHeaders {
+			if value := w.Header().Get(header); value != "" {
+				response.Headers[header] = value
+			}
+		}
+		raw, err := json.Marshal(response)
+		if err == nil {
+			err = client.Set(context.Background(), redisKey, raw, ttl).Err()
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Storing idempotent response failed")
+			return
+		}
+		stored = true
+	}
+}
+
+func replayIdempotentResponse(ctx context.Context, w http.ResponseWriter, client idempotencyStore, redisKey string, fingerprint string) {
+	raw, err := client.Get(ctx, redisKey).Bytes()
+	response := idempotentResponse{}
+	if err == nil {
+		err = json.Unmarshal(raw, &response)
+	}
+	switch {
+	case err != nil:
+		// The first request failed and released the key, or its response expired, in the meantime.
+		helpers.WriteResponseBody(w, &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "The request with this Idempotency-Key did not complete, retry it",
+			Data:    nil,
+		}, http.StatusConflict)
+	case response.Fingerprint != fingerprint:
+		helpers.WriteResponseBody(w, &data.WebResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "Idempotency-Key was already used for a different request",
+			Data:    nil,
+		}, http.StatusUnprocessableEntity)
+	case response.Code == 0:
+		helpers.WriteResponseBody(w, &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "A request with this Idempotency-Key is still in progress",
+			Data:    nil,
+		}, http.StatusConflict)
+	default:
+		for header, value := range response.Headers {
+			w.Header().Set(header, value)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(response.Code)
+		_, _ = w.Write(response.Body)
+	}
+}
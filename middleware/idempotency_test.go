@@ -0,0 +1,228 @@
+package middleware
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/net/context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeStore is an in-memory idempotencyStore.
+type fakeStore struct {
+	values map[string]string
+}
+
+func (store *fakeStore) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	if _, ok := store.values[key]; ok {
+		return redis.NewBoolResult(false, nil)
+	}
+	store.values[key] = string(value.([]byte))
+	return redis.NewBoolResult(true, nil)
+}
+
+func (store *fakeStore) Get(ctx context.Context, key string) *redis.StringCmd {
+	value, ok := store.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (store *fakeStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	store.values[key] = string(value.([]byte))
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (store *fakeStore) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	deleted := 0
+	for _, key := range keys {
+		if _, ok := store.values[key]; ok {
+			delete(store.values, key)
+			deleted++
+		}
+	}
+	return redis.NewIntResult(int64(deleted), nil)
+}
+
+type idempotentRequest struct {
+	method string
+	key    string
+	body   string
+	userId int // 0 for a request without a signed in user
+}
+
+func TestIdempotency(t *testing.T) {
+	created := httprouter.Handle(func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		w.Header().Set("Location", "/orders/1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	})
+	failing := httprouter.Handle(func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	tests := []struct {
+		name         string
+		handler      httprouter.Handle
+		requests     []idempotentRequest
+		wantCodes    []int
+		wantCalls    int
+		wantReplayed bool // Whether the last response was replayed
+	}{
+		{
+			name:    "a repeat is replayed",
+			handler: created,
+			requests: []idempotentRequest{
+				{http.MethodPost, "order-1", `{"total":10}`, 1},
+				{http.MethodPost, "order-1", `{"total":10}`, 1},
+			},
+			wantCodes:    []int{http.StatusCreated, http.StatusCreated},
+			wantCalls:    1,
+			wantReplayed: true,
+		},
+		{
+			name:    "a key reused for a different body is refused",
+			handler: created,
+			requests: []idempotentRequest{
+				{http.MethodPost, "order-1", `{"total":10}`, 1},
+				{http.MethodPost, "order-1", `{"total":20}`, 1},
+			},
+			wantCodes: []int{http.StatusCreated, http.StatusUnprocessableEntity},
+			wantCalls: 1,
+		},
+		{
+			name:    "different keys run separately",
+			handler: created,
+			requests: []idempotentRequest{
+				{http.MethodPost, "order-1", `{"total":10}`, 1},
+				{http.MethodPost, "order-2", `{"total":10}`, 1},
+			},
+			wantCodes: []int{http.StatusCreated, http.StatusCreated},
+			wantCalls: 2,
+		},
+		{
+			name:    "server errors are not kept",
+			handler: failing,
+			requests: []idempotentRequest{
+				{http.MethodPost, "order-1", `{"total":10}`, 1},
+				{http.MethodPost, "order-1", `{"total":10}`, 1},
+			},
+			wantCodes: []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			wantCalls: 2,
+		},
+		{
+			name:    "requests without a key are not kept",
+			handler: created,
+			requests: []idempotentRequest{
+				{http.MethodPost, "", `{"total":10}`, 1},
+				{http.MethodPost, "", `{"total":10}`, 1},
+			},
+			wantCodes: []int{http.StatusCreated, http.StatusCreated},
+			wantCalls: 2,
+		},
+		{
+			name:    "reads are not kept",
+			handler: created,
+			requests: []idempotentRequest{
+				{http.MethodGet, "order-1", "", 1},
+				{http.MethodGet, "order-1", "", 1},
+			},
+			wantCodes: []int{http.StatusCreated, http.StatusCreated},
+			wantCalls: 2,
+		},
+		{
+			name:    "users do not share keys",
+			handler: created,
+			requests: []idempotentRequest{
+				{http.MethodPost, "order-1", `{"total":10}`, 1},
+				{http.MethodPost, "order-1", `{"total":20}`, 2},
+			},
+			wantCodes: []int{http.StatusCreated, http.StatusCreated},
+			wantCalls: 2,
+		},
+		{
+			name:    "a key without a signed in user is refused",
+			handler: created,
+			requests: []idempotentRequest{
+				{http.MethodPost, "order-1", `{"total":10}`, 0},
+			},
+			wantCodes: []int{http.StatusUnauthorized},
+		},
+		{
+			name:    "an oversized body is refused",
+			handler: created,
+			requests: []idempotentRequest{
+				{http.MethodPost, "order-1", strings.Repeat("x", maxIdempotentBody+1), 1},
+			},
+			wantCodes: []int{http.StatusRequestEntityTooLarge},
+		},
+		{
+			name:    "an overlong key is refused",
+			handler: created,
+			requests: []idempotentRequest{
+				{http.MethodPost, strings.Repeat("k", 256), `{"total":10}`, 1},
+			},
+			wantCodes: []int{http.StatusBadRequest},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			handler := idempotency(&fakeStore{values: map[string]string{}}, time.Hour, func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+				calls++
+				test.handler(w, r, params)
+			})
+			var w *httptest.ResponseRecorder
+			for i, request := range test.requests {
+				w = serveIdempotent(handler, request)
+				if w.Code != test.wantCodes[i] {
+					t.Errorf("request %d got status %d, want %d", i+1, w.Code, test.wantCodes[i])
+				}
+			}
+			if calls != test.wantCalls {
+				t.Errorf("handler ran %d times, want %d", calls, test.wantCalls)
+			}
+			if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != test.wantReplayed {
+				t.Errorf("last response replayed %v, want %v", replayed, test.wantReplayed)
+			}
+			if test.wantReplayed && (w.Header().Get("Location") != "/orders/1" || w.Body.String() != `{"id":1}`) {
+				t.Errorf("replayed Location %q and body %q, want the first response", w.Header().Get("Location"), w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	request := idempotentRequest{http.MethodPost, "order-1", `{"total":10}`, 1}
+	var repeat *httptest.ResponseRecorder
+	var handler httprouter.Handle
+	handler = idempotency(&fakeStore{values: map[string]string{}}, time.Hour, func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		// The repeat arrives while the first request is still running.
+		repeat = serveIdempotent(handler, request)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	first := serveIdempotent(handler, request)
+	if first.Code != http.StatusCreated {
+		t.Errorf("first request got status %d, want %d", first.Code, http.StatusCreated)
+	}
+	if repeat == nil || repeat.Code != http.StatusConflict {
+		t.Fatalf("repeat in progress got %v, want status %d", repeat, http.StatusConflict)
+	}
+}
+
+func serveIdempotent(handler httprouter.Handle, request idempotentRequest) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(request.method, "/orders", strings.NewReader(request.body))
+	if request.key != "" {
+		r.Header.Set("Idempotency-Key", request.key)
+	}
+	if request.userId != 0 {
+		r = r.WithContext(context.WithValue(r.Context(), "userId", request.userId))
+	}
+	w := httptest.NewRecorder()
+	handler(w, r, nil)
+	return w
+}
@@ -42,6 +42,10 @@ func RoleBasedAuthMiddleware(allowedRoles []string, next httprouter.Handle) http
 		}
 
 		ctx := context.WithValue(r.Context(), "userId", claims.Id)
+		if idempotencyKeys != nil {
+			idempotency(idempotencyKeys, idempotencyTTL, next)(w, r.WithContext(ctx), params)
+			return
+		}
 		next(w, r.WithContext(ctx), params)
 	}
 }
@@ -54,3 +58,18 @@ func isAllowedRole(userRole string, allowedRoles []string) bool {
 	}
 	return false
 }
+
+// IfMatchMiddleware rejects writes that do not say which version of the resource they were based on.
+func IfMatchMiddleware(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		if r.Header.Get("If-Match") == "" {
+			helpers.WriteResponseBody(w, &data.WebResponse{
+				Code:    http.StatusPreconditionRequired,
+				Message: "If-Match header is required, send the ETag of the resource you are changing",
+				Data:    nil,
+			}, http.StatusPreconditionRequired)
+			return
+		}
+		next(w, r, params)
+	}
+}
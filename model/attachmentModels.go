@@ -0,0 +1,31 @@
+package model
+
+import (
+	"io"
+	"time"
+)
+
+type AttachmentUploadModel struct {
+	FileName  string `json:"fileName" validate:"required,max=255"`
+	Content   []byte `json:"-" validate:"required"`
+	ProductId int    `json:"productId"`
+	UserId    int    `json:"userId"`
+}
+
+type AttachmentResponse struct {
+	Id           int       `json:"id"`
+	Kind         string    `json:"kind"`
+	FileName     string    `json:"fileName"`
+	ContentType  string    `json:"contentType"`
+	Size         int       `json:"size"`
+	Url          string    `json:"url"`
+	ThumbnailUrl string    `json:"thumbnailUrl,omitempty"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type FileDownload struct {
+	Body        io.ReadCloser
+	ContentType string
+	FileName    string
+}
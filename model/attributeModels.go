@@ -0,0 +1,21 @@
+package model
+
+type CategoryAttributeModel struct {
+	Name          string   `json:"name" validate:"required,min=1,max=50"`
+	Type          string   `json:"type" validate:"required,oneof=STRING NUMBER BOOLEAN"`
+	Required      bool     `json:"required"`
+	AllowedValues []string `json:"allowedValues" validate:"omitempty,dive,required"`
+}
+
+type CategoryAttributesModel struct {
+	Attributes []CategoryAttributeModel `json:"attributes" validate:"dive"`
+	CategoryId int                      `json:"categoryId"`
+	UserId     int                      `json:"userId"`
+}
+
+type CategoryAttributeResponse struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Required      bool     `json:"required"`
+	AllowedValues []string `json:"allowedValues"`
+}
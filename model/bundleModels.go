@@ -0,0 +1,32 @@
+package model
+
+type BundleComponentModel struct {
+	ProductId int `json:"productId" validate:"required"`
+	Quantity  int `json:"quantity" validate:"required,gte=1"`
+}
+
+type BundleModel struct {
+	Components []BundleComponentModel `json:"components" validate:"required,min=1,dive"`
+	// Discount is a percentage taken off the sum of the component prices. Without it the bundle keeps its own price.
+	Discount  *float64 `json:"discount" validate:"omitempty,gte=0,lt=100"`
+	ProductId int      `json:"productId"`
+	UserId    int      `json:"userId"`
+}
+
+type BundleComponentResponse struct {
+	ProductId int     `json:"productId"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	Price     float32 `json:"price"`
+	Stock     int     `json:"stock"`
+}
+
+type BundleResponse struct {
+	ProductId      int                       `json:"productId"`
+	Name           string                    `json:"name"`
+	Price          float32                   `json:"price"`
+	ComponentsCost float32                   `json:"componentsCost"`
+	Discount       *float64                  `json:"discount"`
+	AvailableStock int                       `json:"availableStock"`
+	Components     []BundleComponentResponse `json:"components"`
+}
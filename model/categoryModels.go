@@ -4,12 +4,37 @@ import "time"
 
 type CategoryModel struct {
 	Name       string `json:"name" validate:"required,min=3,max=50"`
+	ParentId   *int   `json:"parentId"`
 	UserId     int    `json:"userId"`
 	CategoryId int    `json:"categoryId"`
+	IfMatch    string `json:"-"`
+}
+
+type CategoryMoveModel struct {
+	ParentId   *int   `json:"parentId"`
+	UserId     int    `json:"userId"`
+	CategoryId int    `json:"categoryId"`
+	IfMatch    string `json:"-"`
 }
 
 type CategoryResponse struct {
-	Id        int       `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"createdAt"`
+	Id        int        `json:"id"`
+	Name      string     `json:"name"`
+	ParentId  *int       `json:"parentId"`
+	CreatedAt time.Time  `json:"createdAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+type CategoryTreeResponse struct {
+	Id                int                    `json:"id"`
+	Name              string                 `json:"name"`
+	ParentId          *int                   `json:"parentId"`
+	ProductCount      int                    `json:"productCount"`
+	TotalProductCount int                    `json:"totalProductCount"`
+	Children          []CategoryTreeResponse `json:"children"`
+}
+
+type BreadcrumbResponse struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
 }
@@ -0,0 +1,11 @@
+package model
+
+type LabelItemModel struct {
+	ProductId int `json:"productId" validate:"required"`
+	Quantity  int `json:"quantity" validate:"required,gte=1,lte=500"`
+}
+
+type LabelSheetModel struct {
+	Items []LabelItemModel `json:"items" validate:"required,min=1,dive"`
+	Type  string           `json:"type" validate:"omitempty,oneof=ean13 code128 qr"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+type LotResponse struct {
+	Id             int        `json:"id"`
+	ProductId      int        `json:"productId"`
+	ProductName    string     `json:"productName"`
+	WarehouseId    int        `json:"warehouseId"`
+	WarehouseName  string     `json:"warehouseName"`
+	LotNumber      string     `json:"lotNumber"`
+	ManufacturedAt *time.Time `json:"manufacturedAt"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+	Quantity       int        `json:"quantity"`
+	Expired        bool       `json:"expired"`
+}
+
+type LotRecallOrderResponse struct {
+	OrderId     int       `json:"orderId"`
+	UserId      int       `json:"userId"`
+	Status      string    `json:"status"`
+	WarehouseId int       `json:"warehouseId"`
+	Quantity    int       `json:"quantity"`
+	OrderedAt   time.Time `json:"orderedAt"`
+}
+
+type LotRecallResponse struct {
+	ProductId int                      `json:"productId"`
+	LotNumber string                   `json:"lotNumber"`
+	Lots      []LotResponse            `json:"lots"`
+	Orders    []LotRecallOrderResponse `json:"orders"`
+}
@@ -0,0 +1,87 @@
+package model
+
+import "time"
+
+type OrderItemModel struct {
+	ProductId int  `json:"productId" validate:"required"`
+	VariantId *int `json:"variantId"`
+	Quantity  int  `json:"quantity" validate:"required,gt=0"`
+}
+
+type OrderModel struct {
+	Items          []OrderItemModel `json:"items" validate:"required,min=1,dive"`
+	ReservationIds []int            `json:"reservationIds" validate:"dive,gt=0"`
+	UserId         int              `json:"userId"`
+}
+
+type OrderStatusModel struct {
+	Status  string `json:"status" validate:"required,oneof=SHIPPED DELIVERED CANCELLED"`
+	Note    string `json:"note" validate:"max=255"`
+	OrderId int    `json:"orderId"`
+	UserId  int    `json:"userId"`
+}
+
+type OrderStatusHistoryResponse struct {
+	Id         int       `json:"id"`
+	FromStatus *string   `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+	UserId     int       `json:"userId"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type OrderFilterModel struct {
+	Status    string     `json:"status" validate:"omitempty,oneof=PENDING SHIPPED DELIVERED CANCELLED"`
+	From      *time.Time `json:"from"`
+	To        *time.Time `json:"to"`
+	UserId    int        `json:"userId" validate:"gte=0"`
+	MinAmount *float64   `json:"minAmount" validate:"omitempty,gte=0"`
+	MaxAmount *float64   `json:"maxAmount" validate:"omitempty,gte=0"`
+	Search    string     `json:"search" validate:"max=100"`
+	Page      int        `json:"page" validate:"gte=0"`
+	PerPage   int        `json:"perPage" validate:"gte=0,lte=100"`
+}
+
+type OrderSummaryResponse struct {
+	Id          int       `json:"id"`
+	UserId      int       `json:"userId"`
+	Email       string    `json:"email"`
+	FirstName   string    `json:"firstName"`
+	LastName    string    `json:"lastName"`
+	Status      string    `json:"status"`
+	TotalAmount float64   `json:"totalAmount"`
+	ItemCount   int       `json:"itemCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type OrderItemResponse struct {
+	ProductId   int     `json:"productId"`
+	ProductName string  `json:"productName"`
+	VariantId   *int    `json:"variantId"`
+	Sku         string  `json:"sku,omitempty"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"`
+	Total       float64 `json:"total"`
+}
+
+type OrderTransactionResponse struct {
+	Id          int       `json:"id"`
+	Amount      float64   `json:"amount"`
+	Method      string    `json:"method"`
+	Status      string    `json:"status"`
+	ProcessedAt time.Time `json:"processedAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type OrderResponse struct {
+	Id           int                          `json:"id"`
+	UserId       int                          `json:"userId"`
+	Status       string                       `json:"status"`
+	TotalAmount  float64                      `json:"totalAmount"`
+	Items        []OrderItemResponse          `json:"items"`
+	Transactions []OrderTransactionResponse   `json:"transactions"`
+	History      []OrderStatusHistoryResponse `json:"history"`
+	CreatedAt    time.Time                    `json:"createdAt"`
+	UpdatedAt    time.Time                    `json:"updatedAt"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+type PriceListModel struct {
+	Name          string     `json:"name" validate:"required,min=3,max=100"`
+	Currency      string     `json:"currency" validate:"required,len=3,alpha,uppercase"`
+	CustomerGroup string     `json:"customerGroup" validate:"omitempty,oneof=RETAIL WHOLESALE"`
+	Priority      int        `json:"priority"`
+	ValidFrom     *time.Time `json:"validFrom"`
+	ValidTo       *time.Time `json:"validTo"`
+	Active        *bool      `json:"active"`
+	PriceListId   int        `json:"priceListId"`
+	UserId        int        `json:"userId"`
+}
+
+type PriceListItemModel struct {
+	ProductId   int     `json:"productId" validate:"required"`
+	VariantId   *int    `json:"variantId"`
+	MinQuantity int     `json:"minQuantity" validate:"gte=0"`
+	Price       float64 `json:"price" validate:"required,gt=0"`
+}
+
+type PriceListItemsModel struct {
+	Items       []PriceListItemModel `json:"items" validate:"dive"`
+	PriceListId int                  `json:"priceListId"`
+	UserId      int                  `json:"userId"`
+}
+
+type ExchangeRateModel struct {
+	BaseCurrency  string     `json:"baseCurrency" validate:"required,len=3,alpha,uppercase"`
+	QuoteCurrency string     `json:"quoteCurrency" validate:"required,len=3,alpha,uppercase,nefield=BaseCurrency"`
+	Rate          float64    `json:"rate" validate:"required,gt=0"`
+	EffectiveAt   *time.Time `json:"effectiveAt"`
+	UserId        int        `json:"userId"`
+}
+
+type PriceListItemResponse struct {
+	ProductId   int     `json:"productId"`
+	VariantId   *int    `json:"variantId,omitempty"`
+	MinQuantity int     `json:"minQuantity"`
+	Price       float64 `json:"price"`
+}
+
+type PriceListResponse struct {
+	Id            int                     `json:"id"`
+	Name          string                  `json:"name"`
+	Currency      string                  `json:"currency"`
+	CustomerGroup *string                 `json:"customerGroup"`
+	Priority      int                     `json:"priority"`
+	ValidFrom     *time.Time              `json:"validFrom"`
+	ValidTo       *time.Time              `json:"validTo"`
+	Active        bool                    `json:"active"`
+	CreatedAt     time.Time               `json:"createdAt"`
+	Items         []PriceListItemResponse `json:"items,omitempty"`
+}
+
+type ExchangeRateResponse struct {
+	Id            int       `json:"id"`
+	BaseCurrency  string    `json:"baseCurrency"`
+	QuoteCurrency string    `json:"quoteCurrency"`
+	Rate          float64   `json:"rate"`
+	EffectiveAt   time.Time `json:"effectiveAt"`
+}
+
+type ResolvedPriceResponse struct {
+	ProductId     int     `json:"productId"`
+	VariantId     *int    `json:"variantId,omitempty"`
+	Quantity      int     `json:"quantity"`
+	CustomerGroup string  `json:"customerGroup"`
+	Currency      string  `json:"currency"`
+	UnitPrice     float64 `json:"unitPrice"`
+	Total         float64 `json:"total"`
+	PriceListId   *int    `json:"priceListId"`
+	PriceListName string  `json:"priceListName,omitempty"`
+	// SourceCurrency is the currency the price was defined in before conversion.
+	SourceCurrency string  `json:"sourceCurrency"`
+	ExchangeRate   float64 `json:"exchangeRate"`
+}
@@ -3,26 +3,92 @@ package model
 import "time"
 
 type ProductModel struct {
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	Price       float32 `json:"price" validate:"required"`
-	Stock       int     `json:"stock" validate:"required"`
-	CategoryId  []int   `json:"categoryId"`
-	UserId      int     `json:"userId"`
-	ProductId   int     `json:"productId"`
+	Name         string                 `json:"name" validate:"required"`
+	Description  string                 `json:"description" validate:"required"`
+	Price        float32                `json:"price" validate:"required"`
+	Stock        int                    `json:"stock" validate:"gte=0"`
+	WarehouseId  *int                   `json:"warehouseId"`
+	CategoryId   []int                  `json:"categoryId"`
+	Barcode      string                 `json:"barcode" validate:"omitempty,len=13,numeric"`
+	LotTracked   *bool                  `json:"lotTracked"`
+	StandardCost *float64               `json:"standardCost" validate:"omitempty,gte=0"`
+	Attributes   map[string]interface{} `json:"attributes"`
+	PriceReason  string                 `json:"priceReason"`
+	UserId       int                    `json:"userId"`
+	ProductId    int                    `json:"productId"`
+	IfMatch      string                 `json:"-"`
 }
 
 type ProductResponse struct {
-	Id          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float32   `json:"price"`
-	Stock       int       `json:"stock"`
-	CreatedAt   time.Time `json:"createdAt"`
+	Id           int                      `json:"id"`
+	Name         string                   `json:"name"`
+	Description  string                   `json:"description"`
+	Type         string                   `json:"type"`
+	Barcode      string                   `json:"barcode,omitempty"`
+	LotTracked   bool                     `json:"lotTracked"`
+	StandardCost *float64                 `json:"standardCost,omitempty"`
+	Price        float32                  `json:"price"`
+	Stock        int                      `json:"stock"`
+	Available    int                      `json:"available"`
+	Inventory    []InventoryResponse      `json:"inventory,omitempty"`
+	CreatedAt    time.Time                `json:"createdAt"`
+	Attributes   map[string]interface{}   `json:"attributes,omitempty"`
+	Options      []ProductOptionResponse  `json:"options,omitempty"`
+	Variants     []ProductVariantResponse `json:"variants,omitempty"`
+	Attachments  []AttachmentResponse     `json:"attachments,omitempty"`
+	DeletedAt    *time.Time               `json:"deletedAt,omitempty"`
 }
 
 type ProductStock struct {
-	Stock     int `json:"stock" validate:"required"`
-	ProductId int `json:"productId"`
-	UserId    int `json:"userId"`
+	Stock       int     `json:"stock" validate:"gte=0"`
+	WarehouseId int     `json:"warehouseId" validate:"required"`
+	VariantId   *int    `json:"variantId"`
+	Bin         *string `json:"bin" validate:"omitempty,max=50"`
+	Reason      string  `json:"reason" validate:"max=255"`
+	ProductId   int     `json:"productId"`
+	UserId      int     `json:"userId"`
+	IfMatch     string  `json:"-"`
+}
+
+type InventoryResponse struct {
+	WarehouseId     int     `json:"warehouseId"`
+	WarehouseName   string  `json:"warehouseName"`
+	VariantId       *int    `json:"variantId"`
+	Bin             *string `json:"bin"`
+	Quantity        int     `json:"quantity"`
+	Reserved        int     `json:"reserved"`
+	Available       int     `json:"available"`
+	ReorderPoint    *int    `json:"reorderPoint"`
+	ReorderQuantity *int    `json:"reorderQuantity"`
+}
+
+type PriceChangeModel struct {
+	Price       float32   `json:"price" validate:"required,gt=0"`
+	Reason      string    `json:"reason" validate:"required,max=255"`
+	EffectiveAt time.Time `json:"effectiveAt" validate:"required"`
+	ProductId   int       `json:"productId"`
+	UserId      int       `json:"userId"`
+}
+
+type PriceChangeResponse struct {
+	Id          int        `json:"id"`
+	ProductId   int        `json:"productId"`
+	OldPrice    *float32   `json:"oldPrice"`
+	NewPrice    float32    `json:"newPrice"`
+	Reason      string     `json:"reason"`
+	Status      string     `json:"status"`
+	EffectiveAt time.Time  `json:"effectiveAt"`
+	AppliedAt   *time.Time `json:"appliedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ChangedBy   struct {
+		Email     string `json:"email"`
+		FirstName string `json:"firstName"`
+	} `json:"changedBy"`
+}
+
+type EffectivePriceResponse struct {
+	ProductId     int       `json:"productId"`
+	At            time.Time `json:"at"`
+	Price         float32   `json:"price"`
+	PriceChangeId *int      `json:"priceChangeId"`
 }
@@ -0,0 +1,99 @@
+package model
+
+import "time"
+
+type SupplierModel struct {
+	Name         string  `json:"name" validate:"required,min=2,max=100"`
+	ContactName  *string `json:"contactName" validate:"omitempty,max=100"`
+	Email        *string `json:"email" validate:"omitempty,email"`
+	Phone        *string `json:"phone" validate:"omitempty,max=30"`
+	Address      *string `json:"address" validate:"omitempty,max=200"`
+	LeadTimeDays *int    `json:"leadTimeDays" validate:"omitempty,gte=0"`
+	Active       *bool   `json:"active"`
+	SupplierId   int     `json:"supplierId"`
+	UserId       int     `json:"userId"`
+}
+
+type SupplierResponse struct {
+	Id           int       `json:"id"`
+	Name         string    `json:"name"`
+	ContactName  *string   `json:"contactName"`
+	Email        *string   `json:"email"`
+	Phone        *string   `json:"phone"`
+	Address      *string   `json:"address"`
+	LeadTimeDays *int      `json:"leadTimeDays"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type PurchaseOrderItemModel struct {
+	ProductId int     `json:"productId" validate:"required"`
+	Quantity  int     `json:"quantity" validate:"required,gt=0"`
+	UnitCost  float64 `json:"unitCost" validate:"gte=0"`
+}
+
+type PurchaseOrderModel struct {
+	SupplierId      int                      `json:"supplierId" validate:"required"`
+	WarehouseId     int                      `json:"warehouseId" validate:"required"`
+	ExpectedAt      *time.Time               `json:"expectedAt"`
+	Note            string                   `json:"note" validate:"max=255"`
+	Items           []PurchaseOrderItemModel `json:"items" validate:"required,min=1,dive"`
+	PurchaseOrderId int                      `json:"purchaseOrderId"`
+	UserId          int                      `json:"userId"`
+}
+
+type GoodsReceiptItemModel struct {
+	ProductId      int        `json:"productId" validate:"required"`
+	Quantity       int        `json:"quantity" validate:"required,gt=0"`
+	LotNumber      string     `json:"lotNumber" validate:"max=50"`
+	ManufacturedAt *time.Time `json:"manufacturedAt"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+	Note           string     `json:"note" validate:"max=255"`
+}
+
+type GoodsReceiptModel struct {
+	Reference       string                  `json:"reference" validate:"max=100"`
+	Items           []GoodsReceiptItemModel `json:"items" validate:"required,min=1,dive"`
+	PurchaseOrderId int                     `json:"purchaseOrderId"`
+	UserId          int                     `json:"userId"`
+}
+
+type PurchaseOrderItemResponse struct {
+	ProductId   int     `json:"productId"`
+	ProductName string  `json:"productName"`
+	Ordered     int     `json:"ordered"`
+	Received    int     `json:"received"`
+	Outstanding int     `json:"outstanding"`
+	UnitCost    float64 `json:"unitCost"`
+	Total       float64 `json:"total"`
+}
+
+type GoodsReceiptResponse struct {
+	Id        int       `json:"id"`
+	ProductId int       `json:"productId"`
+	Quantity  int       `json:"quantity"`
+	UnitCost  float64   `json:"unitCost"`
+	LotNumber string    `json:"lotNumber,omitempty"`
+	Reference string    `json:"reference,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	UserId    int       `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type PurchaseOrderResponse struct {
+	Id          int                         `json:"id"`
+	SupplierId  int                         `json:"supplierId"`
+	WarehouseId int                         `json:"warehouseId"`
+	Status      string                      `json:"status"`
+	ExpectedAt  *time.Time                  `json:"expectedAt"`
+	Note        string                      `json:"note,omitempty"`
+	Total       float64                     `json:"total"`
+	UserId      int                         `json:"userId"`
+	SentAt      *time.Time                  `json:"sentAt"`
+	ReceivedAt  *time.Time                  `json:"receivedAt"`
+	ClosedAt    *time.Time                  `json:"closedAt"`
+	CreatedAt   time.Time                   `json:"createdAt"`
+	Items       []PurchaseOrderItemResponse `json:"items,omitempty"`
+	Receipts    []GoodsReceiptResponse      `json:"receipts,omitempty"`
+}
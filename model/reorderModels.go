@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+type ReorderPointModel struct {
+	WarehouseId     int  `json:"warehouseId" validate:"required"`
+	VariantId       *int `json:"variantId"`
+	ReorderPoint    *int `json:"reorderPoint" validate:"omitempty,gte=0"`
+	ReorderQuantity *int `json:"reorderQuantity" validate:"omitempty,gt=0"`
+	ProductId       int  `json:"productId"`
+	UserId          int  `json:"userId"`
+}
+
+type LowStockItemResponse struct {
+	ProductId       int      `json:"productId"`
+	ProductName     string   `json:"productName"`
+	VariantId       *int     `json:"variantId"`
+	Sku             *string  `json:"sku"`
+	WarehouseId     int      `json:"warehouseId"`
+	WarehouseName   string   `json:"warehouseName"`
+	Quantity        int      `json:"quantity"`
+	Reserved        int      `json:"reserved"`
+	Available       int      `json:"available"`
+	ReorderPoint    int      `json:"reorderPoint"`
+	ReorderQuantity int      `json:"reorderQuantity"`
+	Suggested       int      `json:"suggested"`
+	UnitCost        *float64 `json:"unitCost"` // Last purchase order unit cost, else the standard cost
+	SupplierId      *int     `json:"supplierId"`
+	SupplierName    *string  `json:"supplierName"`
+}
+
+type PurchaseSuggestionResponse struct {
+	WarehouseId   int                    `json:"warehouseId"`
+	WarehouseName string                 `json:"warehouseName"`
+	SupplierId    *int                   `json:"supplierId"` // Null for items never bought before
+	SupplierName  *string                `json:"supplierName"`
+	TotalQuantity int                    `json:"totalQuantity"`
+	TotalCost     float64                `json:"totalCost"`
+	Items         []LowStockItemResponse `json:"items"`
+}
+
+type NotificationResponse struct {
+	Id        int        `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	Link      *string    `json:"link"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type ReservationModel struct {
+	Quantity    int    `json:"quantity" validate:"required,gt=0"`
+	WarehouseId *int   `json:"warehouseId"`
+	VariantId   *int   `json:"variantId"`
+	Reference   string `json:"reference" validate:"max=100"`
+	ProductId   int    `json:"productId"`
+	UserId      int    `json:"userId"`
+}
+
+type ReservationResponse struct {
+	Id          int        `json:"id"`
+	ProductId   int        `json:"productId"`
+	VariantId   *int       `json:"variantId"`
+	WarehouseId int        `json:"warehouseId"`
+	Quantity    int        `json:"quantity"`
+	Status      string     `json:"status"`
+	OrderId     *int       `json:"orderId"`
+	Reference   string     `json:"reference,omitempty"`
+	UserId      int        `json:"userId"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+	ReleasedAt  *time.Time `json:"releasedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
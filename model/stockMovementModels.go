@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+type StockMovementModel struct {
+	WarehouseId    int        `json:"warehouseId" validate:"required"`
+	VariantId      *int       `json:"variantId"`
+	Type           string     `json:"type" validate:"required,oneof=RECEIPT SHIPMENT ADJUSTMENT RETURN"`
+	Quantity       int        `json:"quantity" validate:"required"`
+	Reason         string     `json:"reason" validate:"required,max=255"`
+	Reference      string     `json:"reference" validate:"max=100"`
+	UnitCost       *float64   `json:"unitCost" validate:"omitempty,gte=0"`
+	LotNumber      string     `json:"lotNumber" validate:"max=50"`
+	ManufacturedAt *time.Time `json:"manufacturedAt"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+	ProductId      int        `json:"productId"`
+	UserId         int        `json:"userId"`
+}
+
+type StockMovementResponse struct {
+	Id            int       `json:"id"`
+	ProductId     int       `json:"productId"`
+	VariantId     *int      `json:"variantId"`
+	WarehouseId   int       `json:"warehouseId"`
+	WarehouseName string    `json:"warehouseName"`
+	Type          string    `json:"type"`
+	Quantity      int       `json:"quantity"`
+	Reason        string    `json:"reason"`
+	Reference     string    `json:"reference,omitempty"`
+	LotNumber     *string   `json:"lotNumber"`
+	UnitCost      *float64  `json:"unitCost"`
+	UserId        *int      `json:"userId"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type StockDiscrepancyResponse struct {
+	ProductId   int  `json:"productId"`
+	VariantId   *int `json:"variantId"`
+	WarehouseId int  `json:"warehouseId"`
+	Inventory   int  `json:"inventory"`
+	Ledger      int  `json:"ledger"`
+}
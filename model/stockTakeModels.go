@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+type StockTakeModel struct {
+	WarehouseId int    `json:"warehouseId" validate:"required"`
+	BinPrefix   string `json:"binPrefix" validate:"max=50"`
+	Note        string `json:"note" validate:"max=255"`
+	UserId      int    `json:"userId"`
+}
+
+type StockTakeCountItemModel struct {
+	ProductId int     `json:"productId" validate:"required"`
+	Bin       *string `json:"bin" validate:"omitempty,max=50"`
+	Counted   *int    `json:"counted" validate:"required,gte=0"`
+}
+
+type StockTakeCountModel struct {
+	Items       []StockTakeCountItemModel `json:"items" validate:"required,min=1,dive"`
+	StockTakeId int                       `json:"stockTakeId"`
+	UserId      int                       `json:"userId"`
+}
+
+// StockTakeSheetItemResponse is what a counter sees of an item to count, never its book quantity.
+type StockTakeSheetItemResponse struct {
+	ProductId   int     `json:"productId"`
+	ProductName string  `json:"productName"`
+	Bin         *string `json:"bin"`
+	Counted     *int    `json:"counted"`
+}
+
+type StockTakeSheetResponse struct {
+	Id          int                          `json:"id"`
+	WarehouseId int                          `json:"warehouseId"`
+	Status      string                       `json:"status"`
+	Items       []StockTakeSheetItemResponse `json:"items"`
+}
+
+type StockTakeCountResponse struct {
+	ProductId   int        `json:"productId"`
+	ProductName string     `json:"productName"`
+	Bin         *string    `json:"bin"`
+	Counted     int        `json:"counted"`
+	Book        int        `json:"book"`
+	Variance    int        `json:"variance"`
+	UserId      int        `json:"userId"`
+	CountedAt   time.Time  `json:"countedAt"`
+	PostedAt    *time.Time `json:"postedAt"`
+}
+
+type StockTakeResponse struct {
+	Id          int                      `json:"id"`
+	WarehouseId int                      `json:"warehouseId"`
+	Status      string                   `json:"status"`
+	BinPrefix   string                   `json:"binPrefix,omitempty"`
+	Note        string                   `json:"note,omitempty"`
+	UserId      int                      `json:"userId"`
+	ClosedAt    *time.Time               `json:"closedAt"`
+	ApprovedAt  *time.Time               `json:"approvedAt"`
+	CreatedAt   time.Time                `json:"createdAt"`
+	Counts      []StockTakeCountResponse `json:"counts,omitempty"`
+	Uncounted   []int                    `json:"uncounted,omitempty"`
+}
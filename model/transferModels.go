@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+type TransferItemModel struct {
+	ProductId int  `json:"productId" validate:"required"`
+	VariantId *int `json:"variantId"`
+	Quantity  int  `json:"quantity" validate:"required,gt=0"`
+}
+
+type TransferModel struct {
+	SourceWarehouseId      int                 `json:"sourceWarehouseId" validate:"required"`
+	DestinationWarehouseId int                 `json:"destinationWarehouseId" validate:"required,nefield=SourceWarehouseId"`
+	Note                   string              `json:"note" validate:"max=255"`
+	Items                  []TransferItemModel `json:"items" validate:"required,min=1,dive"`
+	UserId                 int                 `json:"userId"`
+}
+
+type TransferItemsModel struct {
+	Items      []TransferItemModel `json:"items" validate:"required,min=1,dive"`
+	TransferId int                 `json:"transferId"`
+	UserId     int                 `json:"userId"`
+}
+
+type TransferReceiptItemModel struct {
+	ProductId   int    `json:"productId" validate:"required"`
+	VariantId   *int   `json:"variantId"`
+	Received    int    `json:"received" validate:"gte=0"`
+	Discrepancy int    `json:"discrepancy" validate:"gte=0"`
+	Note        string `json:"note" validate:"max=255"`
+}
+
+type TransferReceiptModel struct {
+	Items      []TransferReceiptItemModel `json:"items" validate:"required,min=1,dive"`
+	TransferId int                        `json:"transferId"`
+	UserId     int                        `json:"userId"`
+}
+
+type TransferItemResponse struct {
+	ProductId   int    `json:"productId"`
+	VariantId   *int   `json:"variantId,omitempty"`
+	ProductName string `json:"productName"`
+	Quantity    int    `json:"quantity"`
+	Shipped     int    `json:"shipped"`
+	Received    int    `json:"received"`
+	Discrepancy int    `json:"discrepancy"`
+	Outstanding int    `json:"outstanding"`
+}
+
+type TransferDiscrepancyResponse struct {
+	ProductId int       `json:"productId"`
+	VariantId *int      `json:"variantId,omitempty"`
+	Quantity  int       `json:"quantity"`
+	Note      string    `json:"note"`
+	UserId    int       `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TransferResponse struct {
+	Id                     int                           `json:"id"`
+	SourceWarehouseId      int                           `json:"sourceWarehouseId"`
+	DestinationWarehouseId int                           `json:"destinationWarehouseId"`
+	Status                 string                        `json:"status"`
+	Note                   string                        `json:"note,omitempty"`
+	UserId                 int                           `json:"userId"`
+	DispatchedAt           *time.Time                    `json:"dispatchedAt"`
+	ReceivedAt             *time.Time                    `json:"receivedAt"`
+	CreatedAt              time.Time                     `json:"createdAt"`
+	Items                  []TransferItemResponse        `json:"items,omitempty"`
+	Discrepancies          []TransferDiscrepancyResponse `json:"discrepancies,omitempty"`
+}
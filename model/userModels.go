@@ -6,10 +6,12 @@ import (
 )
 
 type UserCreationModel struct {
-	Name   string `json:"name" validate:"required"`
-	Email  string `json:"email" validate:"required,email"`
-	RoleId int    `json:"roleId" validate:"required"`
-	UserId int    `json:"userId"`
+	Name          string `json:"name" validate:"required"`
+	Email         string `json:"email" validate:"required,email"`
+	RoleId        int    `json:"roleId" validate:"required"`
+	CustomerGroup string `json:"customerGroup" validate:"omitempty,oneof=RETAIL WHOLESALE"`
+	UserId        int    `json:"userId"`
+	IfMatch       string `json:"-"`
 }
 
 type RoleCreationModel struct {
@@ -28,6 +30,7 @@ type UpdateUserInfoModel struct {
 	FirstName string `json:"firstName" validate:"required,min=5,max=32"`
 	LastName  string `json:"lastName" validate:"required,min=5,max=32"`
 	UserId    int    `json:"userId"`
+	IfMatch   string `json:"-"`
 }
 
 type LoginUserModel struct {
@@ -62,12 +65,13 @@ type AuditLogResponse struct {
 }
 
 type UserResponse struct {
-	Id        int       `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	CreatedAt time.Time `json:"createdAt"`
-	Role      struct {
+	Id            int       `json:"id"`
+	Email         string    `json:"email"`
+	FirstName     string    `json:"firstName"`
+	LastName      string    `json:"lastName"`
+	CustomerGroup string    `json:"customerGroup"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Role          struct {
 		Id          int    `json:"id"`
 		Name        string `json:"name"`
 		Permissions string `json:"permissions"`
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+type ValuationMethodModel struct {
+	Method string `json:"method" validate:"required,oneof=FIFO WEIGHTED_AVERAGE"`
+	UserId int    `json:"userId"`
+}
+
+type ValuationFilterModel struct {
+	ProductId   int `json:"productId"`
+	WarehouseId int `json:"warehouseId"`
+}
+
+type ValuationItemResponse struct {
+	ProductId        int     `json:"productId"`
+	ProductName      string  `json:"productName"`
+	Quantity         int     `json:"quantity"`
+	UncostedQuantity int     `json:"uncostedQuantity"`
+	UnitCost         float64 `json:"unitCost"`
+	Value            float64 `json:"value"`
+}
+
+type ValuationResponse struct {
+	Method           string                  `json:"method"`
+	AsOf             time.Time               `json:"asOf"`
+	TotalValue       float64                 `json:"totalValue"`
+	UncostedQuantity int                     `json:"uncostedQuantity"`
+	Items            []ValuationItemResponse `json:"items"`
+}
+
+type CostOfGoodsSoldItemResponse struct {
+	ProductId        int     `json:"productId"`
+	ProductName      string  `json:"productName"`
+	QuantitySold     int     `json:"quantitySold"`
+	UncostedQuantity int     `json:"uncostedQuantity"`
+	Cost             float64 `json:"cost"`
+}
+
+type CostOfGoodsSoldResponse struct {
+	Method           string                        `json:"method"`
+	From             time.Time                     `json:"from"`
+	To               time.Time                     `json:"to"`
+	TotalCost        float64                       `json:"totalCost"`
+	UncostedQuantity int                           `json:"uncostedQuantity"`
+	Items            []CostOfGoodsSoldItemResponse `json:"items"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+type ProductOptionModel struct {
+	Name   string   `json:"name" validate:"required,min=1,max=50"`
+	Values []string `json:"values" validate:"required,min=1,dive,required"`
+}
+
+type ProductOptionsModel struct {
+	Options   []ProductOptionModel `json:"options" validate:"required,min=1,dive"`
+	ProductId int                  `json:"productId"`
+	UserId    int                  `json:"userId"`
+}
+
+type ProductVariantModel struct {
+	Sku         string            `json:"sku" validate:"required,min=3,max=64"`
+	Options     map[string]string `json:"options" validate:"required"`
+	Price       *float32          `json:"price" validate:"omitempty,gt=0"`
+	Stock       int               `json:"stock" validate:"gte=0"`
+	WarehouseId *int              `json:"warehouseId"`
+	ProductId   int               `json:"productId"`
+	VariantId   int               `json:"variantId"`
+	UserId      int               `json:"userId"`
+}
+
+type ProductOptionResponse struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductVariantResponse struct {
+	Id            int               `json:"id"`
+	Sku           string            `json:"sku"`
+	Options       map[string]string `json:"options"`
+	Price         float32           `json:"price"`
+	PriceOverride *float32          `json:"priceOverride"`
+	Stock         int               `json:"stock"`
+	CreatedAt     time.Time         `json:"createdAt"`
+}
+
+type VariantMatrixResponse struct {
+	ProductId int                      `json:"productId"`
+	Options   []ProductOptionResponse  `json:"options"`
+	Variants  []ProductVariantResponse `json:"variants"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+type WarehouseModel struct {
+	Name        string  `json:"name" validate:"required,min=3,max=100"`
+	Location    string  `json:"location" validate:"required,max=100"`
+	Address     *string `json:"address" validate:"omitempty,max=200"`
+	City        *string `json:"city" validate:"omitempty,max=100"`
+	PostalCode  *string `json:"postalCode" validate:"omitempty,max=20"`
+	Country     *string `json:"country" validate:"omitempty,len=2,alpha,uppercase"`
+	Active      *bool   `json:"active"`
+	WarehouseId int     `json:"warehouseId"`
+	UserId      int     `json:"userId"`
+}
+
+type WarehouseResponse struct {
+	Id         int       `json:"id"`
+	Name       string    `json:"name"`
+	Location   string    `json:"location"`
+	Address    *string   `json:"address"`
+	City       *string   `json:"city"`
+	PostalCode *string   `json:"postalCode"`
+	Country    *string   `json:"country"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type WarehouseStockItemResponse struct {
+	ProductId   int     `json:"productId"`
+	ProductName string  `json:"productName"`
+	VariantId   *int    `json:"variantId"`
+	Sku         *string `json:"sku"`
+	Bin         *string `json:"bin"`
+	Quantity    int     `json:"quantity"`
+	Value       float64 `json:"value"`
+}
+
+type WarehouseStockResponse struct {
+	WarehouseId   int                          `json:"warehouseId"`
+	Name          string                       `json:"name"`
+	ProductCount  int                          `json:"productCount"`
+	TotalQuantity int                          `json:"totalQuantity"`
+	TotalValue    float64                      `json:"totalValue"`
+	Items         []WarehouseStockItemResponse `json:"items"`
+}
@@ -0,0 +1,27 @@
+-- CreateEnum
+CREATE TYPE "AttachmentKind" AS ENUM ('IMAGE', 'DOCUMENT');
+
+-- CreateTable
+CREATE TABLE "ProductAttachment" (
+    "id" SERIAL NOT NULL,
+    "productId" INTEGER NOT NULL,
+    "kind" "AttachmentKind" NOT NULL,
+    "fileName" TEXT NOT NULL,
+    "contentType" TEXT NOT NULL,
+    "size" INTEGER NOT NULL,
+    "storageKey" TEXT NOT NULL,
+    "thumbnailKey" TEXT,
+    "userId" INTEGER NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "ProductAttachment_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "ProductAttachment_storageKey_key" ON "ProductAttachment"("storageKey");
+
+-- AddForeignKey
+ALTER TABLE "ProductAttachment" ADD CONSTRAINT "ProductAttachment_productId_fkey" FOREIGN KEY ("productId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "ProductAttachment" ADD CONSTRAINT "ProductAttachment_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
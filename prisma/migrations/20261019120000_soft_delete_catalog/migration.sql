@@ -0,0 +1,5 @@
+-- AlterTable
+ALTER TABLE "Category" ADD COLUMN     "deletedAt" TIMESTAMP(3);
+
+-- AlterTable
+ALTER TABLE "Product" ADD COLUMN     "deletedAt" TIMESTAMP(3);
@@ -0,0 +1,27 @@
+-- CreateTable
+CREATE TABLE "PriceChange" (
+    "id" SERIAL NOT NULL,
+    "productId" INTEGER NOT NULL,
+    "oldPrice" DOUBLE PRECISION,
+    "newPrice" DOUBLE PRECISION NOT NULL,
+    "reason" TEXT NOT NULL,
+    "effectiveAt" TIMESTAMP(3) NOT NULL,
+    "appliedAt" TIMESTAMP(3),
+    "userId" INTEGER NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "PriceChange_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "PriceChange_productId_effectiveAt_idx" ON "PriceChange"("productId", "effectiveAt");
+
+-- AddForeignKey
+ALTER TABLE "PriceChange" ADD CONSTRAINT "PriceChange_productId_fkey" FOREIGN KEY ("productId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "PriceChange" ADD CONSTRAINT "PriceChange_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- Backfill the current price of every existing product as its first history entry
+INSERT INTO "PriceChange" ("productId", "newPrice", "reason", "effectiveAt", "appliedAt", "userId")
+SELECT "id", "price", 'Initial price', "createdAt", "createdAt", "userId" FROM "Product";
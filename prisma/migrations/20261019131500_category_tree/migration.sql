@@ -0,0 +1,18 @@
+/*
+  Warnings:
+
+  - You are about to drop the column `productId` on the `Category` table. All the data in the column will be lost.
+
+*/
+-- DropForeignKey
+ALTER TABLE "Category" DROP CONSTRAINT "Category_productId_fkey";
+
+-- AlterTable
+ALTER TABLE "Category" DROP COLUMN "productId",
+ADD COLUMN     "parentId" INTEGER;
+
+-- CreateIndex
+CREATE INDEX "Category_parentId_idx" ON "Category"("parentId");
+
+-- AddForeignKey
+ALTER TABLE "Category" ADD CONSTRAINT "Category_parentId_fkey" FOREIGN KEY ("parentId") REFERENCES "Category"("id") ON DELETE SET NULL ON UPDATE CASCADE;
@@ -0,0 +1,3 @@
+-- AlterTable
+ALTER TABLE "Category" ADD COLUMN "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE "Category" ALTER COLUMN "updatedAt" DROP DEFAULT;
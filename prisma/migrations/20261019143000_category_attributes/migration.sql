@@ -0,0 +1,26 @@
+-- CreateEnum
+CREATE TYPE "AttributeType" AS ENUM ('STRING', 'NUMBER', 'BOOLEAN');
+
+-- AlterTable
+ALTER TABLE "Product" ADD COLUMN     "attributes" JSONB NOT NULL DEFAULT '{}';
+
+-- CreateTable
+CREATE TABLE "CategoryAttribute" (
+    "id" SERIAL NOT NULL,
+    "categoryId" INTEGER NOT NULL,
+    "name" TEXT NOT NULL,
+    "type" "AttributeType" NOT NULL,
+    "required" BOOLEAN NOT NULL DEFAULT false,
+    "allowedValues" TEXT[],
+
+    CONSTRAINT "CategoryAttribute_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "CategoryAttribute_categoryId_name_key" ON "CategoryAttribute"("categoryId", "name");
+
+-- CreateIndex
+CREATE INDEX "Product_attributes_idx" ON "Product" USING GIN ("attributes" jsonb_path_ops);
+
+-- AddForeignKey
+ALTER TABLE "CategoryAttribute" ADD CONSTRAINT "CategoryAttribute_categoryId_fkey" FOREIGN KEY ("categoryId") REFERENCES "Category"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
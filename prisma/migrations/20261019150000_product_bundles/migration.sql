@@ -0,0 +1,28 @@
+-- CreateEnum
+CREATE TYPE "ProductType" AS ENUM ('STANDARD', 'BUNDLE');
+
+-- AlterTable
+ALTER TABLE "Product" ADD COLUMN     "bundleDiscount" DOUBLE PRECISION,
+ADD COLUMN     "type" "ProductType" NOT NULL DEFAULT 'STANDARD';
+
+-- CreateTable
+CREATE TABLE "BundleComponent" (
+    "id" SERIAL NOT NULL,
+    "bundleId" INTEGER NOT NULL,
+    "componentId" INTEGER NOT NULL,
+    "quantity" INTEGER NOT NULL,
+
+    CONSTRAINT "BundleComponent_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "BundleComponent_componentId_idx" ON "BundleComponent"("componentId");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "BundleComponent_bundleId_componentId_key" ON "BundleComponent"("bundleId", "componentId");
+
+-- AddForeignKey
+ALTER TABLE "BundleComponent" ADD CONSTRAINT "BundleComponent_bundleId_fkey" FOREIGN KEY ("bundleId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "BundleComponent" ADD CONSTRAINT "BundleComponent_componentId_fkey" FOREIGN KEY ("componentId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
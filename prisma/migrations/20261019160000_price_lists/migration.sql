@@ -0,0 +1,66 @@
+-- CreateEnum
+CREATE TYPE "CustomerGroup" AS ENUM ('RETAIL', 'WHOLESALE');
+
+-- AlterTable
+ALTER TABLE "User" ADD COLUMN     "customerGroup" "CustomerGroup" NOT NULL DEFAULT 'RETAIL';
+
+-- CreateTable
+CREATE TABLE "PriceList" (
+    "id" SERIAL NOT NULL,
+    "name" TEXT NOT NULL,
+    "currency" TEXT NOT NULL,
+    "customerGroup" "CustomerGroup",
+    "priority" INTEGER NOT NULL DEFAULT 0,
+    "validFrom" TIMESTAMP(3),
+    "validTo" TIMESTAMP(3),
+    "active" BOOLEAN NOT NULL DEFAULT true,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "PriceList_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "PriceListItem" (
+    "id" SERIAL NOT NULL,
+    "priceListId" INTEGER NOT NULL,
+    "productId" INTEGER NOT NULL,
+    "variantId" INTEGER,
+    "minQuantity" INTEGER NOT NULL DEFAULT 1,
+    "price" DOUBLE PRECISION NOT NULL,
+
+    CONSTRAINT "PriceListItem_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "ExchangeRate" (
+    "id" SERIAL NOT NULL,
+    "baseCurrency" TEXT NOT NULL,
+    "quoteCurrency" TEXT NOT NULL,
+    "rate" DOUBLE PRECISION NOT NULL,
+    "effectiveAt" TIMESTAMP(3) NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "ExchangeRate_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "PriceList_name_key" ON "PriceList"("name");
+
+-- CreateIndex
+CREATE INDEX "PriceListItem_productId_idx" ON "PriceListItem"("productId");
+
+-- CreateIndex, a list prices a product and each of its variants once per tier
+CREATE UNIQUE INDEX "PriceListItem_priceListId_productId_variantId_minQuantity_key" ON "PriceListItem"("priceListId", "productId", "variantId", "minQuantity") NULLS NOT DISTINCT;
+
+-- CreateIndex
+CREATE UNIQUE INDEX "ExchangeRate_baseCurrency_quoteCurrency_effectiveAt_key" ON "ExchangeRate"("baseCurrency", "quoteCurrency", "effectiveAt");
+
+-- AddForeignKey
+ALTER TABLE "PriceListItem" ADD CONSTRAINT "PriceListItem_priceListId_fkey" FOREIGN KEY ("priceListId") REFERENCES "PriceList"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "PriceListItem" ADD CONSTRAINT "PriceListItem_productId_fkey" FOREIGN KEY ("productId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "PriceListItem" ADD CONSTRAINT "PriceListItem_variantId_fkey" FOREIGN KEY ("variantId") REFERENCES "ProductVariant"("id") ON DELETE CASCADE ON UPDATE CASCADE;
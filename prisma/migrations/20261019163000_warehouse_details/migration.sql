@@ -0,0 +1,17 @@
+-- AlterTable
+ALTER TABLE "Warehouse" ADD COLUMN     "address" TEXT,
+ADD COLUMN     "city" TEXT,
+ADD COLUMN     "postalCode" TEXT,
+ADD COLUMN     "country" TEXT,
+ADD COLUMN     "active" BOOLEAN NOT NULL DEFAULT true,
+ADD COLUMN     "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+ADD COLUMN     "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE "Warehouse" ALTER COLUMN "updatedAt" DROP DEFAULT;
+
+-- Warehouses that share a name with an older one get their id appended so names can be unique
+UPDATE "Warehouse" w
+SET "name" = w."name" || ' (' || w."id" || ')'
+WHERE EXISTS (SELECT 1 FROM "Warehouse" o WHERE o."name" = w."name" AND o."id" < w."id");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "Warehouse_name_key" ON "Warehouse"("name");
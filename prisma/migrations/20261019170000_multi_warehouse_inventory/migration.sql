@@ -0,0 +1,47 @@
+-- DropForeignKey
+ALTER TABLE "Inventory" DROP CONSTRAINT "Inventory_variantId_fkey";
+
+-- DropIndex
+DROP INDEX "Inventory_productId_key";
+
+-- AlterTable
+ALTER TABLE "Inventory" ALTER COLUMN "quantity" SET DEFAULT 0;
+ALTER TABLE "Inventory" RENAME COLUMN "location" TO "bin";
+ALTER TABLE "Inventory" ALTER COLUMN "bin" DROP NOT NULL;
+ALTER TABLE "Inventory" ADD CONSTRAINT "Inventory_quantity_check" CHECK ("quantity" >= 0);
+
+-- A product had a single inventory record, which holds its own stock from now on
+UPDATE "Inventory" SET "variantId" = NULL WHERE "variantId" IS NOT NULL;
+
+-- CreateIndex, a product keeps one record per warehouse for its own stock and one per variant
+CREATE UNIQUE INDEX "Inventory_productId_variantId_warehouseId_key" ON "Inventory"("productId", "variantId", "warehouseId") NULLS NOT DISTINCT;
+
+-- CreateIndex
+CREATE INDEX "Inventory_warehouseId_idx" ON "Inventory"("warehouseId");
+
+-- AddForeignKey
+ALTER TABLE "Inventory" ADD CONSTRAINT "Inventory_variantId_fkey" FOREIGN KEY ("variantId") REFERENCES "ProductVariant"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- Move stock that was only recorded on the product or the variant into a default warehouse
+INSERT INTO "Warehouse" ("name", "location", "updatedAt")
+SELECT 'Main', 'Default warehouse', CURRENT_TIMESTAMP
+WHERE EXISTS (
+    SELECT 1 FROM "Product" p
+    WHERE p."stock" > 0 AND NOT EXISTS (SELECT 1 FROM "Inventory" i WHERE i."productId" = p."id")
+) OR EXISTS (SELECT 1 FROM "ProductVariant" WHERE "stock" > 0)
+ON CONFLICT ("name") DO NOTHING;
+
+INSERT INTO "Inventory" ("productId", "warehouseId", "quantity", "updatedAt")
+SELECT p."id", w."id", p."stock", CURRENT_TIMESTAMP
+FROM "Product" p
+JOIN "Warehouse" w ON w."name" = 'Main'
+WHERE p."stock" > 0 AND NOT EXISTS (SELECT 1 FROM "Inventory" i WHERE i."productId" = p."id");
+
+INSERT INTO "Inventory" ("productId", "variantId", "warehouseId", "quantity", "updatedAt")
+SELECT v."productId", v."id", w."id", v."stock", CURRENT_TIMESTAMP
+FROM "ProductVariant" v
+JOIN "Warehouse" w ON w."name" = 'Main'
+WHERE v."stock" > 0;
+
+UPDATE "Product" p
+SET "stock" = COALESCE((SELECT SUM(i."quantity") FROM "Inventory" i WHERE i."productId" = p."id"), 0);
@@ -0,0 +1,53 @@
+-- CreateEnum
+CREATE TYPE "StockMovementType" AS ENUM ('RECEIPT', 'SHIPMENT', 'ADJUSTMENT', 'TRANSFER', 'RETURN');
+
+-- CreateTable
+CREATE TABLE "StockMovement" (
+    "id" SERIAL NOT NULL,
+    "productId" INTEGER NOT NULL,
+    "variantId" INTEGER,
+    "warehouseId" INTEGER NOT NULL,
+    "type" "StockMovementType" NOT NULL,
+    "quantity" INTEGER NOT NULL,
+    "reason" TEXT NOT NULL,
+    "reference" TEXT,
+    "userId" INTEGER,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "StockMovement_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "StockMovement_productId_createdAt_idx" ON "StockMovement"("productId", "createdAt");
+
+-- CreateIndex
+CREATE INDEX "StockMovement_warehouseId_idx" ON "StockMovement"("warehouseId");
+
+-- AddForeignKey
+ALTER TABLE "StockMovement" ADD CONSTRAINT "StockMovement_productId_fkey" FOREIGN KEY ("productId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockMovement" ADD CONSTRAINT "StockMovement_variantId_fkey" FOREIGN KEY ("variantId") REFERENCES "ProductVariant"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockMovement" ADD CONSTRAINT "StockMovement_warehouseId_fkey" FOREIGN KEY ("warehouseId") REFERENCES "Warehouse"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockMovement" ADD CONSTRAINT "StockMovement_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- The ledger is append-only
+CREATE FUNCTION "stock_movement_append_only"() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'StockMovement rows cannot be changed or removed';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "StockMovement_append_only"
+BEFORE UPDATE OR DELETE ON "StockMovement"
+FOR EACH ROW EXECUTE FUNCTION "stock_movement_append_only"();
+
+-- Opening balances so the ledger agrees with existing inventory
+INSERT INTO "StockMovement" ("productId", "variantId", "warehouseId", "type", "quantity", "reason")
+SELECT "productId", "variantId", "warehouseId", 'ADJUSTMENT', "quantity", 'Opening balance'
+FROM "Inventory"
+WHERE "quantity" <> 0;
@@ -0,0 +1,106 @@
+-- CreateEnum
+CREATE TYPE "TransferStatus" AS ENUM ('DRAFT', 'IN_TRANSIT', 'RECEIVED', 'CANCELLED');
+
+-- CreateTable
+CREATE TABLE "TransferOrder" (
+    "id" SERIAL NOT NULL,
+    "sourceWarehouseId" INTEGER NOT NULL,
+    "destinationWarehouseId" INTEGER NOT NULL,
+    "status" "TransferStatus" NOT NULL DEFAULT 'DRAFT',
+    "note" TEXT,
+    "userId" INTEGER NOT NULL,
+    "dispatchedAt" TIMESTAMP(3),
+    "receivedAt" TIMESTAMP(3),
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "TransferOrder_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "TransferOrderItem" (
+    "id" SERIAL NOT NULL,
+    "transferOrderId" INTEGER NOT NULL,
+    "productId" INTEGER NOT NULL,
+    "variantId" INTEGER,
+    "quantity" INTEGER NOT NULL,
+    "shippedQuantity" INTEGER NOT NULL DEFAULT 0,
+    "receivedQuantity" INTEGER NOT NULL DEFAULT 0,
+    "discrepancyQuantity" INTEGER NOT NULL DEFAULT 0,
+
+    CONSTRAINT "TransferOrderItem_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "TransferDiscrepancy" (
+    "id" SERIAL NOT NULL,
+    "transferOrderId" INTEGER NOT NULL,
+    "productId" INTEGER NOT NULL,
+    "variantId" INTEGER,
+    "quantity" INTEGER NOT NULL,
+    "note" TEXT NOT NULL,
+    "userId" INTEGER NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "TransferDiscrepancy_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "TransferOrder_status_idx" ON "TransferOrder"("status");
+
+-- CreateIndex, a transfer has one line for the product's own stock and one per variant
+CREATE UNIQUE INDEX "TransferOrderItem_transferOrderId_productId_variantId_key" ON "TransferOrderItem"("transferOrderId", "productId", "variantId") NULLS NOT DISTINCT;
+
+-- AddForeignKey
+ALTER TABLE "TransferOrder" ADD CONSTRAINT "TransferOrder_sourceWarehouseId_fkey" FOREIGN KEY ("sourceWarehouseId") REFERENCES "Warehouse"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "TransferOrder" ADD CONSTRAINT "TransferOrder_destinationWarehouseId_fkey" FOREIGN KEY ("destinationWarehouseId") REFERENCES "Warehouse"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "TransferOrder" ADD CONSTRAINT "TransferOrder_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "TransferOrderItem" ADD CONSTRAINT "TransferOrderItem_transferOrderId_fkey" FOREIGN KEY ("transferOrderId") REFERENCES "TransferOrder"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "TransferOrderItem" ADD CONSTRAINT "TransferOrderItem_productId_fkey" FOREIGN KEY ("productId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "TransferOrderItem" ADD CONSTRAINT "TransferOrderItem_variantId_fkey" FOREIGN KEY ("variantId") REFERENCES "ProductVariant"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "TransferDiscrepancy" ADD CONSTRAINT "TransferDiscrepancy_transferOrderId_fkey" FOREIGN KEY ("transferOrderId") REFERENCES "TransferOrder"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "TransferDiscrepancy" ADD CONSTRAINT "TransferDiscrepancy_productId_fkey" FOREIGN KEY ("productId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "TransferDiscrepancy" ADD CONSTRAINT "TransferDiscrepancy_variantId_fkey" FOREIGN KEY ("variantId") REFERENCES "ProductVariant"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "TransferDiscrepancy" ADD CONSTRAINT "TransferDiscrepancy_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- A line can never ship more than was ordered, nor receive more than was shipped
+ALTER TABLE "TransferOrderItem" ADD CONSTRAINT "TransferOrderItem_quantity_check" CHECK (
+    "quantity" > 0
+    AND "shippedQuantity" <= "quantity"
+    AND "receivedQuantity" >= 0
+    AND "discrepancyQuantity" >= 0
+    AND "receivedQuantity" + "discrepancyQuantity" <= "shippedQuantity"
+);
+
+-- Shipped and received quantities only move while the transfer is in transit, so a transfer cancelled or
+-- received concurrently makes the dispatch or receipt fail instead of moving stock
+CREATE FUNCTION "transfer_item_in_transit"() RETURNS trigger AS $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM "TransferOrder" WHERE "id" = NEW."transferOrderId" AND "status" = 'IN_TRANSIT') THEN
+        RAISE EXCEPTION 'TransferOrder % is not in transit', NEW."transferOrderId";
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "TransferOrderItem_in_transit"
+BEFORE UPDATE OF "shippedQuantity", "receivedQuantity", "discrepancyQuantity" ON "TransferOrderItem"
+FOR EACH ROW EXECUTE FUNCTION "transfer_item_in_transit"();
@@ -0,0 +1,65 @@
+-- CreateEnum
+CREATE TYPE "ReservationStatus" AS ENUM ('ACTIVE', 'RELEASED', 'EXPIRED', 'CONSUMED');
+
+-- AlterTable
+ALTER TABLE "Inventory" ADD COLUMN     "reserved" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "Inventory" ADD CONSTRAINT "Inventory_reserved_check" CHECK ("reserved" >= 0 AND "reserved" <= "quantity");
+
+-- CreateTable
+CREATE TABLE "StockReservation" (
+    "id" SERIAL NOT NULL,
+    "productId" INTEGER NOT NULL,
+    "variantId" INTEGER,
+    "warehouseId" INTEGER NOT NULL,
+    "quantity" INTEGER NOT NULL,
+    "status" "ReservationStatus" NOT NULL DEFAULT 'ACTIVE',
+    "orderId" INTEGER,
+    "reference" TEXT,
+    "userId" INTEGER NOT NULL,
+    "expiresAt" TIMESTAMP(3),
+    "releasedAt" TIMESTAMP(3),
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "StockReservation_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "StockReservation_quantity_check" CHECK ("quantity" > 0)
+);
+
+-- CreateIndex
+CREATE INDEX "StockReservation_status_expiresAt_idx" ON "StockReservation"("status", "expiresAt");
+
+-- CreateIndex
+CREATE INDEX "StockReservation_orderId_idx" ON "StockReservation"("orderId");
+
+-- CreateIndex
+CREATE INDEX "StockReservation_productId_idx" ON "StockReservation"("productId");
+
+-- AddForeignKey
+ALTER TABLE "StockReservation" ADD CONSTRAINT "StockReservation_productId_fkey" FOREIGN KEY ("productId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockReservation" ADD CONSTRAINT "StockReservation_variantId_fkey" FOREIGN KEY ("variantId") REFERENCES "ProductVariant"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockReservation" ADD CONSTRAINT "StockReservation_warehouseId_fkey" FOREIGN KEY ("warehouseId") REFERENCES "Warehouse"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockReservation" ADD CONSTRAINT "StockReservation_orderId_fkey" FOREIGN KEY ("orderId") REFERENCES "Order"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockReservation" ADD CONSTRAINT "StockReservation_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- A reservation is claimed by one order while it is active, an order taking over a reservation that was
+-- claimed, released or expired in the meantime is refused so concurrent orders cannot both count it
+CREATE FUNCTION "stock_reservation_claim"() RETURNS trigger AS $$
+BEGIN
+    IF NEW."orderId" IS NOT NULL AND NEW."orderId" IS DISTINCT FROM OLD."orderId"
+         AND (OLD."orderId" IS NOT NULL OR OLD."status" <> 'ACTIVE') THEN
+        RAISE EXCEPTION 'StockReservation % is no longer available to claim', OLD."id";
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "StockReservation_claim"
+BEFORE UPDATE OF "orderId" ON "StockReservation"
+FOR EACH ROW EXECUTE FUNCTION "stock_reservation_claim"();
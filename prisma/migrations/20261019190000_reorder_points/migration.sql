@@ -0,0 +1,28 @@
+-- CreateEnum
+CREATE TYPE "NotificationType" AS ENUM ('LOW_STOCK');
+
+-- AlterTable
+ALTER TABLE "Inventory" ADD COLUMN     "reorderPoint" INTEGER,
+ADD COLUMN     "reorderQuantity" INTEGER,
+ADD COLUMN     "lowStockAlertedAt" TIMESTAMP(3);
+ALTER TABLE "Inventory" ADD CONSTRAINT "Inventory_reorder_check" CHECK ("reorderPoint" >= 0 AND "reorderQuantity" > 0);
+
+-- CreateTable
+CREATE TABLE "Notification" (
+    "id" SERIAL NOT NULL,
+    "userId" INTEGER NOT NULL,
+    "type" "NotificationType" NOT NULL,
+    "title" TEXT NOT NULL,
+    "message" TEXT NOT NULL,
+    "link" TEXT,
+    "readAt" TIMESTAMP(3),
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "Notification_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "Notification_userId_readAt_idx" ON "Notification"("userId", "readAt");
+
+-- AddForeignKey
+ALTER TABLE "Notification" ADD CONSTRAINT "Notification_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE CASCADE ON UPDATE CASCADE;
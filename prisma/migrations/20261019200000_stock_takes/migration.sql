@@ -0,0 +1,74 @@
+-- CreateEnum
+CREATE TYPE "StockTakeStatus" AS ENUM ('OPEN', 'COUNTED', 'APPROVED', 'CANCELLED');
+
+-- CreateTable
+CREATE TABLE "StockTake" (
+    "id" SERIAL NOT NULL,
+    "warehouseId" INTEGER NOT NULL,
+    "status" "StockTakeStatus" NOT NULL DEFAULT 'OPEN',
+    "binPrefix" TEXT,
+    "note" TEXT,
+    "userId" INTEGER NOT NULL,
+    "closedAt" TIMESTAMP(3),
+    "approvedAt" TIMESTAMP(3),
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "StockTake_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "StockTakeCount" (
+    "id" SERIAL NOT NULL,
+    "stockTakeId" INTEGER NOT NULL,
+    "productId" INTEGER NOT NULL,
+    "bin" TEXT,
+    "countedQuantity" INTEGER NOT NULL,
+    "bookQuantity" INTEGER NOT NULL,
+    "userId" INTEGER NOT NULL,
+    "postedAt" TIMESTAMP(3),
+    "countedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "StockTakeCount_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "StockTakeCount_quantity_check" CHECK ("countedQuantity" >= 0)
+);
+
+-- CreateIndex
+CREATE INDEX "StockTake_warehouseId_status_idx" ON "StockTake"("warehouseId", "status");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "StockTakeCount_stockTakeId_productId_key" ON "StockTakeCount"("stockTakeId", "productId");
+
+-- AddForeignKey
+ALTER TABLE "StockTake" ADD CONSTRAINT "StockTake_warehouseId_fkey" FOREIGN KEY ("warehouseId") REFERENCES "Warehouse"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockTake" ADD CONSTRAINT "StockTake_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockTakeCount" ADD CONSTRAINT "StockTakeCount_stockTakeId_fkey" FOREIGN KEY ("stockTakeId") REFERENCES "StockTake"("id") ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockTakeCount" ADD CONSTRAINT "StockTakeCount_productId_fkey" FOREIGN KEY ("productId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "StockTakeCount" ADD CONSTRAINT "StockTakeCount_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- Counts are only submitted while the stock take is open and only posted, once, after counting was closed, so
+-- a count racing the close or a second approval fails instead of changing stock
+CREATE FUNCTION "stock_take_count_writable"() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW."postedAt" IS NOT NULL THEN
+        IF OLD."postedAt" IS NOT NULL OR NOT EXISTS (SELECT 1 FROM "StockTake" WHERE "id" = NEW."stockTakeId" AND "status" = 'COUNTED') THEN
+            RAISE EXCEPTION 'StockTake % is not awaiting approval', NEW."stockTakeId";
+        END IF;
+    ELSIF NOT EXISTS (SELECT 1 FROM "StockTake" WHERE "id" = NEW."stockTakeId" AND "status" = 'OPEN') THEN
+        RAISE EXCEPTION 'StockTake % is not open for counting', NEW."stockTakeId";
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "StockTakeCount_writable"
+BEFORE INSERT OR UPDATE ON "StockTakeCount"
+FOR EACH ROW EXECUTE FUNCTION "stock_take_count_writable"();
@@ -0,0 +1,67 @@
+-- AlterTable
+ALTER TABLE "Product" ADD COLUMN     "lotTracked" BOOLEAN NOT NULL DEFAULT false;
+
+-- AlterTable
+ALTER TABLE "StockMovement" ADD COLUMN     "lotId" INTEGER;
+
+-- AlterTable
+ALTER TABLE "GoodsReceipt" ADD COLUMN     "lotNumber" TEXT;
+
+-- CreateTable
+CREATE TABLE "InventoryLot" (
+    "id" SERIAL NOT NULL,
+    "productId" INTEGER NOT NULL,
+    "warehouseId" INTEGER NOT NULL,
+    "lotNumber" TEXT NOT NULL,
+    "manufacturedAt" TIMESTAMP(3),
+    "expiresAt" TIMESTAMP(3),
+    "quantity" INTEGER NOT NULL DEFAULT 0,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "InventoryLot_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "InventoryLot_quantity_check" CHECK ("quantity" >= 0)
+);
+
+-- CreateTable
+CREATE TABLE "OrderLotAllocation" (
+    "id" SERIAL NOT NULL,
+    "orderId" INTEGER NOT NULL,
+    "productId" INTEGER NOT NULL,
+    "lotId" INTEGER NOT NULL,
+    "quantity" INTEGER NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "OrderLotAllocation_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "OrderLotAllocation_quantity_check" CHECK ("quantity" > 0)
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "InventoryLot_productId_warehouseId_lotNumber_key" ON "InventoryLot"("productId", "warehouseId", "lotNumber");
+
+-- CreateIndex
+CREATE INDEX "InventoryLot_expiresAt_idx" ON "InventoryLot"("expiresAt");
+
+-- CreateIndex
+CREATE INDEX "OrderLotAllocation_lotId_idx" ON "OrderLotAllocation"("lotId");
+
+-- CreateIndex
+CREATE INDEX "OrderLotAllocation_orderId_idx" ON "OrderLotAllocation"("orderId");
+
+-- AddForeignKey
+ALTER TABLE "StockMovement" ADD CONSTRAINT "StockMovement_lotId_fkey" FOREIGN KEY ("lotId") REFERENCES "InventoryLot"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "InventoryLot" ADD CONSTRAINT "InventoryLot_productId_fkey" FOREIGN KEY ("productId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "InventoryLot" ADD CONSTRAINT "InventoryLot_warehouseId_fkey" FOREIGN KEY ("warehouseId") REFERENCES "Warehouse"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "OrderLotAllocation" ADD CONSTRAINT "OrderLotAllocation_orderId_fkey" FOREIGN KEY ("orderId") REFERENCES "Order"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "OrderLotAllocation" ADD CONSTRAINT "OrderLotAllocation_productId_fkey" FOREIGN KEY ("productId") REFERENCES "Product"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "OrderLotAllocation" ADD CONSTRAINT "OrderLotAllocation_lotId_fkey" FOREIGN KEY ("lotId") REFERENCES "InventoryLot"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
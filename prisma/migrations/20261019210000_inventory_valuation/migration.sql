@@ -0,0 +1,37 @@
+-- CreateEnum
+CREATE TYPE "ValuationMethod" AS ENUM ('FIFO', 'WEIGHTED_AVERAGE');
+
+-- AlterTable
+ALTER TABLE "Product" ADD COLUMN     "standardCost" DOUBLE PRECISION;
+ALTER TABLE "Product" ADD CONSTRAINT "Product_standardCost_check" CHECK ("standardCost" IS NULL OR "standardCost" >= 0);
+
+-- CreateTable, the ledger is append-only so unit costs are kept beside it
+CREATE TABLE "StockMovementCost" (
+    "movementId" INTEGER NOT NULL,
+    "unitCost" DOUBLE PRECISION NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "StockMovementCost_pkey" PRIMARY KEY ("movementId"),
+    CONSTRAINT "StockMovementCost_unitCost_check" CHECK ("unitCost" >= 0)
+);
+
+-- AddForeignKey
+ALTER TABLE "StockMovementCost" ADD CONSTRAINT "StockMovementCost_movementId_fkey" FOREIGN KEY ("movementId") REFERENCES "StockMovement"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- CreateTable
+CREATE TABLE "CompanySetting" (
+    "id" INTEGER NOT NULL DEFAULT 1,
+    "valuationMethod" "ValuationMethod" NOT NULL DEFAULT 'FIFO',
+    "updatedAt" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "CompanySetting_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "CompanySetting_single_row_check" CHECK ("id" = 1)
+);
+
+-- Purchase order receipts were made at the ordered unit cost
+INSERT INTO "StockMovementCost" ("movementId", "unitCost")
+SELECT m."id", i."unitCost"
+FROM "StockMovement" m
+JOIN "PurchaseOrder" o ON m."reference" = 'PO-' || o."id"
+JOIN "PurchaseOrderItem" i ON i."purchaseOrderId" = o."id" AND i."productId" = m."productId"
+WHERE m."type" = 'RECEIPT' AND m."quantity" > 0;
@@ -0,0 +1,44 @@
+-- AlterEnum
+ALTER TYPE "NotificationType" ADD VALUE 'ORDER_STATUS';
+
+-- CreateTable
+CREATE TABLE "OrderStatusHistory" (
+    "id" SERIAL NOT NULL,
+    "orderId" INTEGER NOT NULL,
+    "fromStatus" "OrderStatus",
+    "toStatus" "OrderStatus" NOT NULL,
+    "userId" INTEGER NOT NULL,
+    "note" TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "OrderStatusHistory_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "OrderStatusHistory_orderId_idx" ON "OrderStatusHistory"("orderId");
+
+-- AddForeignKey
+ALTER TABLE "OrderStatusHistory" ADD CONSTRAINT "OrderStatusHistory_orderId_fkey" FOREIGN KEY ("orderId") REFERENCES "Order"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "OrderStatusHistory" ADD CONSTRAINT "OrderStatusHistory_userId_fkey" FOREIGN KEY ("userId") REFERENCES "User"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- Existing orders start their history in their current status
+INSERT INTO "OrderStatusHistory" ("orderId", "toStatus", "userId", "createdAt")
+SELECT "id", "status", "userId", "createdAt" FROM "Order";
+
+-- Only legal transitions, setting the status an order already has is refused too so a transition cannot be
+-- applied twice by concurrent requests
+CREATE FUNCTION "order_status_transition"() RETURNS trigger AS $$
+BEGIN
+    IF NOT ((OLD."status" = 'PENDING' AND NEW."status" IN ('SHIPPED', 'CANCELLED'))
+         OR (OLD."status" = 'SHIPPED' AND NEW."status" = 'DELIVERED')) THEN
+        RAISE EXCEPTION 'Order % cannot go from % to %', OLD."id", OLD."status", NEW."status";
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "Order_status_transition"
+BEFORE UPDATE OF "status" ON "Order"
+FOR EACH ROW EXECUTE FUNCTION "order_status_transition"();
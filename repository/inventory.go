@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"Enterprise/prisma/db"
+	"golang.org/x/net/context"
+	"strings"
+	"time"
+)
+
+// StockMovement returns the writes that record a movement in the stock ledger and apply it to the warehouse's
+// inventory of the product, or of one of its variants when variantId is set, and to the product's total. quantity
+// is the signed change. A movement that would take the warehouse below zero makes the transaction fail on the
+// Inventory quantity check. unitCost is what each unit of an inbound movement cost, see movementCost.
+func StockMovement(dbClient *db.PrismaClient, productId int, variantId *int, warehouseId int, movementType db.StockMovementType, quantity int, reason string, reference *string, userId *int, unitCost *float64) []db.PrismaTransaction {
+	return ledgerMovement(dbClient, productId, variantId, warehouseId, movementType, quantity, reason, reference, userId, unitCost)
+}
+
+func ledgerMovement(dbClient *db.PrismaClient, productId int, variantId *int, warehouseId int, movementType db.StockMovementType, quantity int, reason string, reference *string, userId *int, unitCost *float64, movement ...db.StockMovementSetParam) []db.PrismaTransaction {
+	movement = append(movement, db.StockMovement.Reference.SetIfPresent(reference))
+	if userId != nil {
+		movement = append(movement, db.StockMovement.User.Link(db.User.ID.Equals(*userId)))
+	}
+	if variantId != nil {
+		movement = append(movement, db.StockMovement.Variant.Link(db.ProductVariant.ID.Equals(*variantId)))
+	}
+	transactions := []db.PrismaTransaction{
+		dbClient.StockMovement.CreateOne(
+			db.StockMovement.Product.Link(db.Product.ID.Equals(productId)),
+			db.StockMovement.Warehouse.Link(db.Warehouse.ID.Equals(warehouseId)),
+			db.StockMovement.Type.Set(movementType),
+			db.StockMovement.Quantity.Set(quantity),
+			db.StockMovement.Reason.Set(reason),
+			movement...,
+		).Tx(),
+	}
+	if quantity > 0 && (unitCost != nil || movementType == db.StockMovementTypeReceipt || movementType == db.StockMovementTypeAdjustment) {
+		transactions = append(transactions, movementCost(dbClient, productId, unitCost))
+	}
+	transactions = append(transactions, EnsureInventory(dbClient, productId, variantId, warehouseId))
+	transactions = append(transactions, dbClient.Inventory.FindMany(InventoryOf(productId, variantId, warehouseId)...).Update(
+		db.Inventory.Quantity.Increment(quantity),
+	).Tx())
+	transactions = append(transactions, SyncProductStock(dbClient, productId))
+	if variantId != nil {
+		transactions = append(transactions, SyncVariantStock(dbClient, *variantId))
+	}
+	return transactions
+}
+
+// movementCost returns the write that records the unit cost of the movement created just before it in the
+// transaction: unitCost, or else the product's standard cost. Without either nothing is recorded and the units
+// are valued at the current cost of the stock, or reported as uncosted while it has none.
+func movementCost(dbClient *db.PrismaClient, productId int, unitCost *float64) db.PrismaTransaction {
+	return dbClient.Prisma.ExecuteRaw(
+		`INSERT INTO "StockMovementCost" ("movementId", "unitCost")
+		SELECT currval(pg_get_serial_sequence('"StockMovement"', 'id')), COALESCE(CAST($2 AS DOUBLE PRECISION), "standardCost")
+		FROM "Product"
+		WHERE "id" = $1 AND COALESCE(CAST($2 AS DOUBLE PRECISION), "standardCost") IS NOT NULL`,
+		productId, unitCost,
+	).Tx()
+}
+
+// InventoryOf selects the inventory record of a product in a warehouse, the one of a variant when variantId is
+// set and the product's own otherwise.
+func InventoryOf(productId int, variantId *int, warehouseId int) []db.InventoryWhereParam {
+	return []db.InventoryWhereParam{
+		db.Inventory.ProductID.Equals(productId),
+		db.Inventory.VariantID.EqualsOptional(variantId),
+		db.Inventory.WarehouseID.Equals(warehouseId),
+	}
+}
+
+// EnsureInventory returns the write that creates the empty inventory record of a product or variant in a
+// warehouse unless it exists. Prisma cannot upsert on a unique key with a null part, so the record is created
+// first and then updated through InventoryOf.
+func EnsureInventory(dbClient *db.PrismaClient, productId int, variantId *int, warehouseId int) db.PrismaTransaction {
+	return dbClient.Prisma.ExecuteRaw(
+		`INSERT INTO "Inventory" ("productId", "variantId", "warehouseId", "updatedAt")
+		VALUES ($1, CAST($2 AS INTEGER), $3, CURRENT_TIMESTAMP)
+		ON CONFLICT DO NOTHING`,
+		productId, variantId, warehouseId,
+	).Tx()
+}
+
+// InventoryGuard returns the write that fails the transaction on the Inventory quantity check unless the
+// inventory of the product or variant in the warehouse still holds quantity, see StaleInventory. It goes after
+// EnsureInventory and before the writes that change the quantity, so they only apply to the quantity they were
+// computed from.
+func InventoryGuard(dbClient *db.PrismaClient, productId int, variantId *int, warehouseId int, quantity int) db.PrismaTransaction {
+	return dbClient.Prisma.ExecuteRaw(
+		`UPDATE "Inventory" SET "quantity" = CASE WHEN "quantity" = $4 THEN "quantity" ELSE -1 END
+		WHERE "productId" = $1 AND "variantId" IS NOT DISTINCT FROM CAST($2 AS INTEGER) AND "warehouseId" = $3`,
+		productId, variantId, warehouseId, quantity,
+	).Tx()
+}
+
+// SyncProductStock returns the write that recomputes Product.stock as the total held across all warehouses,
+// variants included. It belongs in the same transaction as the inventory change it follows.
+func SyncProductStock(dbClient *db.PrismaClient, productId int) db.PrismaTransaction {
+	return dbClient.Prisma.ExecuteRaw(
+		`UPDATE "Product" SET "stock" = (SELECT COALESCE(SUM("quantity"), 0) FROM "Inventory" WHERE "productId" = $1), "updatedAt" = CURRENT_TIMESTAMP WHERE "id" = $1`,
+		productId,
+	).Tx()
+}
+
+// SyncVariantStock is SyncProductStock for the total of one variant.
+func SyncVariantStock(dbClient *db.PrismaClient, variantId int) db.PrismaTransaction {
+	return dbClient.Prisma.ExecuteRaw(
+		`UPDATE "ProductVariant" SET "stock" = (SELECT COALESCE(SUM("quantity"), 0) FROM "Inventory" WHERE "variantId" = $1), "updatedAt" = CURRENT_TIMESTAMP WHERE "id" = $1`,
+		variantId,
+	).Tx()
+}
+
+// InsufficientStock reports whether a transaction failed because it would have taken inventory, a lot or a
+// variant below zero, or inventory below what is reserved.
+func InsufficientStock(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "Inventory_quantity_check") ||
+		strings.Contains(err.Error(), "Inventory_reserved_check") ||
+		strings.Contains(err.Error(), "InventoryLot_quantity_check") ||
+		strings.Contains(err.Error(), "ProductVariant_stock_check"))
+}
+
+// StaleInventory reports whether a transaction with an InventoryGuard failed because the quantity had changed.
+// Check it before InsufficientStock, which the guard's failure also matches.
+func StaleInventory(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "Inventory_quantity_check")
+}
+
+// Reserve returns the writes that hold quantity units of a product, or of one of its variants, in a warehouse.
+// The Inventory reserved check makes the transaction fail if the warehouse no longer has them available.
+func Reserve(dbClient *db.PrismaClient, productId int, variantId *int, warehouseId int, quantity int, orderId *int, reference *string, userId int, expiresAt *time.Time) []db.PrismaTransaction {
+	reservation := []db.StockReservationSetParam{
+		db.StockReservation.Reference.SetIfPresent(reference),
+		db.StockReservation.ExpiresAt.SetIfPresent(expiresAt),
+	}
+	if orderId != nil {
+		reservation = append(reservation, db.StockReservation.Order.Link(db.Order.ID.Equals(*orderId)))
+	}
+	if variantId != nil {
+		reservation = append(reservation, db.StockReservation.Variant.Link(db.ProductVariant.ID.Equals(*variantId)))
+	}
+	return []db.PrismaTransaction{
+		dbClient.StockReservation.CreateOne(
+			db.StockReservation.Product.Link(db.Product.ID.Equals(productId)),
+			db.StockReservation.Warehouse.Link(db.Warehouse.ID.Equals(warehouseId)),
+			db.StockReservation.Quantity.Set(quantity),
+			db.StockReservation.User.Link(db.User.ID.Equals(userId)),
+			reservation...,
+		).Tx(),
+		dbClient.Inventory.FindMany(InventoryOf(productId, variantId, warehouseId)...).Update(
+			db.Inventory.Reserved.Increment(quantity),
+		).Tx(),
+	}
+}
+
+// ReleaseReservation returns the write that ends an active reservation with the given status and gives its
+// quantity back. The status change and the inventory update are one statement, so releasing twice is a no-op.
+func ReleaseReservation(dbClient *db.PrismaClient, reservationId int, status db.ReservationStatus) db.PrismaTransaction {
+	return dbClient.Prisma.ExecuteRaw(
+		`WITH released AS (
+			UPDATE "StockReservation" SET "status" = $2::"ReservationStatus", "releasedAt" = CURRENT_TIMESTAMP
+			WHERE "id" = $1 AND "status" = 'ACTIVE'
+			RETURNING "productId", "variantId", "warehouseId", "quantity"
+		)
+		UPDATE "Inventory" i SET "reserved" = i."reserved" - r."quantity", "updatedAt" = CURRENT_TIMESTAMP
+		FROM released r
+		WHERE i."productId" = r."productId" AND i."variantId" IS NOT DISTINCT FROM r."variantId" AND i."warehouseId" = r."warehouseId"`,
+		reservationId, string(status),
+	).Tx()
+}
+
+// ExpireReservations releases every active reservation whose expiry has passed and returns how many inventory
+// records were freed.
+func ExpireReservations(ctx context.Context, dbClient *db.PrismaClient) (int, error) {
+	result, err := dbClient.Prisma.ExecuteRaw(
+		`WITH expired AS (
+			UPDATE "StockReservation" SET "status" = 'EXPIRED', "releasedAt" = CURRENT_TIMESTAMP
+			WHERE "status" = 'ACTIVE' AND "expiresAt" <= CURRENT_TIMESTAMP
+			RETURNING "productId", "variantId", "warehouseId", "quantity"
+		), totals AS (
+			SELECT "productId", "variantId", "warehouseId", SUM("quantity") AS "quantity" FROM expired GROUP BY "productId", "variantId", "warehouseId"
+		)
+		UPDATE "Inventory" i SET "reserved" = i."reserved" - t."quantity", "updatedAt" = CURRENT_TIMESTAMP
+		FROM totals t
+		WHERE i."productId" = t."productId" AND i."variantId" IS NOT DISTINCT FROM t."variantId" AND i."warehouseId" = t."warehouseId"`,
+	).Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return result.Count, nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInsufficientStock(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantShort bool
+		wantStale bool
+	}{
+		{name: "no error"},
+		{name: "inventory below zero", err: errors.New(`violates check constraint "Inventory_quantity_check"`), wantShort: true, wantStale: true},
+		{name: "inventory below reserved", err: errors.New(`violates check constraint "Inventory_reserved_check"`), wantShort: true},
+		{name: "lot below zero", err: errors.New(`violates check constraint "InventoryLot_quantity_check"`), wantShort: true},
+		{name: "variant below zero", err: errors.New(`violates check constraint "ProductVariant_stock_check"`), wantShort: true},
+		{name: "ledger changed", err: errors.New("StockMovement rows cannot be changed or removed")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := InsufficientStock(test.err); got != test.wantShort {
+				t.Errorf("InsufficientStock(%v) = %v, want %v", test.err, got, test.wantShort)
+			}
+			if got := StaleInventory(test.err); got != test.wantStale {
+				t.Errorf("StaleInventory(%v) = %v, want %v", test.err, got, test.wantStale)
+			}
+		})
+	}
+}
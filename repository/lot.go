@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"Enterprise/prisma/db"
+	"errors"
+	"golang.org/x/net/context"
+	"sort"
+	"time"
+)
+
+// ErrExpiredStock is returned when a warehouse only has the units asked for in lots that expired.
+var ErrExpiredStock = errors.New("the remaining stock is in expired lots")
+
+// Lot identifies the lot units are received into, the dates are only needed when the lot is first received.
+type Lot struct {
+	Number         string
+	ManufacturedAt *time.Time
+	ExpiresAt      *time.Time
+}
+
+// LotAllocation is the quantity taken from one lot.
+type LotAllocation struct {
+	LotId     int
+	LotNumber string
+	Quantity  int
+}
+
+// LotStockMovement is StockMovement for units of a product going into or out of a lot, which is created on
+// first receipt. Without a lot it is a plain StockMovement. Lots hold the product's own stock, variant stock is
+// not received into lots.
+func LotStockMovement(dbClient *db.PrismaClient, productId int, warehouseId int, lot *Lot, movementType db.StockMovementType, quantity int, reason string, reference *string, userId *int, unitCost *float64) []db.PrismaTransaction {
+	if lot == nil {
+		return StockMovement(dbClient, productId, nil, warehouseId, movementType, quantity, reason, reference, userId, unitCost)
+	}
+	lotUnique := db.InventoryLot.ProductIDWarehouseIDLotNumber(
+		db.InventoryLot.ProductID.Equals(productId),
+		db.InventoryLot.WarehouseID.Equals(warehouseId),
+		db.InventoryLot.LotNumber.Equals(lot.Number),
+	)
+	transactions := []db.PrismaTransaction{
+		dbClient.InventoryLot.UpsertOne(lotUnique).Create(
+			db.InventoryLot.Product.Link(db.Product.ID.Equals(productId)),
+			db.InventoryLot.Warehouse.Link(db.Warehouse.ID.Equals(warehouseId)),
+			db.InventoryLot.LotNumber.Set(lot.Number),
+			db.InventoryLot.Quantity.Set(quantity),
+			db.InventoryLot.ManufacturedAt.SetIfPresent(lot.ManufacturedAt),
+			db.InventoryLot.ExpiresAt.SetIfPresent(lot.ExpiresAt),
+		).Update(
+			db.InventoryLot.Quantity.Increment(quantity),
+			db.InventoryLot.ManufacturedAt.SetIfPresent(lot.ManufacturedAt),
+			db.InventoryLot.ExpiresAt.SetIfPresent(lot.ExpiresAt),
+		).Tx(),
+	}
+	return append(transactions, ledgerMovement(dbClient, productId, nil, warehouseId, movementType, quantity, reason, reference, userId, unitCost,
+		db.StockMovement.Lot.Link(lotUnique),
+	)...)
+}
+
+// Outbound returns the writes that take quantity units of a product out of a warehouse, first-expired-first-out:
+// the lots that expire soonest first, then lots without an expiry date, then stock that was not received into a
+// lot. Expired lots are only taken when includeExpired is set, e.g. to write them off. The lots taken are
+// returned so orders can record them for recalls. Variant stock has no lots and is taken as it is.
+func Outbound(ctx context.Context, dbClient *db.PrismaClient, productId int, variantId *int, warehouseId int, movementType db.StockMovementType, quantity int, reason string, reference *string, userId *int, includeExpired bool) ([]db.PrismaTransaction, []LotAllocation, error) {
+	if variantId != nil {
+		return StockMovement(dbClient, productId, variantId, warehouseId, movementType, -quantity, reason, reference, userId, nil), nil, nil
+	}
+	lots, err := dbClient.InventoryLot.FindMany(
+		db.InventoryLot.ProductID.Equals(productId),
+		db.InventoryLot.WarehouseID.Equals(warehouseId),
+		db.InventoryLot.Quantity.Gt(0),
+	).Exec(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	onHand := 0
+	inventory, err := dbClient.Inventory.FindFirst(InventoryOf(productId, nil, warehouseId)...).Exec(ctx)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, nil, err
+	}
+	if inventory != nil {
+		onHand = inventory.Quantity
+	}
+
+	sort.SliceStable(lots, func(i, j int) bool {
+		left, leftOk := lots[i].ExpiresAt()
+		right, rightOk := lots[j].ExpiresAt()
+		if leftOk != rightOk {
+			return leftOk
+		}
+		if leftOk && !left.Equal(right) {
+			return left.Before(right)
+		}
+		return lots[i].ID < lots[j].ID
+	})
+
+	var transactions []db.PrismaTransaction
+	var allocations []LotAllocation
+	remaining, unlotted, expired := quantity, onHand, 0
+	now := time.Now()
+	for _, lot := range lots {
+		unlotted -= lot.Quantity
+		if expiresAt, ok := lot.ExpiresAt(); ok && !expiresAt.After(now) && !includeExpired {
+			expired += lot.Quantity
+			continue
+		}
+		take := min(lot.Quantity, remaining)
+		if take == 0 {
+			continue
+		}
+		transactions = append(transactions, LotStockMovement(dbClient, productId, warehouseId, &Lot{Number: lot.LotNumber}, movementType, -take, reason, reference, userId, nil)...)
+		allocations = append(allocations, LotAllocation{LotId: lot.ID, LotNumber: lot.LotNumber, Quantity: take})
+		remaining -= take
+	}
+	if remaining > 0 && remaining > unlotted && expired > 0 {
+		return nil, nil, ErrExpiredStock
+	}
+	// Whatever the lots did not cover comes from unlotted stock, the Inventory quantity check refuses it if
+	// the warehouse does not have it.
+	if remaining > 0 {
+		transactions = append(transactions, StockMovement(dbClient, productId, nil, warehouseId, movementType, -remaining, reason, reference, userId, nil)...)
+	}
+	return transactions, allocations, nil
+}
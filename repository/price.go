@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"Enterprise/prisma/db"
+	"time"
+)
+
+// AppliedPriceChange returns the history entry for a price change that takes effect immediately.
+func AppliedPriceChange(dbClient *db.PrismaClient, productId int, oldPrice *float64, newPrice float64, reason string, userId int) db.PrismaTransaction {
+	now := time.Now()
+	return dbClient.PriceChange.CreateOne(
+		db.PriceChange.Product.Link(db.Product.ID.Equals(productId)),
+		db.PriceChange.NewPrice.Set(newPrice),
+		db.PriceChange.Reason.Set(reason),
+		db.PriceChange.EffectiveAt.Set(now),
+		db.PriceChange.User.Link(db.User.ID.Equals(userId)),
+		db.PriceChange.OldPrice.SetIfPresent(oldPrice),
+		db.PriceChange.AppliedAt.Set(now),
+	).Tx()
+}
+
+// GuardedPriceChange returns the write that sets a product's price and records the change, both only while the
+// product still has the given updatedAt. updatedAt itself is left alone, so an update of the product guarded by
+// the same value can follow in the transaction, and when that update matches no row neither does this one.
+func GuardedPriceChange(dbClient *db.PrismaClient, productId int, updatedAt time.Time, oldPrice float64, newPrice float64, reason string, userId int) db.PrismaTransaction {
+	return dbClient.Prisma.ExecuteRaw(
+		`WITH updated AS (
+			UPDATE "Product" SET "price" = $3
+			WHERE "id" = $1 AND "updatedAt" = CAST($2 AS TIMESTAMP(3))
+			RETURNING "id"
+		)
+		INSERT INTO "PriceChange" ("productId", "oldPrice", "newPrice", "reason", "effectiveAt", "appliedAt", "userId")
+		SELECT "id", $4, $3, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $6 FROM updated`,
+		productId, updatedAt.UTC().Format("2006-01-02T15:04:05.000"), newPrice, oldPrice, reason, userId,
+	).Tx()
+}
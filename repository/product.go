@@ -3,9 +3,25 @@ package repository
 import (
 	"Enterprise/prisma/db"
 	"golang.org/x/net/context"
+	"time"
 )
 
 func ExistingProductByName(ctx context.Context, dbClient *db.PrismaClient, name string) bool {
-	existingProduct, _ := dbClient.Product.FindFirst(db.Product.Name.Equals(name)).Exec(ctx)
+	existingProduct, _ := dbClient.Product.FindFirst(db.Product.Name.Equals(name), db.Product.DeletedAt.IsNull()).Exec(ctx)
 	return existingProduct != nil
 }
+
+// TouchProduct bumps the product's updatedAt, and so its ETag, after a change to one of its child records.
+func TouchProduct(ctx context.Context, dbClient *db.PrismaClient, productId int) {
+	_, _ = dbClient.Product.FindMany(db.Product.ID.Equals(productId)).Update(
+		db.Product.UpdatedAt.Set(time.Now()),
+	).Exec(ctx)
+}
+
+func ProductIsBundleComponent(ctx context.Context, dbClient *db.PrismaClient, productId int) bool {
+	component, _ := dbClient.BundleComponent.FindFirst(
+		db.BundleComponent.ComponentID.Equals(productId),
+		db.BundleComponent.Bundle.Where(db.Product.DeletedAt.IsNull()),
+	).Exec(ctx)
+	return component != nil
+}
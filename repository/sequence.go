@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"Enterprise/prisma/db"
+	"errors"
+	"golang.org/x/net/context"
+)
+
+// NextId takes the next id of a table from its sequence, so a record and the records that refer to it can be
+// written in one transaction.
+func NextId(ctx context.Context, dbClient *db.PrismaClient, table string) (int, error) {
+	var rows []struct {
+		Id int `json:"id"`
+	}
+	err := dbClient.Prisma.QueryRaw(`SELECT CAST(nextval(pg_get_serial_sequence($1, 'id')) AS INTEGER) AS "id"`, `"`+table+`"`).Exec(ctx, &rows)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, errors.New("no id was returned for " + table)
+	}
+	return rows[0].Id, nil
+}
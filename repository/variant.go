@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"Enterprise/prisma/db"
+	"golang.org/x/net/context"
+)
+
+func ExistingVariantBySku(ctx context.Context, dbClient *db.PrismaClient, sku string) bool {
+	existingVariant, _ := dbClient.ProductVariant.FindUnique(db.ProductVariant.Sku.Equals(sku)).Exec(ctx)
+	return existingVariant != nil
+}
+
+func ProductHasVariants(ctx context.Context, dbClient *db.PrismaClient, productId int) bool {
+	variant, _ := dbClient.ProductVariant.FindFirst(db.ProductVariant.ProductID.Equals(productId)).Exec(ctx)
+	return variant != nil
+}
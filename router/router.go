@@ -10,6 +10,26 @@ func NewRouter(
 	userController *controller.UserController,
 	categoryController *controller.CategoryController,
 	productController *controller.ProductController,
+	variantController *controller.VariantController,
+	attachmentController *controller.AttachmentController,
+	priceController *controller.PriceController,
+	attributeController *controller.AttributeController,
+	bundleController *controller.BundleController,
+	labelController *controller.LabelController,
+	priceListController *controller.PriceListController,
+	warehouseController *controller.WarehouseController,
+	stockMovementController *controller.StockMovementController,
+	transferController *controller.TransferController,
+	reservationController *controller.ReservationController,
+	reorderController *controller.ReorderController,
+	notificationController *controller.NotificationController,
+	supplierController *controller.SupplierController,
+	purchaseOrderController *controller.PurchaseOrderController,
+	stockTakeController *controller.StockTakeController,
+	lotController *controller.LotController,
+	valuationController *controller.ValuationController,
+	orderController *controller.OrderController,
+	cacheController *controller.CacheController,
 ) *httprouter.Router {
 	router := httprouter.New()
 
@@ -19,30 +39,171 @@ func NewRouter(
 
 	router.POST("/api/admin/users/roles", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, userController.RoleCreation))
 	router.POST("/api/admin/users/create", middleware.RoleBasedAuthMiddleware(allowedRoles, userController.CreateUserByAdmin))
-	router.PUT("/api/admin/users/update-info/:userId", middleware.RoleBasedAuthMiddleware(allowedRoles, userController.UpdateUserInfo))
-	router.PUT("/api/admin/users/deactivate/:userId", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, userController.DeactivateUser))
-	router.PUT("/api/admin/users/delete/:userId", middleware.RoleBasedAuthMiddleware(allowedRoles, userController.DeleteUser))
+	router.PUT("/api/admin/users/update-info/:userId", middleware.RoleBasedAuthMiddleware(allowedRoles, middleware.IfMatchMiddleware(userController.UpdateUserInfo)))
+	router.PUT("/api/admin/users/deactivate/:userId", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, middleware.IfMatchMiddleware(userController.DeactivateUser)))
+	router.PUT("/api/admin/users/delete/:userId", middleware.RoleBasedAuthMiddleware(allowedRoles, middleware.IfMatchMiddleware(userController.DeleteUser)))
 	router.POST("/api/users/password", userController.CreateUserPassword)
 	router.POST("/api/users/login", userController.Login)
-	router.PUT("/api/users/change-info", middleware.RoleBasedAuthMiddleware(allowedRoles, userController.ChangeUserInfo))
+	router.PUT("/api/users/change-info", middleware.RoleBasedAuthMiddleware(allowedRoles, middleware.IfMatchMiddleware(userController.ChangeUserInfo)))
 	router.GET("/api/admin/users", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, userController.GetAllUsers))
+	router.GET("/api/admin/users/:userId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, userController.GetUserById))
+	router.GET("/api/users/me", middleware.RoleBasedAuthMiddleware(allowedRoles, userController.GetCurrentUser))
 	// AuditLogs
 	router.GET("/api/admin/logs", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, categoryController.AuditLogs))
 
+	// Stock ledger
+	router.GET("/api/admin/stock-reconciliation", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, stockMovementController.ReconcileStock))
+	router.POST("/api/admin/stock-reconciliation", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, stockMovementController.ReconcileStock))
+
+	// Cache
+	router.GET("/api/admin/cache-stats", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, cacheController.CacheStats))
+
+	// Trash
+	router.GET("/api/admin/product-trash", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, productController.GetDeletedProducts))
+	router.PUT("/api/admin/product/restore/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, productController.RestoreProduct))
+	router.GET("/api/admin/category-trash", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, categoryController.GetDeletedCategories))
+	router.PUT("/api/admin/category/restore/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, categoryController.RestoreCategory))
+
 	// Categories
 	router.POST("/api/category/create", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, categoryController.CreateCategory))
 	router.GET("/api/category/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, categoryController.GetCategoryById))
 	router.GET("/api/category", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, categoryController.GetAllCategories))
-	router.PUT("/api/category/update/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, categoryController.UpdateCategory))
-	router.DELETE("/api/category/delete/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, categoryController.DeleteCategory))
+	router.PUT("/api/category/update/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, middleware.IfMatchMiddleware(categoryController.UpdateCategory)))
+	router.DELETE("/api/category/delete/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, middleware.IfMatchMiddleware(categoryController.DeleteCategory)))
+	router.PUT("/api/category/move/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, middleware.IfMatchMiddleware(categoryController.MoveCategory)))
+	router.GET("/api/category-breadcrumb/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, categoryController.GetCategoryBreadcrumb))
+	router.GET("/api/category-products/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, categoryController.GetCategoryProducts))
+	router.PUT("/api/category-attributes/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, attributeController.SetCategoryAttributes))
+	router.GET("/api/category-attributes/:categoryId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, attributeController.GetCategoryAttributes))
 
 	// Product
 	router.POST("/api/product/create", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, productController.CreateProduct))
-	router.PUT("/api/product/update/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, productController.UpdateProduct))
+	router.PUT("/api/product/update/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, middleware.IfMatchMiddleware(productController.UpdateProduct)))
 	router.GET("/api/product/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, productController.GetProductById))
-	router.DELETE("/api/product/delete/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, productController.DeleteProductById))
+	router.DELETE("/api/product/delete/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, middleware.IfMatchMiddleware(productController.DeleteProductById)))
 	router.GET("/api/product", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, productController.GetAllProducts))
-	router.PUT("/api/product-stock/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, productController.UpdateProductStock))
+	router.PUT("/api/product-stock/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, middleware.IfMatchMiddleware(productController.UpdateProductStock)))
+	router.POST("/api/stock-movement/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, stockMovementController.RecordStockMovement))
+	router.GET("/api/stock-movement/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, stockMovementController.GetStockMovements))
+
+	// Product prices
+	router.GET("/api/product-price/history/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceController.GetPriceHistory))
+	router.GET("/api/product-price/effective/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceController.GetEffectivePrice))
+	router.POST("/api/product-price/schedule/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceController.SchedulePriceChange))
+	router.DELETE("/api/product-price/cancel/:priceChangeId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceController.CancelPriceChange))
+
+	router.GET("/api/product-price/resolve/:productId", middleware.RoleBasedAuthMiddleware(allowedRoles, priceListController.ResolvePrice))
+
+	// Price lists
+	router.POST("/api/price-list/create", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceListController.CreatePriceList))
+	router.PUT("/api/price-list/update/:priceListId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceListController.UpdatePriceList))
+	router.DELETE("/api/price-list/delete/:priceListId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceListController.DeletePriceList))
+	router.PUT("/api/price-list/items/:priceListId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceListController.SetPriceListItems))
+	router.GET("/api/price-list/:priceListId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceListController.GetPriceListById))
+	router.GET("/api/price-list", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceListController.GetPriceLists))
+	router.POST("/api/exchange-rate/create", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceListController.CreateExchangeRate))
+	router.GET("/api/exchange-rate", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, priceListController.GetExchangeRates))
+
+	// Warehouses
+	router.POST("/api/warehouse/create", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, warehouseController.CreateWarehouse))
+	router.PUT("/api/warehouse/update/:warehouseId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, warehouseController.UpdateWarehouse))
+	router.DELETE("/api/warehouse/delete/:warehouseId", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, warehouseController.DeleteWarehouse))
+	router.GET("/api/warehouse/:warehouseId", middleware.RoleBasedAuthMiddleware(allowedRoles, warehouseController.GetWarehouseById))
+	router.GET("/api/warehouse", middleware.RoleBasedAuthMiddleware(allowedRoles, warehouseController.GetAllWarehouses))
+	router.GET("/api/warehouse-stock/:warehouseId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, warehouseController.GetWarehouseStock))
+
+	// Stock transfers
+	router.POST("/api/stock-transfer/create", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, transferController.CreateTransfer))
+	router.PUT("/api/stock-transfer/items/:transferId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, transferController.UpdateTransferItems))
+	router.PUT("/api/stock-transfer/dispatch/:transferId", middleware.RoleBasedAuthMiddleware(allowedRoles, transferController.DispatchTransfer))
+	router.PUT("/api/stock-transfer/receive/:transferId", middleware.RoleBasedAuthMiddleware(allowedRoles, transferController.ReceiveTransfer))
+	router.PUT("/api/stock-transfer/cancel/:transferId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, transferController.CancelTransfer))
+	router.GET("/api/stock-transfer/:transferId", middleware.RoleBasedAuthMiddleware(allowedRoles, transferController.GetTransferById))
+	router.GET("/api/stock-transfer", middleware.RoleBasedAuthMiddleware(allowedRoles, transferController.GetTransfers))
+
+	// Stock reservations
+	router.POST("/api/stock-reservation/create/:productId", middleware.RoleBasedAuthMiddleware(allowedRoles, reservationController.CreateReservation))
+	router.DELETE("/api/stock-reservation/release/:reservationId", middleware.RoleBasedAuthMiddleware(allowedRoles, reservationController.ReleaseReservation))
+	router.GET("/api/stock-reservation/:productId", middleware.RoleBasedAuthMiddleware(allowedRoles, reservationController.GetProductReservations))
+
+	// Reorder points and low stock
+	router.PUT("/api/inventory-reorder/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, reorderController.SetReorderPoint))
+	router.GET("/api/inventory-low-stock", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, reorderController.GetLowStock))
+	router.GET("/api/inventory-purchase-suggestions", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, reorderController.GetPurchaseSuggestions))
+
+	// Notifications
+	router.GET("/api/notification", middleware.RoleBasedAuthMiddleware(allowedRoles, notificationController.GetNotifications))
+	router.PUT("/api/notification/read/:notificationId", middleware.RoleBasedAuthMiddleware(allowedRoles, notificationController.MarkNotificationRead))
+	router.PUT("/api/notification/read-all", middleware.RoleBasedAuthMiddleware(allowedRoles, notificationController.MarkAllNotificationsRead))
+
+	// Suppliers
+	router.POST("/api/supplier/create", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, supplierController.CreateSupplier))
+	router.PUT("/api/supplier/update/:supplierId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, supplierController.UpdateSupplier))
+	router.DELETE("/api/supplier/delete/:supplierId", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, supplierController.DeleteSupplier))
+	router.GET("/api/supplier/:supplierId", middleware.RoleBasedAuthMiddleware(allowedRoles, supplierController.GetSupplierById))
+	router.GET("/api/supplier", middleware.RoleBasedAuthMiddleware(allowedRoles, supplierController.GetAllSuppliers))
+
+	// Purchase orders
+	router.POST("/api/purchase-order/create", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, purchaseOrderController.CreatePurchaseOrder))
+	router.PUT("/api/purchase-order/update/:purchaseOrderId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, purchaseOrderController.UpdatePurchaseOrder))
+	router.PUT("/api/purchase-order/send/:purchaseOrderId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, purchaseOrderController.SendPurchaseOrder))
+	router.POST("/api/purchase-order/receive/:purchaseOrderId", middleware.RoleBasedAuthMiddleware(allowedRoles, purchaseOrderController.ReceiveGoods))
+	router.PUT("/api/purchase-order/close/:purchaseOrderId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, purchaseOrderController.ClosePurchaseOrder))
+	router.GET("/api/purchase-order/:purchaseOrderId", middleware.RoleBasedAuthMiddleware(allowedRoles, purchaseOrderController.GetPurchaseOrderById))
+	router.GET("/api/purchase-order", middleware.RoleBasedAuthMiddleware(allowedRoles, purchaseOrderController.GetPurchaseOrders))
+
+	// Stock takes, counters only get the blind sheet. Book quantities are for managers everywhere, so the
+	// employees who count cannot read them from the stock, ledger or lot routes either.
+	router.POST("/api/stock-take/create", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, stockTakeController.CreateStockTake))
+	router.POST("/api/stock-take/count/:stockTakeId", middleware.RoleBasedAuthMiddleware(allowedRoles, stockTakeController.SubmitCounts))
+	router.GET("/api/stock-take/sheet/:stockTakeId", middleware.RoleBasedAuthMiddleware(allowedRoles, stockTakeController.GetStockTakeSheet))
+	router.PUT("/api/stock-take/close/:stockTakeId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, stockTakeController.CloseCounting))
+	router.PUT("/api/stock-take/approve/:stockTakeId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, stockTakeController.ApproveStockTake))
+	router.PUT("/api/stock-take/cancel/:stockTakeId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, stockTakeController.CancelStockTake))
+	router.GET("/api/stock-take/detail/:stockTakeId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, stockTakeController.GetStockTakeById))
+	router.GET("/api/stock-take", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, stockTakeController.GetStockTakes))
+
+	// Lots and expiry
+	router.GET("/api/lot/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, lotController.GetProductLots))
+	router.GET("/api/lot-expiring", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, lotController.GetExpiringLots))
+	router.GET("/api/lot-recall/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, lotController.GetLotRecall))
+
+	// Inventory valuation
+	router.GET("/api/inventory-valuation", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, valuationController.GetInventoryValuation))
+	router.GET("/api/inventory-cogs", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, valuationController.GetCostOfGoodsSold))
+	router.GET("/api/company-setting/valuation-method", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, valuationController.GetValuationMethod))
+	router.PUT("/api/company-setting/valuation-method", middleware.RoleBasedAuthMiddleware(allowedRolesForAdmins, valuationController.SetValuationMethod))
+
+	// Orders
+	router.POST("/api/order/create", middleware.RoleBasedAuthMiddleware(allowedRoles, orderController.CreateOrder))
+	router.PUT("/api/order/status/:orderId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, orderController.UpdateOrderStatus))
+	router.PUT("/api/order/cancel/:orderId", middleware.RoleBasedAuthMiddleware(allowedRoles, orderController.CancelOrder))
+	router.GET("/api/order/history/:orderId", middleware.RoleBasedAuthMiddleware(allowedRoles, orderController.GetOrderHistory))
+	router.GET("/api/order/detail/:orderId", middleware.RoleBasedAuthMiddleware(allowedRoles, orderController.GetOrderById))
+	router.GET("/api/order/mine", middleware.RoleBasedAuthMiddleware(allowedRoles, orderController.GetMyOrders))
+	router.GET("/api/order", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, orderController.GetOrders))
+
+	// Product variants
+	router.PUT("/api/product-variant/options/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, variantController.SetProductOptions))
+	router.POST("/api/product-variant/create/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, variantController.CreateVariant))
+	router.PUT("/api/product-variant/update/:variantId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, variantController.UpdateVariant))
+	router.DELETE("/api/product-variant/delete/:variantId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, variantController.DeleteVariant))
+	router.GET("/api/product-variant/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, variantController.GetVariantMatrix))
+
+	// Product bundles
+	router.PUT("/api/product-bundle/components/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, bundleController.SetBundleComponents))
+	router.DELETE("/api/product-bundle/delete/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, bundleController.RemoveBundle))
+	router.GET("/api/product-bundle/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, bundleController.GetBundle))
+
+	// Product labels
+	router.GET("/api/product-label/barcode/:productId", middleware.RoleBasedAuthMiddleware(allowedRoles, labelController.ProductBarcode))
+	router.GET("/api/product-label/sku/:sku", middleware.RoleBasedAuthMiddleware(allowedRoles, labelController.VariantBarcode))
+	router.POST("/api/product-label/sheet", middleware.RoleBasedAuthMiddleware(allowedRoles, labelController.LabelSheet))
+
+	// Product attachments
+	router.POST("/api/product-attachment/upload/:productId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, attachmentController.UploadAttachment))
+	router.DELETE("/api/product-attachment/delete/:attachmentId", middleware.RoleBasedAuthMiddleware(allowedRolesForManagers, attachmentController.DeleteAttachment))
+	router.GET("/api/files/*key", attachmentController.DownloadFile)
 
 	return router
 }
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/config"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"Enterprise/storage"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"golang.org/x/net/context"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const thumbnailSize = 256
+
+var allowedImageTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+var allowedDocumentTypes = []string{
+	"application/pdf",
+	"text/plain",
+	"text/csv",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+type AttachmentService struct {
+	Db      *db.PrismaClient
+	Storage storage.Storage
+	Cache   *cache.Cache
+}
+
+func NewAttachmentService(db *db.PrismaClient, store storage.Storage, catalogCache *cache.Cache) *AttachmentService {
+	return &AttachmentService{Db: db, Storage: store, Cache: catalogCache}
+}
+
+// MaxAttachmentSize is the largest upload accepted for any attachment kind.
+func MaxAttachmentSize() int64 {
+	return max(maxImageSize(), maxDocumentSize())
+}
+
+func (p *AttachmentService) UploadAttachment(ctx context.Context, uploadDto *model.AttachmentUploadModel) *data.WebResponse {
+	validator := helpers.RequestValidators(uploadDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(uploadDto.ProductId), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+
+	mimeType := mimetype.Detect(uploadDto.Content)
+	kind, maxSize := attachmentKind(mimeType)
+	if kind == "" {
+		return &data.WebResponse{
+			Code:    http.StatusUnsupportedMediaType,
+			Message: fmt.Sprintf("File type %s is not allowed", mimeType.String()),
+			Data:    nil,
+		}
+	}
+	if int64(len(uploadDto.Content)) > maxSize {
+		return &data.WebResponse{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("File is larger than the %d byte limit", maxSize),
+			Data:    nil,
+		}
+	}
+
+	var thumbnail []byte
+	if kind == db.AttachmentKindImage {
+		var err error
+		thumbnail, err = helpers.GenerateThumbnail(uploadDto.Content, thumbnailSize)
+		if errors.Is(err, helpers.ErrImageTooLarge) {
+			return &data.WebResponse{
+				Code:    http.StatusRequestEntityTooLarge,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Image could not be read: " + err.Error(),
+				Data:    nil,
+			}
+		}
+	}
+
+	fileId := uuid.New().String()
+	storageKey := fmt.Sprintf("products/%d/%s%s", uploadDto.ProductId, fileId, mimeType.Extension())
+	err := p.Storage.Put(ctx, storageKey, bytes.NewReader(uploadDto.Content), int64(len(uploadDto.Content)), mimeType.String())
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	var thumbnailKey *string
+	if thumbnail != nil {
+		key := fmt.Sprintf("products/%d/thumbnails/%s.jpg", uploadDto.ProductId, fileId)
+		err = p.Storage.Put(ctx, key, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg")
+		if err != nil {
+			_ = p.Storage.Delete(ctx, storageKey)
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		thumbnailKey = &key
+	}
+
+	attachment, err := p.Db.ProductAttachment.CreateOne(
+		db.ProductAttachment.Product.Link(db.Product.ID.Equals(uploadDto.ProductId)),
+		db.ProductAttachment.Kind.Set(kind),
+		db.ProductAttachment.FileName.Set(uploadDto.FileName),
+		db.ProductAttachment.ContentType.Set(mimeType.String()),
+		db.ProductAttachment.Size.Set(len(uploadDto.Content)),
+		db.ProductAttachment.StorageKey.Set(storageKey),
+		db.ProductAttachment.User.Link(db.User.ID.Equals(uploadDto.UserId)),
+		db.ProductAttachment.ThumbnailKey.SetIfPresent(thumbnailKey),
+	).Exec(ctx)
+	if err != nil {
+		_ = p.Storage.Delete(ctx, storageKey)
+		if thumbnailKey != nil {
+			_ = p.Storage.Delete(ctx, *thumbnailKey)
+		}
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	repository.TouchProduct(ctx, p.Db, uploadDto.ProductId)
+	p.Cache.Invalidate(ctx, cache.Products)
+
+	err = repository.AuditLogs(ctx, p.Db, uploadDto.UserId, "Product Attachment Uploaded", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Product attachment uploaded",
+		Data:    attachmentResponses(ctx, p.Storage, []db.ProductAttachmentModel{*attachment}),
+	}
+}
+
+func (p *AttachmentService) DeleteAttachment(ctx context.Context, attachmentId int, userId int) *data.WebResponse {
+	attachment, _ := p.Db.ProductAttachment.FindUnique(db.ProductAttachment.ID.Equals(attachmentId)).Exec(ctx)
+	if attachment == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product attachment not found",
+			Data:    nil,
+		}
+	}
+
+	_, err := p.Db.ProductAttachment.FindUnique(db.ProductAttachment.ID.Equals(attachmentId)).Delete().Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	_ = p.Storage.Delete(ctx, attachment.StorageKey)
+	if thumbnailKey, ok := attachment.ThumbnailKey(); ok {
+		_ = p.Storage.Delete(ctx, thumbnailKey)
+	}
+
+	repository.TouchProduct(ctx, p.Db, attachment.ProductID)
+	p.Cache.Invalidate(ctx, cache.Products)
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Product Attachment Deleted", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Product attachment deleted",
+		Data:    nil,
+	}
+}
+
+// GetFile serves files for the local storage backend; S3 links point at the bucket directly.
+func (p *AttachmentService) GetFile(ctx context.Context, key string, expires string, signature string) (*model.FileDownload, *data.WebResponse) {
+	localStorage, ok := p.Storage.(*storage.LocalStorage)
+	if !ok || !localStorage.Verify(key, expires, signature) {
+		return nil, &data.WebResponse{
+			Code:    http.StatusForbidden,
+			Message: "Download link is invalid or has expired",
+			Data:    nil,
+		}
+	}
+
+	attachment, _ := p.Db.ProductAttachment.FindFirst(db.ProductAttachment.Or(
+		db.ProductAttachment.StorageKey.Equals(key),
+		db.ProductAttachment.ThumbnailKey.Equals(key),
+	)).Exec(ctx)
+	if attachment == nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "File not found",
+			Data:    nil,
+		}
+	}
+	body, err := p.Storage.Get(ctx, key)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrNotFound) {
+			code = http.StatusNotFound
+		}
+		return nil, &data.WebResponse{
+			Code:    code,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	contentType := attachment.ContentType
+	if key != attachment.StorageKey {
+		contentType = "image/jpeg"
+	}
+	return &model.FileDownload{
+		Body:        body,
+		ContentType: contentType,
+		FileName:    attachment.FileName,
+	}, nil
+}
+
+func attachmentKind(mimeType *mimetype.MIME) (db.AttachmentKind, int64) {
+	for _, allowed := range allowedImageTypes {
+		if mimeType.Is(allowed) {
+			return db.AttachmentKindImage, maxImageSize()
+		}
+	}
+	for _, allowed := range allowedDocumentTypes {
+		if mimeType.Is(allowed) {
+			return db.AttachmentKindDocument, maxDocumentSize()
+		}
+	}
+	return "", 0
+}
+
+func attachmentResponses(ctx context.Context, store storage.Storage, attachments []db.ProductAttachmentModel) []model.AttachmentResponse {
+	expiry := config.AttachmentURLExpiry()
+	expiresAt := time.Now().Add(expiry)
+	var AttachmentResponses []model.AttachmentResponse
+	for _, attachment := range attachments {
+		url, err := store.SignedURL(ctx, attachment.StorageKey, expiry)
+		if err != nil {
+			continue
+		}
+		var thumbnailUrl string
+		if thumbnailKey, ok := attachment.ThumbnailKey(); ok {
+			thumbnailUrl, _ = store.SignedURL(ctx, thumbnailKey, expiry)
+		}
+		AttachmentResponses = append(AttachmentResponses, model.AttachmentResponse{
+			Id:           attachment.ID,
+			Kind:         string(attachment.Kind),
+			FileName:     attachment.FileName,
+			ContentType:  attachment.ContentType,
+			Size:         attachment.Size,
+			Url:          url,
+			ThumbnailUrl: thumbnailUrl,
+			ExpiresAt:    expiresAt,
+			CreatedAt:    attachment.CreatedAt,
+		})
+	}
+	return AttachmentResponses
+}
+
+func maxImageSize() int64 {
+	return envBytes("UPLOAD_MAX_IMAGE_SIZE", 5<<20)
+}
+
+func maxDocumentSize() int64 {
+	return envBytes("UPLOAD_MAX_DOCUMENT_SIZE", 20<<20)
+}
+
+func envBytes(name string, fallback int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(name), 10, 64)
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/net/context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type AttributeService struct {
+	Db    *db.PrismaClient
+	Cache *cache.Cache
+}
+
+func NewAttributeService(db *db.PrismaClient, catalogCache *cache.Cache) *AttributeService {
+	return &AttributeService{Db: db, Cache: catalogCache}
+}
+
+// SetCategoryAttributes replaces the attribute schema of a category.
+// Products already in the category are not re-validated until they are next written.
+func (p *AttributeService) SetCategoryAttributes(ctx context.Context, attributesDto *model.CategoryAttributesModel) *data.WebResponse {
+	validator := helpers.RequestValidators(attributesDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+
+	categoryExist, _ := p.Db.Category.FindFirst(db.Category.ID.Equals(attributesDto.CategoryId), db.Category.DeletedAt.IsNull()).Exec(ctx)
+	if categoryExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Category Not Found",
+			Data:    nil,
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, attribute := range attributesDto.Attributes {
+		name := strings.ToLower(attribute.Name)
+		if seen[name] {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Attribute %s is listed more than once", attribute.Name),
+				Data:    nil,
+			}
+		}
+		seen[name] = true
+
+		if len(attribute.AllowedValues) > 0 && attribute.Type == string(db.AttributeTypeBoolean) {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Attribute %s is a boolean and cannot list allowed values", attribute.Name),
+				Data:    nil,
+			}
+		}
+		if attribute.Type == string(db.AttributeTypeNumber) {
+			for _, value := range attribute.AllowedValues {
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return &data.WebResponse{
+						Code:    http.StatusBadRequest,
+						Message: fmt.Sprintf("Allowed value %s of attribute %s is not a number", value, attribute.Name),
+						Data:    nil,
+					}
+				}
+			}
+		}
+	}
+
+	transactions := []db.PrismaTransaction{
+		p.Db.CategoryAttribute.FindMany(db.CategoryAttribute.CategoryID.Equals(attributesDto.CategoryId)).Delete().Tx(),
+	}
+	for _, attribute := range attributesDto.Attributes {
+		allowedValues := attribute.AllowedValues
+		if attribute.Type == string(db.AttributeTypeNumber) {
+			for i, value := range allowedValues {
+				number, _ := strconv.ParseFloat(value, 64)
+				allowedValues[i] = strconv.FormatFloat(number, 'f', -1, 64)
+			}
+		}
+		transactions = append(transactions, p.Db.CategoryAttribute.CreateOne(
+			db.CategoryAttribute.Category.Link(db.Category.ID.Equals(attributesDto.CategoryId)),
+			db.CategoryAttribute.Name.Set(strings.ToLower(attribute.Name)),
+			db.CategoryAttribute.Type.Set(db.AttributeType(attribute.Type)),
+			db.CategoryAttribute.Required.Set(attribute.Required),
+			db.CategoryAttribute.AllowedValues.Set(allowedValues),
+		).Tx())
+	}
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	p.Cache.Invalidate(ctx, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, attributesDto.UserId, "Category Attributes Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Category attributes updated",
+		Data:    nil,
+	}
+}
+
+func (p *AttributeService) GetCategoryAttributes(ctx context.Context, categoryId int) *data.WebResponse {
+	categoryExist, _ := p.Db.Category.FindFirst(db.Category.ID.Equals(categoryId), db.Category.DeletedAt.IsNull()).With(
+		db.Category.Attributes.Fetch().OrderBy(db.CategoryAttribute.Name.Order(db.SortOrderAsc)),
+	).Exec(ctx)
+	if categoryExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Category Not Found",
+			Data:    nil,
+		}
+	}
+
+	var AttributeResponses []model.CategoryAttributeResponse
+	for _, attribute := range categoryExist.Attributes() {
+		AttributeResponses = append(AttributeResponses, model.CategoryAttributeResponse{
+			Name:          attribute.Name,
+			Type:          string(attribute.Type),
+			Required:      attribute.Required,
+			AllowedValues: attribute.AllowedValues,
+		})
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Category attributes",
+		Data:    AttributeResponses,
+	}
+}
+
+// validateProductAttributes checks product attribute values against the union of the schemas of the given
+// categories. Unknown attributes are rejected and an attribute is required if any category requires it.
+func validateProductAttributes(ctx context.Context, dbClient *db.PrismaClient, categoryIds []int, attributes map[string]interface{}) (map[string]interface{}, error) {
+	definitions, err := dbClient.CategoryAttribute.FindMany(db.CategoryAttribute.CategoryID.In(categoryIds)).Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string][]db.CategoryAttributeModel{}
+	for _, definition := range definitions {
+		byName[definition.Name] = append(byName[definition.Name], definition)
+	}
+
+	values := make(map[string]interface{}, len(attributes))
+	for name, value := range attributes {
+		name = strings.ToLower(name)
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("attribute %s is not defined for the product's categories", name)
+		}
+		values[name] = value
+	}
+
+	normalised := make(map[string]interface{}, len(values))
+	for name, definitions := range byName {
+		value, ok := values[name]
+		if !ok || value == nil {
+			for _, definition := range definitions {
+				if definition.Required {
+					return nil, fmt.Errorf("attribute %s is required", name)
+				}
+			}
+			continue
+		}
+		for _, definition := range definitions {
+			if err = checkAttributeValue(definition, value); err != nil {
+				return nil, err
+			}
+		}
+		normalised[name] = value
+	}
+	return normalised, nil
+}
+
+func checkAttributeValue(definition db.CategoryAttributeModel, value interface{}) error {
+	var text string
+	switch definition.Type {
+	case db.AttributeTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("attribute %s must be a number", definition.Name)
+		}
+		text = strconv.FormatFloat(number, 'f', -1, 64)
+	case db.AttributeTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("attribute %s must be true or false", definition.Name)
+		}
+		return nil
+	default:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("attribute %s must be a string", definition.Name)
+		}
+		text = str
+	}
+
+	if len(definition.AllowedValues) == 0 {
+		return nil
+	}
+	for _, allowed := range definition.AllowedValues {
+		if allowed == text {
+			return nil
+		}
+	}
+	return fmt.Errorf("attribute %s must be one of %s", definition.Name, strings.Join(definition.AllowedValues, ", "))
+}
+
+// attributeFilter turns query string filters into a JSON document for a containment (@>) match,
+// converting each value to the type its attribute is declared with.
+func attributeFilter(ctx context.Context, dbClient *db.PrismaClient, filters map[string]string) ([]byte, error) {
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, strings.ToLower(name))
+	}
+	definitions, err := dbClient.CategoryAttribute.FindMany(db.CategoryAttribute.Name.In(names)).Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	types := map[string]db.AttributeType{}
+	for _, definition := range definitions {
+		types[definition.Name] = definition.Type
+	}
+
+	document := make(map[string]interface{}, len(filters))
+	for name, value := range filters {
+		name = strings.ToLower(name)
+		switch types[name] {
+		case db.AttributeTypeNumber:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s must be a number", name)
+			}
+			document[name] = number
+		case db.AttributeTypeBoolean:
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s must be true or false", name)
+			}
+			document[name] = flag
+		default:
+			document[name] = value
+		}
+	}
+	return json.Marshal(document)
+}
+
+func decodeProductAttributes(raw db.JSON) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	_ = json.Unmarshal(raw, &attributes)
+	return attributes
+}
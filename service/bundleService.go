@@ -0,0 +1,353 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"fmt"
+	"golang.org/x/net/context"
+	"math"
+	"net/http"
+	"sort"
+)
+
+type BundleService struct {
+	Db    *db.PrismaClient
+	Cache *cache.Cache
+}
+
+func NewBundleService(db *db.PrismaClient, catalogCache *cache.Cache) *BundleService {
+	return &BundleService{Db: db, Cache: catalogCache}
+}
+
+// SetBundleComponents turns a product into a bundle of the given components, replacing any previous list.
+func (p *BundleService) SetBundleComponents(ctx context.Context, bundleDto *model.BundleModel) *data.WebResponse {
+	validator := helpers.RequestValidators(bundleDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(bundleDto.ProductId), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+	if repository.ProductHasVariants(ctx, p.Db, bundleDto.ProductId) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "A product with variants cannot be a bundle",
+			Data:    nil,
+		}
+	}
+	if repository.ProductIsBundleComponent(ctx, p.Db, bundleDto.ProductId) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Product is a component of another bundle, bundles cannot be nested",
+			Data:    nil,
+		}
+	}
+
+	seen := map[int]bool{}
+	var componentIds []int
+	for _, component := range bundleDto.Components {
+		if component.ProductId == bundleDto.ProductId {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: "A bundle cannot contain itself",
+				Data:    nil,
+			}
+		}
+		if seen[component.ProductId] {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Component %d is listed more than once", component.ProductId),
+				Data:    nil,
+			}
+		}
+		seen[component.ProductId] = true
+		componentIds = append(componentIds, component.ProductId)
+	}
+
+	components, err := p.Db.Product.FindMany(db.Product.ID.In(componentIds), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if len(components) != len(componentIds) {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Component product not found",
+			Data:    nil,
+		}
+	}
+	for _, component := range components {
+		if component.Type == db.ProductTypeBundle {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("%s is a bundle, bundles cannot be nested", component.Name),
+				Data:    nil,
+			}
+		}
+		// Bundles draw product stock from their components, which a product with variants does not keep.
+		if repository.ProductHasVariants(ctx, p.Db, component.ID) {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("%s has variants, a bundle cannot contain a product with variants", component.Name),
+				Data:    nil,
+			}
+		}
+	}
+
+	transactions := []db.PrismaTransaction{
+		p.Db.BundleComponent.FindMany(db.BundleComponent.BundleID.Equals(bundleDto.ProductId)).Delete().Tx(),
+	}
+	for _, component := range bundleDto.Components {
+		transactions = append(transactions, p.Db.BundleComponent.CreateOne(
+			db.BundleComponent.Bundle.Link(db.Product.ID.Equals(bundleDto.ProductId)),
+			db.BundleComponent.Component.Link(db.Product.ID.Equals(component.ProductId)),
+			db.BundleComponent.Quantity.Set(component.Quantity),
+		).Tx())
+	}
+	transactions = append(transactions, p.Db.Product.FindUnique(db.Product.ID.Equals(bundleDto.ProductId)).Update(
+		db.Product.Type.Set(db.ProductTypeBundle),
+		db.Product.BundleDiscount.SetOptional(bundleDto.Discount),
+	).Tx())
+	err = p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, bundleDto.UserId, "Product Bundle Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Product bundle updated",
+		Data:    nil,
+	}
+}
+
+// RemoveBundle turns a bundle back into a standard product. Its own price and stock are used again.
+func (p *BundleService) RemoveBundle(ctx context.Context, productId int, userId int) *data.WebResponse {
+	productExist, _ := p.Db.Product.FindFirst(
+		db.Product.ID.Equals(productId),
+		db.Product.DeletedAt.IsNull(),
+		db.Product.Type.Equals(db.ProductTypeBundle),
+	).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product bundle not found",
+			Data:    nil,
+		}
+	}
+
+	err := p.Db.Prisma.Transaction(
+		p.Db.BundleComponent.FindMany(db.BundleComponent.BundleID.Equals(productId)).Delete().Tx(),
+		p.Db.Product.FindUnique(db.Product.ID.Equals(productId)).Update(
+			db.Product.Type.Set(db.ProductTypeStandard),
+			db.Product.BundleDiscount.SetOptional(nil),
+		).Tx(),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Product Bundle Removed", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Product bundle removed",
+		Data:    nil,
+	}
+}
+
+func (p *BundleService) GetBundle(ctx context.Context, productId int) *data.WebResponse {
+	bundle, _ := p.Db.Product.FindFirst(
+		db.Product.ID.Equals(productId),
+		db.Product.DeletedAt.IsNull(),
+		db.Product.Type.Equals(db.ProductTypeBundle),
+	).With(
+		db.Product.BundleComponents.Fetch().With(db.BundleComponent.Component.Fetch()),
+	).Exec(ctx)
+	if bundle == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product bundle not found",
+			Data:    nil,
+		}
+	}
+
+	components := bundle.BundleComponents()
+	response := model.BundleResponse{
+		ProductId:      bundle.ID,
+		Name:           bundle.Name,
+		Price:          float32(productPrice(*bundle, components)),
+		ComponentsCost: float32(componentsCost(components)),
+		AvailableStock: productStock(*bundle, components),
+	}
+	if discount, ok := bundle.BundleDiscount(); ok {
+		response.Discount = &discount
+	}
+	for _, component := range components {
+		response.Components = append(response.Components, model.BundleComponentResponse{
+			ProductId: component.ComponentID,
+			Name:      component.Component().Name,
+			Quantity:  component.Quantity,
+			Price:     float32(component.Component().Price),
+			Stock:     component.Component().Stock,
+		})
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Product bundle",
+		Data:    response,
+	}
+}
+
+func componentsCost(components []db.BundleComponentModel) float64 {
+	var cost float64
+	for _, component := range components {
+		cost += component.Component().Price * float64(component.Quantity)
+	}
+	return cost
+}
+
+// productPrice is the price a product sells at: its own, or for a discounted bundle the discounted sum of its components.
+// components must have been fetched with their Component relation.
+func productPrice(product db.ProductModel, components []db.BundleComponentModel) float64 {
+	discount, ok := product.BundleDiscount()
+	if product.Type != db.ProductTypeBundle || !ok {
+		return product.Price
+	}
+	return math.Round(componentsCost(components)*(100-discount)) / 100
+}
+
+// productStock is a product's own stock, or for a bundle the number of complete kits its components allow.
+func productStock(product db.ProductModel, components []db.BundleComponentModel) int {
+	if product.Type != db.ProductTypeBundle {
+		return product.Stock
+	}
+	if len(components) == 0 {
+		return 0
+	}
+	available := math.MaxInt
+	for _, component := range components {
+		if kits := component.Component().Stock / component.Quantity; kits < available {
+			available = kits
+		}
+	}
+	return available
+}
+
+// productAvailable is the stock that can still be promised, on hand minus reserved, for a bundle in complete kits.
+// The product and the components must have been fetched with their Inventory relation.
+func productAvailable(product db.ProductModel, components []db.BundleComponentModel) int {
+	if product.Type != db.ProductTypeBundle {
+		return inventoryAvailable(product.Inventory())
+	}
+	if len(components) == 0 {
+		return 0
+	}
+	available := math.MaxInt
+	for _, component := range components {
+		if kits := inventoryAvailable(component.Component().Inventory()) / component.Quantity; kits < available {
+			available = kits
+		}
+	}
+	return available
+}
+
+// stockLine is a product's stock, or one of its variants' stock when variantId is set.
+type stockLine struct {
+	productId int
+	variantId int
+}
+
+func newStockLine(productId int, variantId *int) stockLine {
+	line := stockLine{productId: productId}
+	if variantId != nil {
+		line.variantId = *variantId
+	}
+	return line
+}
+
+// label names the line in messages.
+func (line stockLine) label() string {
+	if line.variantId == 0 {
+		return fmt.Sprintf("Product %d", line.productId)
+	}
+	return fmt.Sprintf("Variant %d of product %d", line.variantId, line.productId)
+}
+
+func (line stockLine) variant() *int {
+	if line.variantId == 0 {
+		return nil
+	}
+	return &line.variantId
+}
+
+// sortedStockLines lists the lines of demand by product and variant, so concurrent requests write inventory in
+// the same order.
+func sortedStockLines(demand map[stockLine]int) []stockLine {
+	lines := make([]stockLine, 0, len(demand))
+	for line := range demand {
+		lines = append(lines, line)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].productId != lines[j].productId {
+			return lines[i].productId < lines[j].productId
+		}
+		return lines[i].variantId < lines[j].variantId
+	})
+	return lines
+}
+
+// stockDemand adds the units that selling quantity of a product, or of one of its variants, takes out of stock
+// to demand. Selling a bundle takes its components out instead, the bundle itself holds no stock.
+func stockDemand(demand map[stockLine]int, product db.ProductModel, components []db.BundleComponentModel, variantId *int, quantity int) {
+	if product.Type != db.ProductTypeBundle {
+		demand[newStockLine(product.ID, variantId)] += quantity
+		return
+	}
+	for _, component := range components {
+		demand[stockLine{productId: component.ComponentID}] += component.Quantity * quantity
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"Enterprise/prisma/db"
+	"testing"
+)
+
+func TestProductStock(t *testing.T) {
+	component := func(quantity int, stock int) db.BundleComponentModel {
+		componentProduct := db.ProductModel{InnerProduct: db.InnerProduct{Type: db.ProductTypeStandard, Stock: stock}}
+		return db.BundleComponentModel{
+			InnerBundleComponent:     db.InnerBundleComponent{Quantity: quantity},
+			RelationsBundleComponent: db.RelationsBundleComponent{Component: &componentProduct},
+		}
+	}
+	tests := []struct {
+		name       string
+		product    db.ProductModel
+		components []db.BundleComponentModel
+		want       int
+	}{
+		{
+			name:    "a product's own stock",
+			product: db.ProductModel{InnerProduct: db.InnerProduct{Type: db.ProductTypeStandard, Stock: 12}},
+			want:    12,
+		},
+		{
+			name:       "complete kits of the scarcest component",
+			product:    db.ProductModel{InnerProduct: db.InnerProduct{Type: db.ProductTypeBundle}},
+			components: []db.BundleComponentModel{component(2, 9), component(3, 20)},
+			want:       4,
+		},
+		{
+			name:       "a component short of one kit",
+			product:    db.ProductModel{InnerProduct: db.InnerProduct{Type: db.ProductTypeBundle}},
+			components: []db.BundleComponentModel{component(2, 9), component(5, 4)},
+			want:       0,
+		},
+		{
+			name:    "bundle without components",
+			product: db.ProductModel{InnerProduct: db.InnerProduct{Type: db.ProductTypeBundle, Stock: 3}},
+			want:    0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := productStock(test.product, test.components); got != test.want {
+				t.Errorf("productStock() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestProductAvailable(t *testing.T) {
+	inventory := func(quantities ...[2]int) []db.InventoryModel {
+		items := []db.InventoryModel{}
+		for _, quantity := range quantities {
+			items = append(items, db.InventoryModel{InnerInventory: db.InnerInventory{Quantity: quantity[0], Reserved: quantity[1]}})
+		}
+		return items
+	}
+	product := func(productType db.ProductType, stock []db.InventoryModel) db.ProductModel {
+		return db.ProductModel{
+			InnerProduct:     db.InnerProduct{Type: productType},
+			RelationsProduct: db.RelationsProduct{Inventory: stock},
+		}
+	}
+	component := func(quantity int, stock []db.InventoryModel) db.BundleComponentModel {
+		componentProduct := product(db.ProductTypeStandard, stock)
+		return db.BundleComponentModel{
+			InnerBundleComponent:     db.InnerBundleComponent{Quantity: quantity},
+			RelationsBundleComponent: db.RelationsBundleComponent{Component: &componentProduct},
+		}
+	}
+	tests := []struct {
+		name       string
+		product    db.ProductModel
+		components []db.BundleComponentModel
+		want       int
+	}{
+		{
+			name:    "on hand less reserved across warehouses",
+			product: product(db.ProductTypeStandard, inventory([2]int{10, 3}, [2]int{5, 5})),
+			want:    7,
+		},
+		{
+			name:    "complete kits of the scarcest component",
+			product: product(db.ProductTypeBundle, inventory()),
+			components: []db.BundleComponentModel{
+				component(2, inventory([2]int{9, 0})),
+				component(1, inventory([2]int{6, 3}, [2]int{1, 0})),
+			},
+			want: 4,
+		},
+		{
+			name:       "reserved components leave no kit",
+			product:    product(db.ProductTypeBundle, inventory()),
+			components: []db.BundleComponentModel{component(3, inventory([2]int{5, 3}))},
+			want:       0,
+		},
+		{
+			name:    "bundle without components",
+			product: product(db.ProductTypeBundle, inventory()),
+			want:    0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := productAvailable(test.product, test.components); got != test.want {
+				t.Errorf("productAvailable() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"Enterprise/cache"
 	"Enterprise/data"
 	"Enterprise/helpers"
 	"Enterprise/model"
@@ -8,14 +9,17 @@ import (
 	"Enterprise/repository"
 	"golang.org/x/net/context"
 	"net/http"
+	"sort"
+	"time"
 )
 
 type CategoryService struct {
-	Db *db.PrismaClient
+	Db    *db.PrismaClient
+	Cache *cache.Cache
 }
 
-func NewCategoryService(db *db.PrismaClient) *CategoryService {
-	return &CategoryService{Db: db}
+func NewCategoryService(db *db.PrismaClient, catalogCache *cache.Cache) *CategoryService {
+	return &CategoryService{Db: db, Cache: catalogCache}
 }
 
 func (p *CategoryService) CreateCategory(ctx context.Context, categoryModel *model.CategoryModel) *data.WebResponse {
@@ -44,9 +48,23 @@ func (p *CategoryService) CreateCategory(ctx context.Context, categoryModel *mod
 		}
 	}
 
+	var optional []db.CategorySetParam
+	if categoryModel.ParentId != nil {
+		parent, _ := p.Db.Category.FindFirst(db.Category.ID.Equals(*categoryModel.ParentId), db.Category.DeletedAt.IsNull()).Exec(ctx)
+		if parent == nil {
+			return &data.WebResponse{
+				Code:    http.StatusNotFound,
+				Message: "Parent Category Not Found",
+				Data:    nil,
+			}
+		}
+		optional = append(optional, db.Category.Parent.Link(db.Category.ID.Equals(parent.ID)))
+	}
+
 	_, err = p.Db.Category.CreateOne(
 		db.Category.Name.Set(categoryModel.Name),
 		db.Category.User.Link(db.User.ID.Equals(categoryModel.UserId)),
+		optional...,
 	).Exec(ctx)
 	if err != nil {
 		return &data.WebResponse{
@@ -55,6 +73,8 @@ func (p *CategoryService) CreateCategory(ctx context.Context, categoryModel *mod
 			Data:    nil,
 		}
 	}
+	p.Cache.Invalidate(ctx, cache.Categories)
+
 	err = repository.AuditLogs(ctx, p.Db, categoryModel.UserId, "Category created", "This action was performed by ")
 	if err != nil {
 		return &data.WebResponse{
@@ -71,9 +91,11 @@ func (p *CategoryService) CreateCategory(ctx context.Context, categoryModel *mod
 }
 
 func (p *CategoryService) GetCategoryById(ctx context.Context, categoryId int) *data.WebResponse {
-	category, _ := p.Db.Category.FindUnique(db.Category.ID.Equals(categoryId)).Select(
+	category, _ := p.Db.Category.FindFirst(db.Category.ID.Equals(categoryId), db.Category.DeletedAt.IsNull()).Select(
 		db.Category.ID.Field(),
 		db.Category.Name.Field(),
+		db.Category.ParentID.Field(),
+		db.Category.UpdatedAt.Field(),
 	).Exec(ctx)
 
 	if category == nil {
@@ -87,19 +109,34 @@ func (p *CategoryService) GetCategoryById(ctx context.Context, categoryId int) *
 		Code:    http.StatusOK,
 		Message: "Category found",
 		Data: struct {
-			Id   int    `json:"id"`
-			Name string `json:"name"`
+			Id       int    `json:"id"`
+			Name     string `json:"name"`
+			ParentId *int   `json:"parentId"`
 		}{
-			Id:   category.ID,
-			Name: category.Name,
+			Id:       category.ID,
+			Name:     category.Name,
+			ParentId: categoryParentId(*category),
 		},
+		ETag: helpers.ETag(category.UpdatedAt),
+	}
+}
+
+func (p *CategoryService) GetAllCategories(ctx context.Context, tree bool) *data.WebResponse {
+	if tree {
+		return p.Cache.Remember(ctx, cache.Categories, "tree", func() *data.WebResponse {
+			return p.GetCategoryTree(ctx)
+		})
 	}
+	return p.Cache.Remember(ctx, cache.Categories, "all", func() *data.WebResponse {
+		return p.getAllCategories(ctx)
+	})
 }
 
-func (p *CategoryService) GetAllCategories(ctx context.Context) *data.WebResponse {
-	categories, err := p.Db.Category.FindMany().Select(
+func (p *CategoryService) getAllCategories(ctx context.Context) *data.WebResponse {
+	categories, err := p.Db.Category.FindMany(db.Category.DeletedAt.IsNull()).Select(
 		db.Category.ID.Field(),
 		db.Category.Name.Field(),
+		db.Category.ParentID.Field(),
 	).Exec(ctx)
 	if err != nil {
 		return &data.WebResponse{
@@ -111,8 +148,9 @@ func (p *CategoryService) GetAllCategories(ctx context.Context) *data.WebRespons
 	var CategoryResponses []model.CategoryResponse
 	for _, category := range categories {
 		CategoryResponses = append(CategoryResponses, model.CategoryResponse{
-			Id:   category.ID,
-			Name: category.Name,
+			Id:       category.ID,
+			Name:     category.Name,
+			ParentId: categoryParentId(category),
 		})
 	}
 
@@ -132,6 +170,17 @@ func (p *CategoryService) UpdateCategory(ctx context.Context, category *model.Ca
 			Data:    validator.Error(),
 		}
 	}
+	categoryExist, _ := p.Db.Category.FindFirst(db.Category.ID.Equals(category.CategoryId), db.Category.DeletedAt.IsNull()).Exec(ctx)
+	if categoryExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Category Not Found",
+			Data:    nil,
+		}
+	}
+	if response := helpers.CheckETag(category.IfMatch, categoryExist.UpdatedAt, "Category"); response != nil {
+		return response
+	}
 	existingCategory, err := repository.ExistingCategoryByName(ctx, p.Db, category.Name)
 	if err != nil {
 		return &data.WebResponse{
@@ -147,7 +196,10 @@ func (p *CategoryService) UpdateCategory(ctx context.Context, category *model.Ca
 			Data:    nil,
 		}
 	}
-	_, err = p.Db.Category.FindUnique(db.Category.ID.Equals(category.CategoryId)).Update(db.Category.Name.Set(category.Name)).Exec(ctx)
+	result, err := p.Db.Category.FindMany(
+		db.Category.ID.Equals(category.CategoryId),
+		db.Category.UpdatedAt.Equals(categoryExist.UpdatedAt),
+	).Update(db.Category.Name.Set(category.Name)).Exec(ctx)
 	if err != nil {
 		return &data.WebResponse{
 			Code:    http.StatusInternalServerError,
@@ -155,6 +207,11 @@ func (p *CategoryService) UpdateCategory(ctx context.Context, category *model.Ca
 			Data:    nil,
 		}
 	}
+	if result.Count == 0 {
+		return helpers.PreconditionFailed("Category")
+	}
+	p.Cache.Invalidate(ctx, cache.Categories)
+
 	err = repository.AuditLogs(ctx, p.Db, category.UserId, "Category updated", "This action was performed by ")
 	if err != nil {
 		return &data.WebResponse{
@@ -171,8 +228,8 @@ func (p *CategoryService) UpdateCategory(ctx context.Context, category *model.Ca
 
 }
 
-func (p *CategoryService) DeleteCategory(ctx context.Context, categoryId int, auditId int) *data.WebResponse {
-	existingCategory, _ := p.Db.Category.FindUnique(db.Category.ID.Equals(categoryId)).Exec(ctx)
+func (p *CategoryService) DeleteCategory(ctx context.Context, categoryId int, auditId int, ifMatch string) *data.WebResponse {
+	existingCategory, _ := p.Db.Category.FindFirst(db.Category.ID.Equals(categoryId), db.Category.DeletedAt.IsNull()).Exec(ctx)
 	if existingCategory == nil {
 		return &data.WebResponse{
 			Code:    http.StatusNotFound,
@@ -180,7 +237,23 @@ func (p *CategoryService) DeleteCategory(ctx context.Context, categoryId int, au
 			Data:    nil,
 		}
 	}
-	_, err := p.Db.Category.FindUnique(db.Category.ID.Equals(categoryId)).Delete().Exec(ctx)
+	if response := helpers.CheckETag(ifMatch, existingCategory.UpdatedAt, "Category"); response != nil {
+		return response
+	}
+	child, _ := p.Db.Category.FindFirst(db.Category.ParentID.Equals(categoryId), db.Category.DeletedAt.IsNull()).Exec(ctx)
+	if child != nil {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Category has sub-categories, move or delete them first",
+			Data:    nil,
+		}
+	}
+	result, err := p.Db.Category.FindMany(
+		db.Category.ID.Equals(categoryId),
+		db.Category.UpdatedAt.Equals(existingCategory.UpdatedAt),
+	).Update(
+		db.Category.DeletedAt.Set(time.Now()),
+	).Exec(ctx)
 	if err != nil {
 		return &data.WebResponse{
 			Code:    http.StatusInternalServerError,
@@ -188,6 +261,11 @@ func (p *CategoryService) DeleteCategory(ctx context.Context, categoryId int, au
 			Data:    nil,
 		}
 	}
+	if result.Count == 0 {
+		return helpers.PreconditionFailed("Category")
+	}
+
+	p.Cache.Invalidate(ctx, cache.Categories)
 
 	err = repository.AuditLogs(ctx, p.Db, auditId, "Category deleted", "This action was performed by ")
 	if err != nil {
@@ -205,6 +283,285 @@ func (p *CategoryService) DeleteCategory(ctx context.Context, categoryId int, au
 	}
 }
 
+func (p *CategoryService) MoveCategory(ctx context.Context, moveModel *model.CategoryMoveModel) *data.WebResponse {
+	index, err := p.categoryIndex(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	current, ok := index[moveModel.CategoryId]
+	if !ok {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Category Not Found",
+			Data:    nil,
+		}
+	}
+	if response := helpers.CheckETag(moveModel.IfMatch, current.UpdatedAt, "Category"); response != nil {
+		return response
+	}
+
+	if moveModel.ParentId != nil {
+		if _, ok := index[*moveModel.ParentId]; !ok {
+			return &data.WebResponse{
+				Code:    http.StatusNotFound,
+				Message: "Parent Category Not Found",
+				Data:    nil,
+			}
+		}
+		// Walking up from the new parent must never reach the category being moved.
+		for ancestor := moveModel.ParentId; ancestor != nil; ancestor = categoryParentId(index[*ancestor]) {
+			if *ancestor == moveModel.CategoryId {
+				return &data.WebResponse{
+					Code:    http.StatusBadRequest,
+					Message: "A category cannot be moved under itself or one of its sub-categories",
+					Data:    nil,
+				}
+			}
+			if _, ok := index[*ancestor]; !ok {
+				break
+			}
+		}
+	}
+
+	// The check above ran on a snapshot, so the update repeats it. Moves are serialised by the table lock, and
+	// the update only applies while the category is unchanged and the new parent is not in its own subtree, so
+	// two concurrent moves cannot close a cycle.
+	move := p.Db.Prisma.ExecuteRaw(
+		`WITH RECURSIVE ancestors AS (
+			SELECT "id", "parentId" FROM "Category" WHERE "id" = CAST($2 AS INTEGER)
+			UNION
+			SELECT c."id", c."parentId" FROM "Category" c JOIN ancestors a ON c."id" = a."parentId"
+		)
+		UPDATE "Category" SET "parentId" = CAST($2 AS INTEGER), "updatedAt" = CURRENT_TIMESTAMP
+		WHERE "id" = $1 AND "deletedAt" IS NULL AND "updatedAt" = CAST($3 AS TIMESTAMP(3))
+		  AND NOT EXISTS (SELECT 1 FROM ancestors WHERE "id" = $1)`,
+		moveModel.CategoryId, moveModel.ParentId, current.UpdatedAt.UTC().Format("2006-01-02T15:04:05.000"),
+	).Tx()
+	err = p.Db.Prisma.Transaction(
+		p.Db.Prisma.ExecuteRaw(`LOCK TABLE "Category" IN SHARE ROW EXCLUSIVE MODE`).Tx(),
+		move,
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if move.Result().Count == 0 {
+		return helpers.PreconditionFailed("Category")
+	}
+
+	p.Cache.Invalidate(ctx, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, moveModel.UserId, "Category moved", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Category Moved",
+		Data:    nil,
+	}
+}
+
+func (p *CategoryService) GetCategoryTree(ctx context.Context) *data.WebResponse {
+	index, err := p.categoryIndex(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	links, err := p.Db.ProductOnCategory.FindMany(
+		db.ProductOnCategory.Product.Where(db.Product.DeletedAt.IsNull()),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	children := categoryChildren(index)
+	products := map[int]map[int]bool{}
+	for _, link := range links {
+		if products[link.CategoryID] == nil {
+			products[link.CategoryID] = map[int]bool{}
+		}
+		products[link.CategoryID][link.ProductID] = true
+	}
+	tree, _ := buildCategoryTree(0, children, products)
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Category tree",
+		Data:    tree,
+	}
+}
+
+func (p *CategoryService) GetCategoryBreadcrumb(ctx context.Context, categoryId int) *data.WebResponse {
+	index, err := p.categoryIndex(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if _, ok := index[categoryId]; !ok {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Category Not Found",
+			Data:    nil,
+		}
+	}
+
+	var breadcrumb []model.BreadcrumbResponse
+	for current := &categoryId; current != nil; {
+		category, ok := index[*current]
+		if !ok || len(breadcrumb) > len(index) {
+			break
+		}
+		breadcrumb = append([]model.BreadcrumbResponse{{Id: category.ID, Name: category.Name}}, breadcrumb...)
+		current = categoryParentId(category)
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Category breadcrumb",
+		Data:    breadcrumb,
+	}
+}
+
+// GetCategoryProducts lists the products of a category and of all its sub-categories.
+func (p *CategoryService) GetCategoryProducts(ctx context.Context, categoryId int) *data.WebResponse {
+	index, err := p.categoryIndex(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if _, ok := index[categoryId]; !ok {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Category Not Found",
+			Data:    nil,
+		}
+	}
+
+	categoryIds := categoryDescendants(categoryChildren(index), categoryId)
+	products, err := p.Db.Product.FindMany(
+		db.Product.DeletedAt.IsNull(),
+		db.Product.ProductOnCategory.Some(db.ProductOnCategory.CategoryID.In(categoryIds)),
+	).OrderBy(
+		db.Product.Name.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	var ProductResponses []model.ProductResponse
+	for _, product := range products {
+		description, _ := product.Description()
+		ProductResponses = append(ProductResponses, model.ProductResponse{
+			Id:          product.ID,
+			Name:        product.Name,
+			Description: description,
+			Price:       float32(product.Price),
+			Stock:       product.Stock,
+			CreatedAt:   product.CreatedAt,
+		})
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Category products",
+		Data:    ProductResponses,
+	}
+}
+
+func (p *CategoryService) RestoreCategory(ctx context.Context, categoryId int, auditId int) *data.WebResponse {
+	existingCategory, _ := p.Db.Category.FindFirst(db.Category.ID.Equals(categoryId), db.Category.Not(db.Category.DeletedAt.IsNull())).Exec(ctx)
+	if existingCategory == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Deleted Category Not Found",
+			Data:    nil,
+		}
+	}
+	_, err := p.Db.Category.FindUnique(db.Category.ID.Equals(categoryId)).Update(
+		db.Category.DeletedAt.SetOptional(nil),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	p.Cache.Invalidate(ctx, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, auditId, "Category restored", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Category Restored",
+		Data:    nil,
+	}
+}
+
+func (p *CategoryService) GetDeletedCategories(ctx context.Context) *data.WebResponse {
+	categories, err := p.Db.Category.FindMany(db.Category.Not(db.Category.DeletedAt.IsNull())).OrderBy(
+		db.Category.DeletedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	var CategoryResponses []model.CategoryResponse
+	for _, category := range categories {
+		deletedAt, _ := category.DeletedAt()
+		CategoryResponses = append(CategoryResponses, model.CategoryResponse{
+			Id:        category.ID,
+			Name:      category.Name,
+			DeletedAt: &deletedAt,
+		})
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Deleted Categories",
+		Data:    CategoryResponses,
+	}
+}
+
 func (p *CategoryService) AuditLogs(ctx context.Context) *data.WebResponse {
 	logs, err := p.Db.AuditLog.FindMany().Select(
 		db.AuditLog.Action.Field(),
@@ -242,3 +599,77 @@ func (p *CategoryService) AuditLogs(ctx context.Context) *data.WebResponse {
 	}
 
 }
+
+// categoryIndex loads every active category keyed by id.
+func (p *CategoryService) categoryIndex(ctx context.Context) (map[int]db.CategoryModel, error) {
+	categories, err := p.Db.Category.FindMany(db.Category.DeletedAt.IsNull()).Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[int]db.CategoryModel, len(categories))
+	for _, category := range categories {
+		index[category.ID] = category
+	}
+	return index, nil
+}
+
+func categoryParentId(category db.CategoryModel) *int {
+	if parentId, ok := category.ParentID(); ok {
+		return &parentId
+	}
+	return nil
+}
+
+// categoryChildren groups categories by parent id; root categories are listed under 0.
+func categoryChildren(index map[int]db.CategoryModel) map[int][]db.CategoryModel {
+	children := map[int][]db.CategoryModel{}
+	for _, category := range index {
+		parentId := 0
+		if id := categoryParentId(category); id != nil {
+			if _, ok := index[*id]; ok {
+				parentId = *id
+			}
+		}
+		children[parentId] = append(children[parentId], category)
+	}
+	for _, siblings := range children {
+		sort.Slice(siblings, func(i, j int) bool {
+			return siblings[i].Name < siblings[j].Name
+		})
+	}
+	return children
+}
+
+func categoryDescendants(children map[int][]db.CategoryModel, categoryId int) []int {
+	ids := []int{categoryId}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			ids = append(ids, child.ID)
+		}
+	}
+	return ids
+}
+
+// buildCategoryTree returns the nodes under parentId together with the distinct products found in that subtree.
+func buildCategoryTree(parentId int, children map[int][]db.CategoryModel, products map[int]map[int]bool) ([]model.CategoryTreeResponse, map[int]bool) {
+	nodes := []model.CategoryTreeResponse{}
+	subtreeProducts := map[int]bool{}
+	for _, category := range children[parentId] {
+		childNodes, childProducts := buildCategoryTree(category.ID, children, products)
+		for productId := range products[category.ID] {
+			childProducts[productId] = true
+		}
+		for productId := range childProducts {
+			subtreeProducts[productId] = true
+		}
+		nodes = append(nodes, model.CategoryTreeResponse{
+			Id:                category.ID,
+			Name:              category.Name,
+			ParentId:          categoryParentId(category),
+			ProductCount:      len(products[category.ID]),
+			TotalProductCount: len(childProducts),
+			Children:          childNodes,
+		})
+	}
+	return nodes, subtreeProducts
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/labels"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"bytes"
+	"fmt"
+	"golang.org/x/net/context"
+	"io"
+	"net/http"
+)
+
+// maxLabelsPerSheet keeps a single request from rendering an unbounded PDF.
+const maxLabelsPerSheet = 2000
+
+type LabelService struct {
+	Db *db.PrismaClient
+}
+
+func NewLabelService(db *db.PrismaClient) *LabelService {
+	return &LabelService{Db: db}
+}
+
+// ProductBarcode renders a product's barcode. EAN-13 needs a stored barcode, the other types fall back to the product code.
+func (p *LabelService) ProductBarcode(ctx context.Context, productId int, symbology string, format string) (*model.FileDownload, *data.WebResponse) {
+	product, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(productId), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if product == nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+	return renderBarcode(productLabelCode(*product), symbology, format, fmt.Sprintf("product-%d", productId))
+}
+
+func (p *LabelService) VariantBarcode(ctx context.Context, sku string, symbology string, format string) (*model.FileDownload, *data.WebResponse) {
+	variant, _ := p.Db.ProductVariant.FindUnique(db.ProductVariant.Sku.Equals(sku)).Exec(ctx)
+	if variant == nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product variant not found",
+			Data:    nil,
+		}
+	}
+	return renderBarcode(variant.Sku, symbology, format, variant.Sku)
+}
+
+// LabelSheet renders a printable PDF with quantity labels for each requested product.
+func (p *LabelService) LabelSheet(ctx context.Context, sheetDto *model.LabelSheetModel) (*model.FileDownload, *data.WebResponse) {
+	validator := helpers.RequestValidators(sheetDto)
+	if validator != nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	symbology := sheetDto.Type
+	if symbology == "" {
+		symbology = labels.Code128
+	}
+
+	total := 0
+	var productIds []int
+	for _, item := range sheetDto.Items {
+		total += item.Quantity
+		productIds = append(productIds, item.ProductId)
+	}
+	if total > maxLabelsPerSheet {
+		return nil, &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("A label sheet can hold at most %d labels", maxLabelsPerSheet),
+			Data:    nil,
+		}
+	}
+
+	products, err := p.Db.Product.FindMany(db.Product.ID.In(productIds), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if err != nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	byId := map[int]db.ProductModel{}
+	for _, product := range products {
+		byId[product.ID] = product
+	}
+
+	var sheet []labels.Label
+	for _, item := range sheetDto.Items {
+		product, ok := byId[item.ProductId]
+		if !ok {
+			return nil, &data.WebResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Product %d not found", item.ProductId),
+				Data:    nil,
+			}
+		}
+		content := productLabelCode(product)
+		code, err := labels.Encode(content, symbology)
+		if err != nil {
+			return nil, &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("%s: %s", product.Name, err.Error()),
+				Data:    nil,
+			}
+		}
+		for i := 0; i < item.Quantity; i++ {
+			sheet = append(sheet, labels.Label{Title: product.Name, Caption: content, Code: code})
+		}
+	}
+
+	pdf, err := labels.Sheet(sheet)
+	if err != nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &model.FileDownload{
+		Body:        io.NopCloser(bytes.NewReader(pdf)),
+		ContentType: "application/pdf",
+		FileName:    "labels.pdf",
+	}, nil
+}
+
+// productLabelCode is what a product's label encodes: its EAN-13 barcode, or an internal code when it has none.
+func productLabelCode(product db.ProductModel) string {
+	if barcode, ok := product.Barcode(); ok {
+		return barcode
+	}
+	return fmt.Sprintf("PRD%08d", product.ID)
+}
+
+func renderBarcode(content string, symbology string, format string, name string) (*model.FileDownload, *data.WebResponse) {
+	if symbology == "" {
+		symbology = labels.Code128
+	}
+	code, err := labels.Encode(content, symbology)
+	if err != nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	image, contentType, err := labels.Render(code, format, 400, 150)
+	if err != nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	extension := labels.PNG
+	if contentType == "image/svg+xml" {
+		extension = labels.SVG
+	}
+	return &model.FileDownload{
+		Body:        io.NopCloser(bytes.NewReader(image)),
+		ContentType: contentType,
+		FileName:    fmt.Sprintf("%s-%s.%s", name, symbology, extension),
+	}, nil
+}
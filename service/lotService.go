@@ -0,0 +1,181 @@
+package service
+
+import (
+	"Enterprise/data"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"golang.org/x/net/context"
+	"net/http"
+	"time"
+)
+
+const lotQuery = `
+	SELECT l."id", l."productId", p."name" AS "productName", l."warehouseId", w."name" AS "warehouseName",
+	       l."lotNumber", l."manufacturedAt", l."expiresAt", l."quantity",
+	       COALESCE(l."expiresAt" <= CURRENT_TIMESTAMP, false) AS "expired"
+	FROM "InventoryLot" l
+	JOIN "Product" p ON p."id" = l."productId"
+	JOIN "Warehouse" w ON w."id" = l."warehouseId"`
+
+type LotService struct {
+	Db *db.PrismaClient
+}
+
+func NewLotService(db *db.PrismaClient) *LotService {
+	return &LotService{Db: db}
+}
+
+// GetProductLots lists the lots of a product still in stock, soonest expiring first, optionally for one warehouse.
+func (p *LotService) GetProductLots(ctx context.Context, productId int, warehouseId int) *data.WebResponse {
+	productExist, _ := p.Db.Product.FindUnique(db.Product.ID.Equals(productId)).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+
+	var lots []model.LotResponse
+	err := p.Db.Prisma.QueryRaw(lotQuery+`
+		WHERE l."productId" = $1 AND l."quantity" > 0 AND ($2 = 0 OR l."warehouseId" = $2)
+		ORDER BY l."expiresAt" NULLS LAST, l."lotNumber", w."name"`,
+		productId, warehouseId,
+	).Exec(ctx, &lots)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Product lots",
+		Data:    lots,
+	}
+}
+
+// GetExpiringLots reports the lots in stock that expire within the given number of days, including the ones
+// that already expired and still have to be written off.
+func (p *LotService) GetExpiringLots(ctx context.Context, days int, warehouseId int) *data.WebResponse {
+	if days < 0 {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Days must not be negative",
+			Data:    nil,
+		}
+	}
+
+	var lots []model.LotResponse
+	err := p.Db.Prisma.QueryRaw(lotQuery+`
+		WHERE l."quantity" > 0 AND l."expiresAt" <= CURRENT_TIMESTAMP + make_interval(days => $1)
+		  AND p."deletedAt" IS NULL AND ($2 = 0 OR l."warehouseId" = $2)
+		ORDER BY l."expiresAt", p."name", w."name"`,
+		days, warehouseId,
+	).Exec(ctx, &lots)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Expiring lots",
+		Data:    lots,
+	}
+}
+
+// GetLotRecall finds every order that received units of a lot, and where the rest of the lot still is.
+func (p *LotService) GetLotRecall(ctx context.Context, productId int, lotNumber string) *data.WebResponse {
+	if lotNumber == "" {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Lot number is required",
+			Data:    nil,
+		}
+	}
+	var lots []model.LotResponse
+	err := p.Db.Prisma.QueryRaw(lotQuery+`
+		WHERE l."productId" = $1 AND l."lotNumber" = $2
+		ORDER BY w."name"`,
+		productId, lotNumber,
+	).Exec(ctx, &lots)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if len(lots) == 0 {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Lot not found",
+			Data:    nil,
+		}
+	}
+
+	var orders []model.LotRecallOrderResponse
+	err = p.Db.Prisma.QueryRaw(`
+		SELECT o."id" AS "orderId", o."userId", CAST(o."status" AS TEXT) AS "status", l."warehouseId",
+		       CAST(SUM(a."quantity") AS INTEGER) AS "quantity", o."createdAt" AS "orderedAt"
+		FROM "OrderLotAllocation" a
+		JOIN "InventoryLot" l ON l."id" = a."lotId"
+		JOIN "Order" o ON o."id" = a."orderId"
+		WHERE l."productId" = $1 AND l."lotNumber" = $2
+		GROUP BY o."id", l."warehouseId"
+		ORDER BY o."createdAt", o."id"`,
+		productId, lotNumber,
+	).Exec(ctx, &orders)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Lot recall",
+		Data: model.LotRecallResponse{
+			ProductId: productId,
+			LotNumber: lotNumber,
+			Lots:      lots,
+			Orders:    orders,
+		},
+	}
+}
+
+// lotDetails checks the lot given for stock coming in. Lot tracked products must be received into a lot, and
+// the dates of a lot are only taken with its number.
+func lotDetails(lotTracked bool, receipt bool, lotNumber string, manufacturedAt *time.Time, expiresAt *time.Time) (*repository.Lot, *data.WebResponse) {
+	if lotNumber == "" {
+		if manufacturedAt != nil || expiresAt != nil {
+			return nil, &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Manufacture and expiry dates need a lot number",
+				Data:    nil,
+			}
+		}
+		if lotTracked && receipt {
+			return nil, &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Lot tracked products must be received with a lot number",
+				Data:    nil,
+			}
+		}
+		return nil, nil
+	}
+	if manufacturedAt != nil && expiresAt != nil && !expiresAt.After(*manufacturedAt) {
+		return nil, &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Expiry date must be after the manufacture date",
+			Data:    nil,
+		}
+	}
+	return &repository.Lot{Number: lotNumber, ManufacturedAt: manufacturedAt, ExpiresAt: expiresAt}, nil
+}
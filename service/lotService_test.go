@@ -0,0 +1,45 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLotDetails(t *testing.T) {
+	manufactured := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	expires := manufactured.AddDate(1, 0, 0)
+	tests := []struct {
+		name           string
+		lotTracked     bool
+		receipt        bool
+		lotNumber      string
+		manufacturedAt *time.Time
+		expiresAt      *time.Time
+		wantCode       int // 0 when the lot is accepted
+		wantLot        bool
+	}{
+		{name: "untracked without a lot", receipt: true},
+		{name: "tracked receipt needs a lot", lotTracked: true, receipt: true, wantCode: http.StatusBadRequest},
+		{name: "tracked issue may come from unlotted stock", lotTracked: true},
+		{name: "dates need a lot number", receipt: true, expiresAt: &expires, wantCode: http.StatusBadRequest},
+		{name: "expiry before manufacture", receipt: true, lotNumber: "L1", manufacturedAt: &expires, expiresAt: &manufactured, wantCode: http.StatusBadRequest},
+		{name: "lot with dates", lotTracked: true, receipt: true, lotNumber: "L1", manufacturedAt: &manufactured, expiresAt: &expires, wantLot: true},
+		{name: "lot without dates", receipt: true, lotNumber: "L1", wantLot: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lot, response := lotDetails(test.lotTracked, test.receipt, test.lotNumber, test.manufacturedAt, test.expiresAt)
+			code := 0
+			if response != nil {
+				code = response.Code
+			}
+			if code != test.wantCode || (lot != nil) != test.wantLot {
+				t.Fatalf("lotDetails() answers %d with lot %v, want %d with lot %v", code, lot, test.wantCode, test.wantLot)
+			}
+			if lot != nil && (lot.Number != test.lotNumber || lot.ExpiresAt != test.expiresAt) {
+				t.Errorf("lotDetails() = %+v, want lot %s expiring %v", lot, test.lotNumber, test.expiresAt)
+			}
+		})
+	}
+}
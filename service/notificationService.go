@@ -0,0 +1,99 @@
+package service
+
+import (
+	"Enterprise/data"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"golang.org/x/net/context"
+	"net/http"
+	"time"
+)
+
+type NotificationService struct {
+	Db *db.PrismaClient
+}
+
+func NewNotificationService(db *db.PrismaClient) *NotificationService {
+	return &NotificationService{Db: db}
+}
+
+// GetNotifications is the user's feed, newest first, only the unread ones when unreadOnly is set.
+func (p *NotificationService) GetNotifications(ctx context.Context, userId int, unreadOnly bool) *data.WebResponse {
+	filters := []db.NotificationWhereParam{db.Notification.UserID.Equals(userId)}
+	if unreadOnly {
+		filters = append(filters, db.Notification.ReadAt.IsNull())
+	}
+	notifications, err := p.Db.Notification.FindMany(filters...).OrderBy(
+		db.Notification.CreatedAt.Order(db.SortOrderDesc),
+	).Take(100).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var NotificationResponses []model.NotificationResponse
+	for _, notification := range notifications {
+		response := model.NotificationResponse{
+			Id:        notification.ID,
+			Type:      string(notification.Type),
+			Title:     notification.Title,
+			Message:   notification.Message,
+			CreatedAt: notification.CreatedAt,
+		}
+		if link, ok := notification.Link(); ok {
+			response.Link = &link
+		}
+		if readAt, ok := notification.ReadAt(); ok {
+			response.ReadAt = &readAt
+		}
+		NotificationResponses = append(NotificationResponses, response)
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Notifications",
+		Data:    NotificationResponses,
+	}
+}
+
+func (p *NotificationService) MarkNotificationRead(ctx context.Context, notificationId int, userId int) *data.WebResponse {
+	notification, _ := p.Db.Notification.FindFirst(db.Notification.ID.Equals(notificationId), db.Notification.UserID.Equals(userId)).Exec(ctx)
+	if notification == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Notification not found",
+			Data:    nil,
+		}
+	}
+	return p.markRead(ctx, db.Notification.UserID.Equals(userId), db.Notification.ID.Equals(notificationId))
+}
+
+func (p *NotificationService) MarkAllNotificationsRead(ctx context.Context, userId int) *data.WebResponse {
+	return p.markRead(ctx, db.Notification.UserID.Equals(userId))
+}
+
+// markRead marks the unread notifications matching filters as read.
+func (p *NotificationService) markRead(ctx context.Context, filters ...db.NotificationWhereParam) *data.WebResponse {
+	filters = append(filters, db.Notification.ReadAt.IsNull())
+	result, err := p.Db.Notification.FindMany(filters...).Update(
+		db.Notification.ReadAt.Set(time.Now()),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Notifications marked read",
+		Data: struct {
+			Count int `json:"count"`
+		}{
+			Count: result.Count,
+		},
+	}
+}
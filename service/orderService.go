@@ -0,0 +1,826 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"errors"
+	"fmt"
+	"golang.org/x/net/context"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type OrderService struct {
+	Db    *db.PrismaClient
+	Cache *cache.Cache
+}
+
+func NewOrderService(db *db.PrismaClient, catalogCache *cache.Cache) *OrderService {
+	return &OrderService{Db: db, Cache: catalogCache}
+}
+
+// CreateOrder places an order for the user. Each line is priced for the user's customer group and the price is
+// kept on the line. The stock is reserved in the active warehouses in the same transaction that creates the
+// order, so the whole order is refused when any line lacks available stock. Reservations the user holds are
+// taken over by the order and count towards it. The order's reservations lapse after the reservation TTL, the
+// stock itself is only shipped when the order is.
+func (p *OrderService) CreateOrder(ctx context.Context, orderDto *model.OrderModel) *data.WebResponse {
+	validator := helpers.RequestValidators(orderDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	user, _ := p.Db.User.FindUnique(db.User.ID.Equals(orderDto.UserId)).Exec(ctx)
+	if user == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+		}
+	}
+
+	// held is the stock the user's reservations hold, by product or variant and warehouse
+	held := map[stockLine]map[int]int{}
+	if len(orderDto.ReservationIds) > 0 {
+		reservations, err := p.Db.StockReservation.FindMany(
+			db.StockReservation.ID.In(orderDto.ReservationIds),
+			db.StockReservation.UserID.Equals(orderDto.UserId),
+			db.StockReservation.Status.Equals(db.ReservationStatusActive),
+			db.StockReservation.OrderID.IsNull(),
+		).Exec(ctx)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		if len(reservations) != len(orderDto.ReservationIds) {
+			return &data.WebResponse{
+				Code:    http.StatusConflict,
+				Message: "Some reservations are not yours or are no longer active",
+				Data:    nil,
+			}
+		}
+		held = heldStock(reservations)
+	}
+
+	now := time.Now()
+	var prices []float64
+	var total float64
+	demand := map[stockLine]int{}
+	names := map[int]string{}
+	seen := map[string]bool{}
+	for _, line := range orderDto.Items {
+		key := fmt.Sprintf("%d", line.ProductId)
+		if line.VariantId != nil {
+			key = fmt.Sprintf("%d/%d", line.ProductId, *line.VariantId)
+		}
+		if seen[key] {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Product %d is listed more than once", line.ProductId),
+				Data:    nil,
+			}
+		}
+		seen[key] = true
+
+		product, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(line.ProductId), db.Product.DeletedAt.IsNull()).With(
+			db.Product.BundleComponents.Fetch().With(db.BundleComponent.Component.Fetch()),
+		).Exec(ctx)
+		if product == nil {
+			return &data.WebResponse{
+				Code:    http.StatusNotFound,
+				Message: fmt.Sprintf("Product %d not found", line.ProductId),
+				Data:    nil,
+			}
+		}
+		if line.VariantId != nil && product.Type == db.ProductTypeBundle {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Product %d is a bundle and has no variants", product.ID),
+				Data:    nil,
+			}
+		}
+		if line.VariantId == nil && repository.ProductHasVariants(ctx, p.Db, product.ID) {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Product %d has variants, choose one", product.ID),
+				Data:    nil,
+			}
+		}
+		var variant *db.ProductVariantModel
+		if line.VariantId != nil {
+			variant, _ = p.Db.ProductVariant.FindUnique(db.ProductVariant.ID.Equals(*line.VariantId)).Exec(ctx)
+			if variant == nil || variant.ProductID != product.ID {
+				return &data.WebResponse{
+					Code:    http.StatusBadRequest,
+					Message: fmt.Sprintf("Variant %d is not a variant of product %d", *line.VariantId, product.ID),
+					Data:    nil,
+				}
+			}
+		}
+		resolved, err := resolvePrice(ctx, p.Db, *product, product.BundleComponents(), variant, user.CustomerGroup, line.Quantity, "", now)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusUnprocessableEntity,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		price := resolved.UnitPrice
+
+		names[product.ID] = product.Name
+		for _, component := range product.BundleComponents() {
+			names[component.ComponentID] = component.Component().Name
+		}
+		stockDemand(demand, *product, product.BundleComponents(), line.VariantId, line.Quantity)
+		total += roundMoney(price * float64(line.Quantity))
+		prices = append(prices, price)
+	}
+	for line, warehouses := range held {
+		if demand[line] == 0 {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("A reservation for product %d does not match any line of the order", line.productId),
+				Data:    nil,
+			}
+		}
+		for _, quantity := range warehouses {
+			demand[line] -= quantity
+		}
+		if demand[line] < 0 {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("The reservations for product %d hold more than the order takes", line.productId),
+				Data:    nil,
+			}
+		}
+	}
+
+	orderId, err := repository.NextId(ctx, p.Db, "Order")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	reference := orderReference(orderId)
+	total = roundMoney(total)
+
+	transactions := []db.PrismaTransaction{
+		p.Db.Order.CreateOne(
+			db.Order.User.Link(db.User.ID.Equals(orderDto.UserId)),
+			db.Order.TotalAmount.Set(total),
+			db.Order.Status.Set(db.OrderStatusPending),
+			db.Order.ID.Set(orderId),
+		).Tx(),
+		p.Db.OrderStatusHistory.CreateOne(
+			db.OrderStatusHistory.Order.Link(db.Order.ID.Equals(orderId)),
+			db.OrderStatusHistory.ToStatus.Set(db.OrderStatusPending),
+			db.OrderStatusHistory.User.Link(db.User.ID.Equals(orderDto.UserId)),
+		).Tx(),
+	}
+	for i, line := range orderDto.Items {
+		item := []db.OrderItemSetParam{}
+		if line.VariantId != nil {
+			item = append(item, db.OrderItem.Variant.Link(db.ProductVariant.ID.Equals(*line.VariantId)))
+		}
+		transactions = append(transactions, p.Db.OrderItem.CreateOne(
+			db.OrderItem.Order.Link(db.Order.ID.Equals(orderId)),
+			db.OrderItem.Product.Link(db.Product.ID.Equals(line.ProductId)),
+			db.OrderItem.Quantity.Set(line.Quantity),
+			db.OrderItem.Price.Set(prices[i]),
+			item...,
+		).Tx())
+	}
+	// The user's reservations move to the order and lapse with it, demand is what they do not already hold. The
+	// StockReservation claim trigger fails the transaction when another order took one over first.
+	expiresAt := now.Add(reservationTTL())
+	for _, reservationId := range orderDto.ReservationIds {
+		transactions = append(transactions, p.Db.StockReservation.FindUnique(db.StockReservation.ID.Equals(reservationId)).Update(
+			db.StockReservation.Order.Link(db.Order.ID.Equals(orderId)),
+			db.StockReservation.ExpiresAt.Set(expiresAt),
+		).Tx())
+	}
+	for _, line := range sortedStockLines(demand) {
+		if demand[line] == 0 {
+			continue
+		}
+		reservations, err := reserveLine(ctx, p.Db, line, names[line.productId], demand[line], nil, &orderId, &reference, orderDto.UserId, &expiresAt)
+		if errors.Is(err, errInsufficientStock) {
+			return &data.WebResponse{
+				Code:    http.StatusConflict,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		transactions = append(transactions, reservations...)
+	}
+
+	err = p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if response := orderPlacementError(err); response != nil {
+		return response
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, orderDto.UserId, "Order Placed", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Order placed",
+		Data: struct {
+			Id          int     `json:"id"`
+			TotalAmount float64 `json:"totalAmount"`
+		}{
+			Id:          orderId,
+			TotalAmount: total,
+		},
+	}
+}
+
+// UpdateOrderStatus moves an order along its lifecycle: a pending order is shipped or cancelled, a shipped
+// order is delivered.
+func (p *OrderService) UpdateOrderStatus(ctx context.Context, statusDto *model.OrderStatusModel) *data.WebResponse {
+	validator := helpers.RequestValidators(statusDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	order, _ := p.Db.Order.FindUnique(db.Order.ID.Equals(statusDto.OrderId)).Exec(ctx)
+	if order == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Order not found",
+			Data:    nil,
+		}
+	}
+	return p.transitionOrder(ctx, *order, db.OrderStatus(statusDto.Status), statusDto.Note, statusDto.UserId)
+}
+
+// CancelOrder cancels a pending order for the user who placed it or a manager, its stock goes back on the shelf.
+func (p *OrderService) CancelOrder(ctx context.Context, statusDto *model.OrderStatusModel) *data.WebResponse {
+	statusDto.Status = string(db.OrderStatusCancelled)
+	validator := helpers.RequestValidators(statusDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	order, response := p.accessibleOrder(ctx, statusDto.OrderId, statusDto.UserId)
+	if response != nil {
+		return response
+	}
+	return p.transitionOrder(ctx, *order, db.OrderStatusCancelled, statusDto.Note, statusDto.UserId)
+}
+
+// GetOrderHistory lists the statuses an order went through, oldest first.
+func (p *OrderService) GetOrderHistory(ctx context.Context, orderId int, userId int) *data.WebResponse {
+	if _, response := p.accessibleOrder(ctx, orderId, userId); response != nil {
+		return response
+	}
+	history, err := p.Db.OrderStatusHistory.FindMany(db.OrderStatusHistory.OrderID.Equals(orderId)).OrderBy(
+		db.OrderStatusHistory.ID.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Order history",
+		Data:    orderHistoryResponses(history),
+	}
+}
+
+// GetOrders lists the orders matching the filter, newest first, one page at a time. The search matches an order
+// id exactly or the name or email of the user who placed it.
+func (p *OrderService) GetOrders(ctx context.Context, filter *model.OrderFilterModel) *data.WebResponsePagination {
+	validator := helpers.RequestValidators(filter)
+	if validator != nil {
+		return &data.WebResponsePagination{
+			Code:   http.StatusBadRequest,
+			Status: "Validation error: " + validator.Error(),
+		}
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return &data.WebResponsePagination{
+			Code:   http.StatusBadRequest,
+			Status: "from must not be after to",
+		}
+	}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PerPage == 0 {
+		filter.PerPage = 20
+	}
+
+	// Unset filters are passed as empty strings and negative numbers, which match every order.
+	timestamp := func(at *time.Time) string {
+		if at == nil {
+			return ""
+		}
+		return at.UTC().Format("2006-01-02T15:04:05.000")
+	}
+	amount := func(value *float64) float64 {
+		if value == nil {
+			return -1
+		}
+		return *value
+	}
+	search := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(filter.Search)
+	where := `
+		FROM "Order" o
+		JOIN "User" u ON u."id" = o."userId"
+		WHERE ($1 = '' OR CAST(o."status" AS TEXT) = $1)
+		  AND ($2 = '' OR o."createdAt" >= CAST(NULLIF($2, '') AS TIMESTAMP(3)))
+		  AND ($3 = '' OR o."createdAt" <= CAST(NULLIF($3, '') AS TIMESTAMP(3)))
+		  AND ($4 = 0 OR o."userId" = $4)
+		  AND ($5 < 0 OR o."totalAmount" >= $5)
+		  AND ($6 < 0 OR o."totalAmount" <= $6)
+		  AND ($7 = '' OR CAST(o."id" AS TEXT) = $7 OR u."email" ILIKE '%' || $8 || '%'
+		       OR u."firstName" ILIKE '%' || $8 || '%' OR u."lastName" ILIKE '%' || $8 || '%')`
+	params := []interface{}{filter.Status, timestamp(filter.From), timestamp(filter.To), filter.UserId,
+		amount(filter.MinAmount), amount(filter.MaxAmount), filter.Search, search}
+
+	var counts []struct {
+		Total int `json:"total"`
+	}
+	err := p.Db.Prisma.QueryRaw(`SELECT CAST(COUNT(*) AS INTEGER) AS "total"`+where, params...).Exec(ctx, &counts)
+	if err != nil {
+		return &data.WebResponsePagination{
+			Code:   http.StatusInternalServerError,
+			Status: err.Error(),
+		}
+	}
+	orders := []model.OrderSummaryResponse{}
+	err = p.Db.Prisma.QueryRaw(`
+		SELECT o."id", o."userId", u."email", u."firstName", COALESCE(u."lastName", '') AS "lastName",
+		       CAST(o."status" AS TEXT) AS "status", o."totalAmount",
+		       CAST((SELECT COALESCE(SUM(i."quantity"), 0) FROM "OrderItem" i WHERE i."orderId" = o."id") AS INTEGER) AS "itemCount",
+		       o."createdAt", o."updatedAt"`+where+`
+		ORDER BY o."createdAt" DESC, o."id" DESC
+		LIMIT $9 OFFSET $10`,
+		append(params, filter.PerPage, (filter.Page-1)*filter.PerPage)...,
+	).Exec(ctx, &orders)
+	if err != nil {
+		return &data.WebResponsePagination{
+			Code:   http.StatusInternalServerError,
+			Status: err.Error(),
+		}
+	}
+
+	total := 0
+	if len(counts) > 0 {
+		total = counts[0].Total
+	}
+	return &data.WebResponsePagination{
+		Code:   http.StatusOK,
+		Status: "Orders",
+		Data:   orders,
+		Meta:   helpers.PaginationMeta(filter.Page, filter.PerPage, len(orders), total),
+	}
+}
+
+// GetOrderById is an order with its lines, payments and status history.
+func (p *OrderService) GetOrderById(ctx context.Context, orderId int, userId int) *data.WebResponse {
+	if _, response := p.accessibleOrder(ctx, orderId, userId); response != nil {
+		return response
+	}
+	order, err := p.Db.Order.FindUnique(db.Order.ID.Equals(orderId)).With(
+		db.Order.OrderItems.Fetch().With(
+			db.OrderItem.Product.Fetch(),
+			db.OrderItem.Variant.Fetch(),
+		),
+		db.Order.Transactions.Fetch().OrderBy(db.Transaction.CreatedAt.Order(db.SortOrderAsc)),
+		db.Order.History.Fetch().OrderBy(db.OrderStatusHistory.ID.Order(db.SortOrderAsc)),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	response := model.OrderResponse{
+		Id:           order.ID,
+		UserId:       order.UserID,
+		Status:       string(order.Status),
+		TotalAmount:  order.TotalAmount,
+		Items:        []model.OrderItemResponse{},
+		Transactions: []model.OrderTransactionResponse{},
+		History:      orderHistoryResponses(order.History()),
+		CreatedAt:    order.CreatedAt,
+		UpdatedAt:    order.UpdatedAt,
+	}
+	for _, item := range order.OrderItems() {
+		itemResponse := model.OrderItemResponse{
+			ProductId:   item.ProductID,
+			ProductName: item.Product().Name,
+			Quantity:    item.Quantity,
+			Price:       item.Price,
+			Total:       roundMoney(item.Price * float64(item.Quantity)),
+		}
+		if variant, ok := item.Variant(); ok {
+			itemResponse.VariantId = &variant.ID
+			itemResponse.Sku = variant.Sku
+		}
+		response.Items = append(response.Items, itemResponse)
+	}
+	for _, transaction := range order.Transactions() {
+		response.Transactions = append(response.Transactions, model.OrderTransactionResponse{
+			Id:          transaction.ID,
+			Amount:      transaction.Amount,
+			Method:      string(transaction.Method),
+			Status:      string(transaction.Status),
+			ProcessedAt: transaction.ProcessedAt,
+			CreatedAt:   transaction.CreatedAt,
+		})
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Order found",
+		Data:    response,
+	}
+}
+
+// transitionOrder changes the status of an order, records the change in its history and notifies the user who
+// placed it. Shipping takes the stock out of the warehouses, cancelling releases what the order holds. The Order
+// status trigger refuses illegal transitions, also when a concurrent request changed the order after it was read.
+func (p *OrderService) transitionOrder(ctx context.Context, order db.OrderModel, status db.OrderStatus, note string, userId int) *data.WebResponse {
+	if !orderTransitionAllowed(order.Status, status) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("A %s order cannot be %s", strings.ToLower(string(order.Status)), strings.ToLower(string(status))),
+			Data:    nil,
+		}
+	}
+
+	transactions := []db.PrismaTransaction{
+		p.Db.Order.FindUnique(db.Order.ID.Equals(order.ID)).Update(
+			db.Order.Status.Set(status),
+		).Tx(),
+		p.Db.OrderStatusHistory.CreateOne(
+			db.OrderStatusHistory.Order.Link(db.Order.ID.Equals(order.ID)),
+			db.OrderStatusHistory.ToStatus.Set(status),
+			db.OrderStatusHistory.User.Link(db.User.ID.Equals(userId)),
+			db.OrderStatusHistory.FromStatus.Set(order.Status),
+			db.OrderStatusHistory.Note.SetIfPresent(optionalString(note)),
+		).Tx(),
+		p.Db.Notification.CreateOne(
+			db.Notification.User.Link(db.User.ID.Equals(order.UserID)),
+			db.Notification.Type.Set(db.NotificationTypeOrderStatus),
+			db.Notification.Title.Set(fmt.Sprintf("Order %d %s", order.ID, strings.ToLower(string(status)))),
+			db.Notification.Message.Set(orderStatusMessage(order.ID, status, note)),
+			db.Notification.Link.Set(fmt.Sprintf("/api/order/detail/%d", order.ID)),
+		).Tx(),
+	}
+	switch status {
+	case db.OrderStatusShipped:
+		shipment, response := p.shipOrder(ctx, order.ID, userId)
+		if response != nil {
+			return response
+		}
+		transactions = append(transactions, shipment...)
+	case db.OrderStatusCancelled:
+		release, err := p.releaseOrder(ctx, order.ID)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		transactions = append(transactions, release...)
+	}
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if err != nil && strings.Contains(err.Error(), "cannot go from") {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Order was changed by another request, reload it and try again",
+			Data:    nil,
+		}
+	}
+	if repository.InsufficientStock(err) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Stock changed while the order was shipped, try again",
+			Data:    nil,
+		}
+	}
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if status == db.OrderStatusShipped || status == db.OrderStatusCancelled {
+		p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Order Status Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Order status updated",
+		Data:    nil,
+	}
+}
+
+// shipOrder returns the writes that ship a pending order: its reservations are consumed and the stock they held
+// is shipped, what lapsed is shipped from the stock still available.
+func (p *OrderService) shipOrder(ctx context.Context, orderId int, userId int) ([]db.PrismaTransaction, *data.WebResponse) {
+	reference := orderReference(orderId)
+	items, err := p.Db.OrderItem.FindMany(db.OrderItem.OrderID.Equals(orderId)).With(
+		db.OrderItem.Product.Fetch().With(
+			db.Product.BundleComponents.Fetch().With(db.BundleComponent.Component.Fetch()),
+		),
+	).Exec(ctx)
+	if err != nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	reservations, err := p.orderReservations(ctx, orderId)
+	if err != nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	demand := map[stockLine]int{}
+	names := map[int]string{}
+	for _, item := range items {
+		product := item.Product()
+		var variantId *int
+		if id, ok := item.VariantID(); ok {
+			variantId = &id
+		}
+		names[product.ID] = product.Name
+		for _, component := range product.BundleComponents() {
+			names[component.ComponentID] = component.Component().Name
+		}
+		stockDemand(demand, *product, product.BundleComponents(), variantId, item.Quantity)
+	}
+
+	// The reservations are consumed before the stock is shipped, so the stock they held can be shipped.
+	var transactions []db.PrismaTransaction
+	for _, reservation := range reservations {
+		transactions = append(transactions, repository.ReleaseReservation(p.Db, reservation.ID, db.ReservationStatusConsumed))
+	}
+	held := heldStock(reservations)
+	for _, line := range sortedStockLines(demand) {
+		shipments, response := p.shipOrderStock(ctx, orderId, line, names[line.productId], demand[line], held[line], reference, userId)
+		if response != nil {
+			return nil, response
+		}
+		transactions = append(transactions, shipments...)
+	}
+	return transactions, nil
+}
+
+// releaseOrder returns the writes that give back the stock a cancelled order holds, which only pending orders do.
+func (p *OrderService) releaseOrder(ctx context.Context, orderId int) ([]db.PrismaTransaction, error) {
+	reservations, err := p.orderReservations(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	var transactions []db.PrismaTransaction
+	for _, reservation := range reservations {
+		transactions = append(transactions, repository.ReleaseReservation(p.Db, reservation.ID, db.ReservationStatusReleased))
+	}
+	return transactions, nil
+}
+
+// orderReservations lists the reservations that still hold stock for an order.
+func (p *OrderService) orderReservations(ctx context.Context, orderId int) ([]db.StockReservationModel, error) {
+	return p.Db.StockReservation.FindMany(
+		db.StockReservation.OrderID.Equals(orderId),
+		db.StockReservation.Status.Equals(db.ReservationStatusActive),
+	).Exec(ctx)
+}
+
+// accessibleOrder finds an order the user may see: their own, or any order for a manager.
+func (p *OrderService) accessibleOrder(ctx context.Context, orderId int, userId int) (*db.OrderModel, *data.WebResponse) {
+	order, _ := p.Db.Order.FindUnique(db.Order.ID.Equals(orderId)).Exec(ctx)
+	if order == nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Order not found",
+			Data:    nil,
+		}
+	}
+	if order.UserID == userId {
+		return order, nil
+	}
+	user, _ := p.Db.User.FindUnique(db.User.ID.Equals(userId)).With(db.User.Role.Fetch()).Exec(ctx)
+	if user == nil || !slices.Contains(managerRoles, user.Role().Name) {
+		return nil, &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Order not found",
+			Data:    nil,
+		}
+	}
+	return order, nil
+}
+
+// shipOrderStock returns the writes that ship quantity units of a product or variant for an order and record the
+// lots they came from. Warehouses where the order's reservations hold the product go first, then the active
+// warehouses with the most available.
+func (p *OrderService) shipOrderStock(ctx context.Context, orderId int, line stockLine, name string, quantity int, held map[int]int, reference string, userId int) ([]db.PrismaTransaction, *data.WebResponse) {
+	inventory, err := p.Db.Inventory.FindMany(
+		db.Inventory.ProductID.Equals(line.productId),
+		db.Inventory.VariantID.EqualsOptional(line.variant()),
+		db.Inventory.Warehouse.Where(db.Warehouse.Active.Equals(true)),
+	).OrderBy(
+		db.Inventory.Quantity.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	sort.SliceStable(inventory, func(i, j int) bool {
+		return held[inventory[i].WarehouseID] > held[inventory[j].WarehouseID]
+	})
+
+	var transactions []db.PrismaTransaction
+	remaining := quantity
+	for _, item := range inventory {
+		take := min(item.Quantity-item.Reserved+held[item.WarehouseID], remaining)
+		if take <= 0 {
+			continue
+		}
+		shipments, allocations, err := repository.Outbound(ctx, p.Db, line.productId, line.variant(), item.WarehouseID, db.StockMovementTypeShipment, take, "Order shipped", &reference, &userId, false)
+		if errors.Is(err, repository.ErrExpiredStock) {
+			continue
+		}
+		if err != nil {
+			return nil, &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		transactions = append(transactions, shipments...)
+		for _, allocation := range allocations {
+			transactions = append(transactions, p.Db.OrderLotAllocation.CreateOne(
+				db.OrderLotAllocation.Order.Link(db.Order.ID.Equals(orderId)),
+				db.OrderLotAllocation.Product.Link(db.Product.ID.Equals(line.productId)),
+				db.OrderLotAllocation.Lot.Link(db.InventoryLot.ID.Equals(allocation.LotId)),
+				db.OrderLotAllocation.Quantity.Set(allocation.Quantity),
+			).Tx())
+		}
+		remaining -= take
+		if remaining == 0 {
+			break
+		}
+	}
+	if remaining > 0 {
+		return nil, &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("Insufficient stock: only %d of %s available", quantity-remaining, name),
+			Data:    nil,
+		}
+	}
+	return transactions, nil
+}
+
+// heldStock totals what reservations hold, by product or variant and warehouse.
+func heldStock(reservations []db.StockReservationModel) map[stockLine]map[int]int {
+	held := map[stockLine]map[int]int{}
+	for _, reservation := range reservations {
+		line := stockLine{productId: reservation.ProductID}
+		if variantId, ok := reservation.VariantID(); ok {
+			line.variantId = variantId
+		}
+		if held[line] == nil {
+			held[line] = map[int]int{}
+		}
+		held[line][reservation.WarehouseID] += reservation.Quantity
+	}
+	return held
+}
+
+// orderPlacementError turns the database refusing an order's reservations into a response.
+func orderPlacementError(err error) *data.WebResponse {
+	switch {
+	case err == nil:
+		return nil
+	case repository.InsufficientStock(err):
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Stock changed while the order was placed, try again",
+			Data:    nil,
+		}
+	case strings.Contains(err.Error(), "is no longer available to claim"):
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Some reservations were taken by another order or are no longer active",
+			Data:    nil,
+		}
+	default:
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+}
+
+func orderReference(orderId int) string {
+	return fmt.Sprintf("SO-%d", orderId)
+}
+
+// orderTransitions are the statuses each status can move to.
+var orderTransitions = map[db.OrderStatus][]db.OrderStatus{
+	db.OrderStatusPending: {db.OrderStatusShipped, db.OrderStatusCancelled},
+	db.OrderStatusShipped: {db.OrderStatusDelivered},
+}
+
+func orderTransitionAllowed(from db.OrderStatus, to db.OrderStatus) bool {
+	return slices.Contains(orderTransitions[from], to)
+}
+
+func orderStatusMessage(orderId int, status db.OrderStatus, note string) string {
+	var message string
+	switch status {
+	case db.OrderStatusShipped:
+		message = fmt.Sprintf("Your order %d is on its way.", orderId)
+	case db.OrderStatusDelivered:
+		message = fmt.Sprintf("Your order %d was delivered.", orderId)
+	default:
+		message = fmt.Sprintf("Your order %d was cancelled.", orderId)
+	}
+	if note != "" {
+		message += " " + note
+	}
+	return message
+}
+
+func orderHistoryResponses(history []db.OrderStatusHistoryModel) []model.OrderStatusHistoryResponse {
+	responses := []model.OrderStatusHistoryResponse{}
+	for _, entry := range history {
+		note, _ := entry.Note()
+		response := model.OrderStatusHistoryResponse{
+			Id:        entry.ID,
+			ToStatus:  string(entry.ToStatus),
+			UserId:    entry.UserID,
+			Note:      note,
+			CreatedAt: entry.CreatedAt,
+		}
+		if fromStatus, ok := entry.FromStatus(); ok {
+			from := string(fromStatus)
+			response.FromStatus = &from
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"Enterprise/prisma/db"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestOrderTransitionAllowed(t *testing.T) {
+	tests := []struct {
+		from db.OrderStatus
+		to   db.OrderStatus
+		want bool
+	}{
+		{db.OrderStatusPending, db.OrderStatusShipped, true},
+		{db.OrderStatusPending, db.OrderStatusCancelled, true},
+		{db.OrderStatusPending, db.OrderStatusDelivered, false},
+		{db.OrderStatusPending, db.OrderStatusPending, false},
+		{db.OrderStatusShipped, db.OrderStatusDelivered, true},
+		{db.OrderStatusShipped, db.OrderStatusCancelled, false},
+		{db.OrderStatusShipped, db.OrderStatusPending, false},
+		{db.OrderStatusDelivered, db.OrderStatusCancelled, false},
+		{db.OrderStatusDelivered, db.OrderStatusShipped, false},
+		{db.OrderStatusCancelled, db.OrderStatusPending, false},
+		{db.OrderStatusCancelled, db.OrderStatusShipped, false},
+	}
+	for _, test := range tests {
+		t.Run(string(test.from)+"->"+string(test.to), func(t *testing.T) {
+			if got := orderTransitionAllowed(test.from, test.to); got != test.want {
+				t.Errorf("orderTransitionAllowed(%s, %s) = %v, want %v", test.from, test.to, got, test.want)
+			}
+		})
+	}
+}
+
+func TestOrderPlacementError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    int // Response code, 0 for no response
+		message string
+	}{
+		{name: "no error"},
+		{
+			name:    "stock reserved by another order meanwhile",
+			err:     errors.New(`violates check constraint "Inventory_reserved_check"`),
+			want:    http.StatusConflict,
+			message: "Stock changed while the order was placed, try again",
+		},
+		{
+			name:    "reservation claimed by another order",
+			err:     errors.New("StockReservation 12 is no longer available to claim"),
+			want:    http.StatusConflict,
+			message: "Some reservations were taken by another order or are no longer active",
+		},
+		{name: "anything else", err: errors.New("connection reset"), want: http.StatusInternalServerError, message: "connection reset"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := orderPlacementError(test.err)
+			code, message := 0, ""
+			if response != nil {
+				code, message = response.Code, response.Message
+			}
+			if code != test.want || message != test.message {
+				t.Errorf("orderPlacementError(%v) answers %d %q, want %d %q", test.err, code, message, test.want, test.message)
+			}
+		})
+	}
+}
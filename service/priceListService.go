@@ -0,0 +1,630 @@
+package service
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"fmt"
+	"golang.org/x/net/context"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"time"
+)
+
+type PriceListService struct {
+	Db *db.PrismaClient
+}
+
+func NewPriceListService(db *db.PrismaClient) *PriceListService {
+	return &PriceListService{Db: db}
+}
+
+func (p *PriceListService) CreatePriceList(ctx context.Context, priceListDto *model.PriceListModel) *data.WebResponse {
+	if response := validatePriceList(priceListDto); response != nil {
+		return response
+	}
+	existing, _ := p.Db.PriceList.FindUnique(db.PriceList.Name.Equals(priceListDto.Name)).Exec(ctx)
+	if existing != nil {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Price list name already exists",
+			Data:    nil,
+		}
+	}
+
+	priceList, err := p.Db.PriceList.CreateOne(
+		db.PriceList.Name.Set(priceListDto.Name),
+		db.PriceList.Currency.Set(priceListDto.Currency),
+		db.PriceList.CustomerGroup.SetOptional(customerGroup(priceListDto.CustomerGroup)),
+		db.PriceList.Priority.Set(priceListDto.Priority),
+		db.PriceList.ValidFrom.SetOptional(priceListDto.ValidFrom),
+		db.PriceList.ValidTo.SetOptional(priceListDto.ValidTo),
+		db.PriceList.Active.SetIfPresent(priceListDto.Active),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, priceListDto.UserId, "Price List Created", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Price list created",
+		Data: struct {
+			Id int `json:"id"`
+		}{
+			Id: priceList.ID,
+		},
+	}
+}
+
+func (p *PriceListService) UpdatePriceList(ctx context.Context, priceListDto *model.PriceListModel) *data.WebResponse {
+	if response := validatePriceList(priceListDto); response != nil {
+		return response
+	}
+	priceListExist, _ := p.Db.PriceList.FindUnique(db.PriceList.ID.Equals(priceListDto.PriceListId)).Exec(ctx)
+	if priceListExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Price list not found",
+			Data:    nil,
+		}
+	}
+	existing, _ := p.Db.PriceList.FindUnique(db.PriceList.Name.Equals(priceListDto.Name)).Exec(ctx)
+	if existing != nil && existing.ID != priceListDto.PriceListId {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Price list name already exists",
+			Data:    nil,
+		}
+	}
+
+	_, err := p.Db.PriceList.FindUnique(db.PriceList.ID.Equals(priceListDto.PriceListId)).Update(
+		db.PriceList.Name.Set(priceListDto.Name),
+		db.PriceList.Currency.Set(priceListDto.Currency),
+		db.PriceList.CustomerGroup.SetOptional(customerGroup(priceListDto.CustomerGroup)),
+		db.PriceList.Priority.Set(priceListDto.Priority),
+		db.PriceList.ValidFrom.SetOptional(priceListDto.ValidFrom),
+		db.PriceList.ValidTo.SetOptional(priceListDto.ValidTo),
+		db.PriceList.Active.SetIfPresent(priceListDto.Active),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, priceListDto.UserId, "Price List Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Price list updated",
+		Data:    nil,
+	}
+}
+
+func (p *PriceListService) DeletePriceList(ctx context.Context, priceListId int, userId int) *data.WebResponse {
+	_, err := p.Db.PriceList.FindUnique(db.PriceList.ID.Equals(priceListId)).Delete().Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Price list not found",
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Price List Deleted", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Price list deleted",
+		Data:    nil,
+	}
+}
+
+func (p *PriceListService) GetPriceLists(ctx context.Context) *data.WebResponse {
+	priceLists, err := p.Db.PriceList.FindMany().OrderBy(
+		db.PriceList.Name.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var PriceListResponses []model.PriceListResponse
+	for _, priceList := range priceLists {
+		PriceListResponses = append(PriceListResponses, priceListResponse(priceList, nil))
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Price lists",
+		Data:    PriceListResponses,
+	}
+}
+
+func (p *PriceListService) GetPriceListById(ctx context.Context, priceListId int) *data.WebResponse {
+	priceList, _ := p.Db.PriceList.FindUnique(db.PriceList.ID.Equals(priceListId)).With(
+		db.PriceList.Items.Fetch().OrderBy(db.PriceListItem.ProductID.Order(db.SortOrderAsc)),
+	).Exec(ctx)
+	if priceList == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Price list not found",
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Price list found",
+		Data:    priceListResponse(*priceList, priceList.Items()),
+	}
+}
+
+// SetPriceListItems replaces the prices of a list. Several rows for one product with different
+// minimum quantities form its quantity-break tiers. Rows with a variant price that variant instead of the product.
+func (p *PriceListService) SetPriceListItems(ctx context.Context, itemsDto *model.PriceListItemsModel) *data.WebResponse {
+	validator := helpers.RequestValidators(itemsDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	priceListExist, _ := p.Db.PriceList.FindUnique(db.PriceList.ID.Equals(itemsDto.PriceListId)).Exec(ctx)
+	if priceListExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Price list not found",
+			Data:    nil,
+		}
+	}
+
+	seen := map[string]bool{}
+	productIds := map[int]bool{}
+	for i := range itemsDto.Items {
+		if itemsDto.Items[i].MinQuantity == 0 {
+			itemsDto.Items[i].MinQuantity = 1
+		}
+		item := itemsDto.Items[i]
+		key := fmt.Sprintf("%d:%d", item.ProductId, item.MinQuantity)
+		if item.VariantId != nil {
+			key = fmt.Sprintf("%d/%d:%d", item.ProductId, *item.VariantId, item.MinQuantity)
+		}
+		if seen[key] {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Product %d has more than one price from quantity %d", item.ProductId, item.MinQuantity),
+				Data:    nil,
+			}
+		}
+		seen[key] = true
+		productIds[item.ProductId] = true
+		if response := checkVariant(ctx, p.Db, item.ProductId, item.VariantId); response != nil {
+			return response
+		}
+	}
+	var ids []int
+	for id := range productIds {
+		ids = append(ids, id)
+	}
+	products, err := p.Db.Product.FindMany(db.Product.ID.In(ids), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if len(products) != len(ids) {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+
+	transactions := []db.PrismaTransaction{
+		p.Db.PriceListItem.FindMany(db.PriceListItem.PriceListID.Equals(itemsDto.PriceListId)).Delete().Tx(),
+	}
+	for _, item := range itemsDto.Items {
+		var variant []db.PriceListItemSetParam
+		if item.VariantId != nil {
+			variant = append(variant, db.PriceListItem.Variant.Link(db.ProductVariant.ID.Equals(*item.VariantId)))
+		}
+		transactions = append(transactions, p.Db.PriceListItem.CreateOne(
+			db.PriceListItem.PriceList.Link(db.PriceList.ID.Equals(itemsDto.PriceListId)),
+			db.PriceListItem.Product.Link(db.Product.ID.Equals(item.ProductId)),
+			db.PriceListItem.Price.Set(item.Price),
+			db.PriceListItem.MinQuantity.Set(item.MinQuantity),
+			variant...,
+		).Tx())
+	}
+	err = p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, itemsDto.UserId, "Price List Items Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Price list items updated",
+		Data:    nil,
+	}
+}
+
+func (p *PriceListService) CreateExchangeRate(ctx context.Context, rateDto *model.ExchangeRateModel) *data.WebResponse {
+	validator := helpers.RequestValidators(rateDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	effectiveAt := time.Now()
+	if rateDto.EffectiveAt != nil {
+		effectiveAt = *rateDto.EffectiveAt
+	}
+
+	rate, err := p.Db.ExchangeRate.CreateOne(
+		db.ExchangeRate.BaseCurrency.Set(rateDto.BaseCurrency),
+		db.ExchangeRate.QuoteCurrency.Set(rateDto.QuoteCurrency),
+		db.ExchangeRate.Rate.Set(rateDto.Rate),
+		db.ExchangeRate.EffectiveAt.Set(effectiveAt),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "An exchange rate for this currency pair and time already exists",
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, rateDto.UserId, "Exchange Rate Created", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Exchange rate created",
+		Data: struct {
+			Id int `json:"id"`
+		}{
+			Id: rate.ID,
+		},
+	}
+}
+
+func (p *PriceListService) GetExchangeRates(ctx context.Context) *data.WebResponse {
+	rates, err := p.Db.ExchangeRate.FindMany().OrderBy(
+		db.ExchangeRate.EffectiveAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var RateResponses []model.ExchangeRateResponse
+	for _, rate := range rates {
+		RateResponses = append(RateResponses, model.ExchangeRateResponse{
+			Id:            rate.ID,
+			BaseCurrency:  rate.BaseCurrency,
+			QuoteCurrency: rate.QuoteCurrency,
+			Rate:          rate.Rate,
+			EffectiveAt:   rate.EffectiveAt,
+		})
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Exchange rates",
+		Data:    RateResponses,
+	}
+}
+
+// ResolvePrice returns the price a user pays for quantity units of a product, or of one of its variants when
+// variantId is set. An empty group means the user's own group.
+func (p *PriceListService) ResolvePrice(ctx context.Context, productId int, variantId *int, userId int, group string, quantity int, currency string) *data.WebResponse {
+	if quantity < 1 {
+		quantity = 1
+	}
+	if group == "" {
+		user, _ := p.Db.User.FindUnique(db.User.ID.Equals(userId)).Exec(ctx)
+		if user == nil {
+			return &data.WebResponse{
+				Code:    http.StatusNotFound,
+				Message: "User not found",
+				Data:    nil,
+			}
+		}
+		group = string(user.CustomerGroup)
+	}
+	if group != string(db.CustomerGroupRetail) && group != string(db.CustomerGroupWholesale) {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "customerGroup must be RETAIL or WHOLESALE",
+			Data:    nil,
+		}
+	}
+
+	product, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(productId), db.Product.DeletedAt.IsNull()).With(
+		db.Product.BundleComponents.Fetch().With(db.BundleComponent.Component.Fetch()),
+	).Exec(ctx)
+	if product == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+
+	var variant *db.ProductVariantModel
+	if variantId != nil {
+		variant, _ = p.Db.ProductVariant.FindFirst(db.ProductVariant.ID.Equals(*variantId), db.ProductVariant.ProductID.Equals(productId)).Exec(ctx)
+		if variant == nil {
+			return &data.WebResponse{
+				Code:    http.StatusNotFound,
+				Message: "Variant not found",
+				Data:    nil,
+			}
+		}
+	}
+
+	resolved, err := resolvePrice(ctx, p.Db, *product, product.BundleComponents(), variant, db.CustomerGroup(group), quantity, currency, time.Now())
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Resolved price",
+		Data:    resolved,
+	}
+}
+
+// resolvePrice picks the unit price for quantity units of a product at the given time and converts it to currency.
+// Among the active, valid price lists with a tier for the quantity, lists for the customer group beat general
+// lists, then higher priority wins, then a list already in the requested currency, then the deepest tier.
+// Without a matching list the product's own price in the base currency is used. A variant is priced by the rows
+// for it where a list has them and by the product's rows otherwise, and without a list by its own price when it
+// has one.
+func resolvePrice(ctx context.Context, dbClient *db.PrismaClient, product db.ProductModel, components []db.BundleComponentModel, variant *db.ProductVariantModel, group db.CustomerGroup, quantity int, currency string, at time.Time) (*model.ResolvedPriceResponse, error) {
+	if currency == "" {
+		currency = baseCurrency()
+	}
+	resolved := &model.ResolvedPriceResponse{
+		ProductId:      product.ID,
+		Quantity:       quantity,
+		CustomerGroup:  string(group),
+		Currency:       currency,
+		SourceCurrency: baseCurrency(),
+		UnitPrice:      productPrice(product, components),
+	}
+	variantItems := db.PriceListItem.VariantID.IsNull()
+	if variant != nil {
+		resolved.VariantId = &variant.ID
+		if price, ok := variant.Price(); ok {
+			resolved.UnitPrice = price
+		}
+		variantItems = db.PriceListItem.Or(db.PriceListItem.VariantID.IsNull(), db.PriceListItem.VariantID.Equals(variant.ID))
+	}
+
+	items, err := dbClient.PriceListItem.FindMany(
+		db.PriceListItem.ProductID.Equals(product.ID),
+		variantItems,
+		db.PriceListItem.MinQuantity.Lte(quantity),
+		db.PriceListItem.PriceList.Where(
+			db.PriceList.Active.Equals(true),
+			db.PriceList.Or(db.PriceList.CustomerGroup.IsNull(), db.PriceList.CustomerGroup.Equals(group)),
+			db.PriceList.Or(db.PriceList.ValidFrom.IsNull(), db.PriceList.ValidFrom.BeforeEquals(at)),
+			db.PriceList.Or(db.PriceList.ValidTo.IsNull(), db.PriceList.ValidTo.After(at)),
+		),
+	).With(
+		db.PriceListItem.PriceList.Fetch(),
+	).Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// A list that prices the variant itself replaces its product rows for that variant.
+	variantLists := map[int]bool{}
+	for _, item := range items {
+		if _, ok := item.VariantID(); ok {
+			variantLists[item.PriceListID] = true
+		}
+	}
+	items = slices.DeleteFunc(items, func(item db.PriceListItemModel) bool {
+		_, ok := item.VariantID()
+		return !ok && variantLists[item.PriceListID]
+	})
+	if len(items) > 0 {
+		sort.Slice(items, func(i, j int) bool {
+			a, b := items[i].PriceList(), items[j].PriceList()
+			_, aGroup := a.CustomerGroup()
+			_, bGroup := b.CustomerGroup()
+			if aGroup != bGroup {
+				return aGroup
+			}
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+			if (a.Currency == currency) != (b.Currency == currency) {
+				return a.Currency == currency
+			}
+			if a.ID != b.ID {
+				return a.ID < b.ID
+			}
+			return items[i].MinQuantity > items[j].MinQuantity
+		})
+		best := items[0]
+		priceList := best.PriceList()
+		resolved.UnitPrice = best.Price
+		resolved.SourceCurrency = priceList.Currency
+		resolved.PriceListId = &priceList.ID
+		resolved.PriceListName = priceList.Name
+	}
+
+	rate, err := exchangeRate(ctx, dbClient, resolved.SourceCurrency, currency, at)
+	if err != nil {
+		return nil, err
+	}
+	resolved.ExchangeRate = rate
+	resolved.UnitPrice = roundMoney(resolved.UnitPrice * rate)
+	resolved.Total = roundMoney(resolved.UnitPrice * float64(quantity))
+	return resolved, nil
+}
+
+// exchangeRate is the latest manually entered rate from one currency to another at the given time,
+// using the inverse of the opposite pair when only that one is maintained.
+func exchangeRate(ctx context.Context, dbClient *db.PrismaClient, from string, to string, at time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	direct, _ := dbClient.ExchangeRate.FindFirst(
+		db.ExchangeRate.BaseCurrency.Equals(from),
+		db.ExchangeRate.QuoteCurrency.Equals(to),
+		db.ExchangeRate.EffectiveAt.BeforeEquals(at),
+	).OrderBy(
+		db.ExchangeRate.EffectiveAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	inverse, _ := dbClient.ExchangeRate.FindFirst(
+		db.ExchangeRate.BaseCurrency.Equals(to),
+		db.ExchangeRate.QuoteCurrency.Equals(from),
+		db.ExchangeRate.EffectiveAt.BeforeEquals(at),
+	).OrderBy(
+		db.ExchangeRate.EffectiveAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+
+	switch {
+	case direct != nil && (inverse == nil || !inverse.EffectiveAt.After(direct.EffectiveAt)):
+		return direct.Rate, nil
+	case inverse != nil:
+		return 1 / inverse.Rate, nil
+	default:
+		return 0, fmt.Errorf("no exchange rate from %s to %s", from, to)
+	}
+}
+
+func validatePriceList(priceListDto *model.PriceListModel) *data.WebResponse {
+	validator := helpers.RequestValidators(priceListDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	if priceListDto.ValidFrom != nil && priceListDto.ValidTo != nil && !priceListDto.ValidTo.After(*priceListDto.ValidFrom) {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "validTo must be after validFrom",
+			Data:    nil,
+		}
+	}
+	return nil
+}
+
+func priceListResponse(priceList db.PriceListModel, items []db.PriceListItemModel) model.PriceListResponse {
+	response := model.PriceListResponse{
+		Id:        priceList.ID,
+		Name:      priceList.Name,
+		Currency:  priceList.Currency,
+		Priority:  priceList.Priority,
+		Active:    priceList.Active,
+		CreatedAt: priceList.CreatedAt,
+	}
+	if group, ok := priceList.CustomerGroup(); ok {
+		value := string(group)
+		response.CustomerGroup = &value
+	}
+	if validFrom, ok := priceList.ValidFrom(); ok {
+		response.ValidFrom = &validFrom
+	}
+	if validTo, ok := priceList.ValidTo(); ok {
+		response.ValidTo = &validTo
+	}
+	for _, item := range items {
+		itemResponse := model.PriceListItemResponse{
+			ProductId:   item.ProductID,
+			MinQuantity: item.MinQuantity,
+			Price:       item.Price,
+		}
+		if variantId, ok := item.VariantID(); ok {
+			itemResponse.VariantId = &variantId
+		}
+		response.Items = append(response.Items, itemResponse)
+	}
+	return response
+}
+
+func customerGroup(group string) *db.CustomerGroup {
+	if group == "" {
+		return nil
+	}
+	value := db.CustomerGroup(group)
+	return &value
+}
+
+// baseCurrency is the currency product prices are kept in, from BASE_CURRENCY (default USD).
+func baseCurrency() string {
+	if currency := os.Getenv("BASE_CURRENCY"); currency != "" {
+		return currency
+	}
+	return "USD"
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"Enterprise/model"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestValidatePriceList(t *testing.T) {
+	from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	tests := []struct {
+		name      string
+		priceList model.PriceListModel
+		want      int // Response code, 0 when valid
+	}{
+		{name: "open ended", priceList: model.PriceListModel{Name: "Wholesale", Currency: "EUR"}},
+		{name: "valid window", priceList: model.PriceListModel{Name: "Wholesale", Currency: "EUR", ValidFrom: &from, ValidTo: &to}},
+		{name: "window ends before it starts", priceList: model.PriceListModel{Name: "Wholesale", Currency: "EUR", ValidFrom: &to, ValidTo: &from}, want: http.StatusBadRequest},
+		{name: "empty window", priceList: model.PriceListModel{Name: "Wholesale", Currency: "EUR", ValidFrom: &from, ValidTo: &from}, want: http.StatusBadRequest},
+		{name: "lowercase currency", priceList: model.PriceListModel{Name: "Wholesale", Currency: "eur"}, want: http.StatusBadRequest},
+		{name: "unknown customer group", priceList: model.PriceListModel{Name: "Wholesale", Currency: "EUR", CustomerGroup: "VIP"}, want: http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := validatePriceList(&test.priceList)
+			code := 0
+			if response != nil {
+				code = response.Code
+			}
+			if code != test.want {
+				t.Errorf("validatePriceList() answers %d, want %d", code, test.want)
+			}
+		})
+	}
+}
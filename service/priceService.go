@@ -0,0 +1,278 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/context"
+	"net/http"
+	"time"
+)
+
+type PriceService struct {
+	Db    *db.PrismaClient
+	Cache *cache.Cache
+}
+
+func NewPriceService(db *db.PrismaClient, catalogCache *cache.Cache) *PriceService {
+	return &PriceService{Db: db, Cache: catalogCache}
+}
+
+func (p *PriceService) SchedulePriceChange(ctx context.Context, priceDto *model.PriceChangeModel) *data.WebResponse {
+	validator := helpers.RequestValidators(priceDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	if !priceDto.EffectiveAt.After(time.Now()) {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "effectiveAt must be in the future, use the product update to change the price now",
+			Data:    nil,
+		}
+	}
+
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(priceDto.ProductId), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+
+	priceChange, err := p.Db.PriceChange.CreateOne(
+		db.PriceChange.Product.Link(db.Product.ID.Equals(priceDto.ProductId)),
+		db.PriceChange.NewPrice.Set(float64(priceDto.Price)),
+		db.PriceChange.Reason.Set(priceDto.Reason),
+		db.PriceChange.EffectiveAt.Set(priceDto.EffectiveAt),
+		db.PriceChange.User.Link(db.User.ID.Equals(priceDto.UserId)),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, priceDto.UserId, "Price Change Scheduled", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Price change scheduled",
+		Data: struct {
+			Id int `json:"id"`
+		}{
+			Id: priceChange.ID,
+		},
+	}
+}
+
+func (p *PriceService) CancelPriceChange(ctx context.Context, priceChangeId int, userId int) *data.WebResponse {
+	result, err := p.Db.PriceChange.FindMany(
+		db.PriceChange.ID.Equals(priceChangeId),
+		db.PriceChange.AppliedAt.IsNull(),
+	).Delete().Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if result.Count == 0 {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Scheduled price change not found or already applied",
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Price Change Cancelled", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Price change cancelled",
+		Data:    nil,
+	}
+}
+
+func (p *PriceService) GetPriceHistory(ctx context.Context, productId int) *data.WebResponse {
+	changes, err := p.Db.PriceChange.FindMany(db.PriceChange.ProductID.Equals(productId)).OrderBy(
+		db.PriceChange.EffectiveAt.Order(db.SortOrderDesc),
+	).With(
+		db.PriceChange.User.Fetch(),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var PriceChangeResponses []model.PriceChangeResponse
+	for _, change := range changes {
+		response := model.PriceChangeResponse{
+			Id:          change.ID,
+			ProductId:   change.ProductID,
+			NewPrice:    float32(change.NewPrice),
+			Reason:      change.Reason,
+			Status:      "SCHEDULED",
+			EffectiveAt: change.EffectiveAt,
+			CreatedAt:   change.CreatedAt,
+		}
+		if oldPrice, ok := change.OldPrice(); ok {
+			price := float32(oldPrice)
+			response.OldPrice = &price
+		}
+		if appliedAt, ok := change.AppliedAt(); ok {
+			response.AppliedAt = &appliedAt
+			response.Status = "APPLIED"
+		}
+		response.ChangedBy.Email = change.User().Email
+		response.ChangedBy.FirstName = change.User().FirstName
+		PriceChangeResponses = append(PriceChangeResponses, response)
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Price history",
+		Data:    PriceChangeResponses,
+	}
+}
+
+// GetEffectivePrice returns the price a product had (or is scheduled to have) at the given time.
+func (p *PriceService) GetEffectivePrice(ctx context.Context, productId int, at time.Time) *data.WebResponse {
+	productExist, _ := p.Db.Product.FindUnique(db.Product.ID.Equals(productId)).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+
+	response := model.EffectivePriceResponse{
+		ProductId: productId,
+		At:        at,
+		Price:     float32(productExist.Price),
+	}
+	change, _ := p.Db.PriceChange.FindFirst(
+		db.PriceChange.ProductID.Equals(productId),
+		db.PriceChange.EffectiveAt.BeforeEquals(at),
+	).OrderBy(
+		db.PriceChange.EffectiveAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if change != nil {
+		response.Price = float32(change.NewPrice)
+		response.PriceChangeId = &change.ID
+	} else {
+		// Before the first recorded change the product carried that change's old price.
+		first, _ := p.Db.PriceChange.FindFirst(db.PriceChange.ProductID.Equals(productId)).OrderBy(
+			db.PriceChange.EffectiveAt.Order(db.SortOrderAsc),
+		).Exec(ctx)
+		if first == nil {
+			response.Price = float32(productExist.Price)
+		} else if oldPrice, ok := first.OldPrice(); ok {
+			response.Price = float32(oldPrice)
+		} else {
+			return &data.WebResponse{
+				Code:    http.StatusNotFound,
+				Message: "Product had no price at the requested time",
+				Data:    nil,
+			}
+		}
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Effective price",
+		Data:    response,
+	}
+}
+
+// ApplyDuePriceChanges moves every scheduled price change whose effective time has passed onto its product.
+func (p *PriceService) ApplyDuePriceChanges(ctx context.Context) error {
+	dueChanges, err := p.Db.PriceChange.FindMany(
+		db.PriceChange.AppliedAt.IsNull(),
+		db.PriceChange.EffectiveAt.BeforeEquals(time.Now()),
+	).OrderBy(
+		db.PriceChange.EffectiveAt.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	applied := 0
+	for _, change := range dueChanges {
+		// The change is claimed, the product's price read and replaced in one statement, with the product locked,
+		// so a concurrent scheduler or price edit can neither apply it twice nor record a stale old price.
+		result, err := p.Db.Prisma.ExecuteRaw(
+			`WITH product AS (
+				SELECT p."id", p."price"
+				FROM "Product" p
+				JOIN "PriceChange" c ON c."productId" = p."id"
+				WHERE c."id" = $1 AND c."appliedAt" IS NULL
+				FOR UPDATE OF p
+			), claimed AS (
+				UPDATE "PriceChange" c SET "oldPrice" = product."price", "appliedAt" = CURRENT_TIMESTAMP
+				FROM product
+				WHERE c."id" = $1 AND c."appliedAt" IS NULL
+				RETURNING c."productId", c."newPrice"
+			)
+			UPDATE "Product" p SET "price" = claimed."newPrice", "updatedAt" = CURRENT_TIMESTAMP
+			FROM claimed
+			WHERE p."id" = claimed."productId"`,
+			change.ID,
+		).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if result.Count == 0 {
+			continue
+		}
+		applied++
+		log.Info().Msgf("Applied scheduled price change %d to product %d", change.ID, change.ProductID)
+	}
+	if applied > 0 {
+		p.Cache.Invalidate(ctx, cache.Products)
+	}
+	return nil
+}
+
+// RunPriceScheduler applies due price changes every interval until the context is cancelled.
+func (p *PriceService) RunPriceScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := p.ApplyDuePriceChanges(ctx); err != nil {
+			log.Error().Err(err).Msg("Applying scheduled price changes failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,21 +1,32 @@
 package service
 
 import (
+	"Enterprise/cache"
+	"Enterprise/config"
 	"Enterprise/data"
 	"Enterprise/helpers"
+	"Enterprise/labels"
 	"Enterprise/model"
 	"Enterprise/prisma/db"
 	"Enterprise/repository"
+	"Enterprise/storage"
+	"encoding/json"
 	"golang.org/x/net/context"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ProductService struct {
-	Db *db.PrismaClient
+	Db      *db.PrismaClient
+	Storage storage.Storage
+	Cache   *cache.Cache
 }
 
-func NewProductService(db *db.PrismaClient) *ProductService {
-	return &ProductService{Db: db}
+func NewProductService(db *db.PrismaClient, store storage.Storage, catalogCache *cache.Cache) *ProductService {
+	return &ProductService{Db: db, Storage: store, Cache: catalogCache}
 }
 
 func (p *ProductService) CreateProduct(ctx context.Context, productDto *model.ProductModel) *data.WebResponse {
@@ -36,14 +47,49 @@ func (p *ProductService) CreateProduct(ctx context.Context, productDto *model.Pr
 			Data:    nil,
 		}
 	}
-	product, err := p.Db.Product.CreateOne(
-		db.Product.Name.Set(productDto.Name),
-		db.Product.Price.Set(float64(productDto.Price)),
-		db.Product.Stock.Set(productDto.Stock),
-		db.Product.Description.Set(productDto.Description),
-		db.Product.User.Link(db.User.ID.Equals(productDto.UserId)),
-		//db.Product.Categories.Link(db.Category.And(db.Category.ID.In(productDto.CategoryId))),
-	).Exec(ctx)
+	if response := p.checkBarcode(ctx, productDto.Barcode, 0); response != nil {
+		return response
+	}
+	// Opening stock is booked into a warehouse like any other stock.
+	if productDto.Stock > 0 {
+		if productDto.WarehouseId == nil {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: "warehouseId is required when stock is given",
+				Data:    nil,
+			}
+		}
+		if response := checkWarehouse(ctx, p.Db, *productDto.WarehouseId); response != nil {
+			return response
+		}
+	}
+	attributes, err := validateProductAttributes(ctx, p.Db, productDto.CategoryId, productDto.Attributes)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	categoryIds := uniqueIds(productDto.CategoryId)
+	if len(categoryIds) > 0 {
+		categories, err := p.Db.Category.FindMany(db.Category.ID.In(categoryIds)).Exec(ctx)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		if len(categories) != len(categoryIds) {
+			return &data.WebResponse{
+				Code:    http.StatusNotFound,
+				Message: "Category not found",
+				Data:    nil,
+			}
+		}
+	}
+	productId, err := repository.NextId(ctx, p.Db, "Product")
 	if err != nil {
 		return &data.WebResponse{
 			Code:    http.StatusInternalServerError,
@@ -52,12 +98,44 @@ func (p *ProductService) CreateProduct(ctx context.Context, productDto *model.Pr
 		}
 	}
 
-	for _, categoryId := range productDto.CategoryId {
-		_, err = p.Db.ProductOnCategory.CreateOne(
-			db.ProductOnCategory.Product.Link(db.Product.ID.Equals(product.ID)),
-			db.ProductOnCategory.Category.Link(db.Category.ID.Equals(categoryId))).Exec(ctx)
+	// The product, its categories, its first price and its opening stock are written together.
+	attributesJson, _ := json.Marshal(attributes)
+	price := float64(productDto.Price)
+	transactions := []db.PrismaTransaction{
+		p.Db.Product.CreateOne(
+			db.Product.Name.Set(productDto.Name),
+			db.Product.Price.Set(price),
+			db.Product.Stock.Set(0),
+			db.Product.Description.Set(productDto.Description),
+			db.Product.User.Link(db.User.ID.Equals(productDto.UserId)),
+			db.Product.ID.Set(productId),
+			db.Product.Attributes.Set(attributesJson),
+			db.Product.Barcode.SetIfPresent(optionalString(productDto.Barcode)),
+			db.Product.LotTracked.SetIfPresent(productDto.LotTracked),
+			db.Product.StandardCost.SetIfPresent(productDto.StandardCost),
+		).Tx(),
+	}
+	for _, categoryId := range categoryIds {
+		transactions = append(transactions, p.Db.ProductOnCategory.CreateOne(
+			db.ProductOnCategory.Product.Link(db.Product.ID.Equals(productId)),
+			db.ProductOnCategory.Category.Link(db.Category.ID.Equals(categoryId)),
+		).Tx())
+	}
+	transactions = append(transactions, repository.AppliedPriceChange(p.Db, productId, nil, price, "Initial price", productDto.UserId))
+	if productDto.Stock > 0 {
+		transactions = append(transactions, repository.StockMovement(p.Db, productId, nil, *productDto.WarehouseId, db.StockMovementTypeReceipt, productDto.Stock, "Opening stock", nil, &productDto.UserId, nil)...)
+	}
+	err = p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
 	}
 
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
 	err = repository.AuditLogs(ctx, p.Db, productDto.UserId, "Product Created", "This action was performed by ")
 	if err != nil {
 		return &data.WebResponse{
@@ -82,20 +160,78 @@ func (p *ProductService) UpdateProduct(ctx context.Context, productDto *model.Pr
 			Data:    validator.Error(),
 		}
 	}
-	existingProduct := repository.ExistingProductByName(ctx, p.Db, productDto.Name)
-	if existingProduct {
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(productDto.ProductId), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+	if response := helpers.CheckETag(productDto.IfMatch, productExist.UpdatedAt, "Product"); response != nil {
+		return response
+	}
+	if productExist.Name != productDto.Name && repository.ExistingProductByName(ctx, p.Db, productDto.Name) {
 		return &data.WebResponse{
 			Code:    http.StatusBadRequest,
 			Message: "Product already exists",
 			Data:    nil,
 		}
 	}
-	_, err := p.Db.Product.FindUnique(db.Product.ID.Equals(productDto.ProductId)).Update(
-		db.Product.Stock.Set(productDto.Stock),
+	if response := p.checkBarcode(ctx, productDto.Barcode, productDto.ProductId); response != nil {
+		return response
+	}
+
+	// Stock is not set here, it is the total of the product's warehouse inventory.
+	updates := []db.ProductSetParam{
 		db.Product.Name.Set(productDto.Name),
 		db.Product.Description.Set(productDto.Description),
 		db.Product.Price.Set(float64(productDto.Price)),
-	).Exec(ctx)
+	}
+	if productDto.Barcode != "" {
+		updates = append(updates, db.Product.Barcode.Set(productDto.Barcode))
+	}
+	if productDto.LotTracked != nil {
+		updates = append(updates, db.Product.LotTracked.Set(*productDto.LotTracked))
+	}
+	if productDto.StandardCost != nil {
+		updates = append(updates, db.Product.StandardCost.Set(*productDto.StandardCost))
+	}
+	// Attributes are only replaced when the request carries them.
+	if productDto.Attributes != nil {
+		var categoryIds []int
+		links, _ := p.Db.ProductOnCategory.FindMany(db.ProductOnCategory.ProductID.Equals(productDto.ProductId)).Exec(ctx)
+		for _, link := range links {
+			categoryIds = append(categoryIds, link.CategoryID)
+		}
+		attributes, err := validateProductAttributes(ctx, p.Db, categoryIds, productDto.Attributes)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		attributesJson, _ := json.Marshal(attributes)
+		updates = append(updates, db.Product.Attributes.Set(attributesJson))
+	}
+
+	// The price history entry is written under the same updatedAt guard as the update, so a rejected update
+	// leaves no change behind.
+	var transactions []db.PrismaTransaction
+	if newPrice := float64(productDto.Price); newPrice != productExist.Price {
+		reason := productDto.PriceReason
+		if reason == "" {
+			reason = "Product updated"
+		}
+		transactions = append(transactions, repository.GuardedPriceChange(p.Db, productDto.ProductId, productExist.UpdatedAt, productExist.Price, newPrice, reason, productDto.UserId))
+	}
+	productUpdate := p.Db.Product.FindMany(
+		db.Product.ID.Equals(productDto.ProductId),
+		db.Product.UpdatedAt.Equals(productExist.UpdatedAt),
+	).Update(updates...).Tx()
+	transactions = append(transactions, productUpdate)
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
 	if err != nil {
 		return &data.WebResponse{
 			Code:    http.StatusInternalServerError,
@@ -103,6 +239,11 @@ func (p *ProductService) UpdateProduct(ctx context.Context, productDto *model.Pr
 			Data:    nil,
 		}
 	}
+	if productUpdate.Result().Count == 0 {
+		return helpers.PreconditionFailed("Product")
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
 	return &data.WebResponse{
 		Code:    http.StatusOK,
 		Message: "Product updated",
@@ -111,7 +252,19 @@ func (p *ProductService) UpdateProduct(ctx context.Context, productDto *model.Pr
 }
 
 func (p *ProductService) GetProductById(ctx context.Context, productId int) *data.WebResponse {
-	productExist, _ := p.Db.Product.FindUnique(db.Product.ID.Equals(productId)).Exec(ctx)
+	return p.Cache.Remember(ctx, cache.Products, "product:"+strconv.Itoa(productId), func() *data.WebResponse {
+		return p.getProductById(ctx, productId)
+	})
+}
+
+func (p *ProductService) getProductById(ctx context.Context, productId int) *data.WebResponse {
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(productId), db.Product.DeletedAt.IsNull()).With(
+		db.Product.Options.Fetch(),
+		db.Product.Variants.Fetch(),
+		db.Product.Attachments.Fetch(),
+		db.Product.BundleComponents.Fetch().With(db.BundleComponent.Component.Fetch().With(db.Product.Inventory.Fetch())),
+		db.Product.Inventory.Fetch().With(db.Inventory.Warehouse.Fetch()),
+	).Exec(ctx)
 	if productExist == nil {
 		return &data.WebResponse{
 			Code:    http.StatusNotFound,
@@ -121,22 +274,94 @@ func (p *ProductService) GetProductById(ctx context.Context, productId int) *dat
 	}
 
 	description, _ := productExist.Description()
+	barcode, _ := productExist.Barcode()
+	var standardCost *float64
+	if cost, ok := productExist.StandardCost(); ok {
+		standardCost = &cost
+	}
+	// The attachment URLs expire, so the ETag has to move on before a revalidated copy ends up with dead links.
+	etag := helpers.ETag(productExist.UpdatedAt)
+	if len(productExist.Attachments()) > 0 {
+		etag = helpers.ExpiringETag(productExist.UpdatedAt, config.AttachmentURLExpiry())
+	}
 	return &data.WebResponse{
 		Code:    http.StatusOK,
 		Message: "Product found",
 		Data: model.ProductResponse{
-			Id:          productId,
-			Name:        productExist.Name,
-			Description: description,
-			Price:       float32(productExist.Price),
-			Stock:       productExist.Stock,
-			CreatedAt:   productExist.CreatedAt,
+			Id:           productId,
+			Name:         productExist.Name,
+			Description:  description,
+			Type:         string(productExist.Type),
+			Barcode:      barcode,
+			LotTracked:   productExist.LotTracked,
+			StandardCost: standardCost,
+			Price:        float32(productPrice(*productExist, productExist.BundleComponents())),
+			Stock:        productStock(*productExist, productExist.BundleComponents()),
+			Available:    productAvailable(*productExist, productExist.BundleComponents()),
+			Inventory:    inventoryResponses(productExist.Inventory()),
+			CreatedAt:    productExist.CreatedAt,
+			Attributes:   decodeProductAttributes(productExist.Attributes),
+			Options:      optionResponses(productExist.Options()),
+			Variants:     variantResponses(productExist.Price, productExist.Variants()),
+			Attachments:  attachmentResponses(ctx, p.Storage, productExist.Attachments()),
 		},
+		ETag: etag,
+	}
+}
+
+// GetAllProducts lists active products, optionally only those whose attributes match every filter.
+func (p *ProductService) GetAllProducts(ctx context.Context, filters map[string]string) *data.WebResponse {
+	key := "all"
+	if len(filters) > 0 {
+		query := url.Values{}
+		for name, value := range filters {
+			query.Set(strings.ToLower(name), value)
+		}
+		key += "?" + query.Encode()
 	}
+	return p.Cache.Remember(ctx, cache.Products, key, func() *data.WebResponse {
+		return p.getAllProducts(ctx, filters)
+	})
 }
 
-func (p *ProductService) GetAllProducts(ctx context.Context) *data.WebResponse {
-	products, _err := p.Db.Product.FindMany().Exec(ctx)
+func (p *ProductService) getAllProducts(ctx context.Context, filters map[string]string) *data.WebResponse {
+	params := []db.ProductWhereParam{db.Product.DeletedAt.IsNull()}
+	if len(filters) > 0 {
+		filter, err := attributeFilter(ctx, p.Db, filters)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		var matches []struct {
+			ID int `json:"id"`
+		}
+		err = p.Db.Prisma.QueryRaw(
+			`SELECT "id" FROM "Product" WHERE "deletedAt" IS NULL AND "attributes" @> $1::jsonb`, string(filter),
+		).Exec(ctx, &matches)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		productIds := make([]int, 0, len(matches))
+		for _, match := range matches {
+			productIds = append(productIds, match.ID)
+		}
+		params = append(params, db.Product.ID.In(productIds))
+	}
+
+	products, _err := p.Db.Product.FindMany(params...).With(
+		db.Product.Options.Fetch(),
+		db.Product.Variants.Fetch(),
+		db.Product.Attachments.Fetch(),
+		db.Product.BundleComponents.Fetch().With(db.BundleComponent.Component.Fetch().With(db.Product.Inventory.Fetch())),
+		db.Product.Inventory.Fetch(),
+	).Exec(ctx)
 	if _err != nil {
 		return &data.WebResponse{
 			Code:    http.StatusInternalServerError,
@@ -147,13 +372,27 @@ func (p *ProductService) GetAllProducts(ctx context.Context) *data.WebResponse {
 	var ProductResponses []model.ProductResponse
 	for _, product := range products {
 		description, _ := product.Description()
+		barcode, _ := product.Barcode()
+		var standardCost *float64
+		if cost, ok := product.StandardCost(); ok {
+			standardCost = &cost
+		}
 		ProductResponses = append(ProductResponses, model.ProductResponse{
-			Id:          product.ID,
-			Name:        product.Name,
-			Description: description,
-			Price:       float32(product.Price),
-			Stock:       product.Stock,
-			CreatedAt:   product.CreatedAt,
+			Id:           product.ID,
+			Name:         product.Name,
+			Description:  description,
+			Type:         string(product.Type),
+			Barcode:      barcode,
+			LotTracked:   product.LotTracked,
+			StandardCost: standardCost,
+			Price:        float32(productPrice(product, product.BundleComponents())),
+			Stock:        productStock(product, product.BundleComponents()),
+			Available:    productAvailable(product, product.BundleComponents()),
+			CreatedAt:    product.CreatedAt,
+			Attributes:   decodeProductAttributes(product.Attributes),
+			Options:      optionResponses(product.Options()),
+			Variants:     variantResponses(product.Price, product.Variants()),
+			Attachments:  attachmentResponses(ctx, p.Storage, product.Attachments()),
 		})
 	}
 
@@ -164,9 +403,9 @@ func (p *ProductService) GetAllProducts(ctx context.Context) *data.WebResponse {
 	}
 }
 
-// DeleteProductById TODO fix cascading issue
-func (p *ProductService) DeleteProductById(ctx context.Context, productId int, userId int) *data.WebResponse {
-	productExist, _ := p.Db.Product.FindUnique(db.Product.ID.Equals(productId)).Exec(ctx)
+// DeleteProductById archives the product; order items and inventory keep pointing at the row.
+func (p *ProductService) DeleteProductById(ctx context.Context, productId int, userId int, ifMatch string) *data.WebResponse {
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(productId), db.Product.DeletedAt.IsNull()).Exec(ctx)
 	if productExist == nil {
 		return &data.WebResponse{
 			Code:    http.StatusNotFound,
@@ -174,8 +413,23 @@ func (p *ProductService) DeleteProductById(ctx context.Context, productId int, u
 			Data:    nil,
 		}
 	}
+	if response := helpers.CheckETag(ifMatch, productExist.UpdatedAt, "Product"); response != nil {
+		return response
+	}
+	if repository.ProductIsBundleComponent(ctx, p.Db, productId) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Product is a component of a bundle, remove it from the bundle first",
+			Data:    nil,
+		}
+	}
 
-	_, err := p.Db.Product.FindUnique(db.Product.ID.Equals(productId)).Delete().Exec(ctx)
+	result, err := p.Db.Product.FindMany(
+		db.Product.ID.Equals(productId),
+		db.Product.UpdatedAt.Equals(productExist.UpdatedAt),
+	).Update(
+		db.Product.DeletedAt.Set(time.Now()),
+	).Exec(ctx)
 	if err != nil {
 		return &data.WebResponse{
 			Code:    http.StatusInternalServerError,
@@ -183,6 +437,11 @@ func (p *ProductService) DeleteProductById(ctx context.Context, productId int, u
 			Data:    nil,
 		}
 	}
+	if result.Count == 0 {
+		return helpers.PreconditionFailed("Product")
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
 	err = repository.AuditLogs(ctx, p.Db, userId, "Product Deleted", "This action was performed by ")
 	if err != nil {
 		return &data.WebResponse{
@@ -199,6 +458,7 @@ func (p *ProductService) DeleteProductById(ctx context.Context, productId int, u
 	}
 }
 
+// UpdateProductStock sets how much of a product one warehouse holds, recording the change in the stock ledger.
 func (p *ProductService) UpdateProductStock(ctx context.Context, productStock *model.ProductStock) *data.WebResponse {
 	validator := helpers.RequestValidators(productStock)
 	if validator != nil {
@@ -209,9 +469,86 @@ func (p *ProductService) UpdateProductStock(ctx context.Context, productStock *m
 		}
 	}
 
-	_, err := p.Db.Product.FindUnique(db.Product.ID.Equals(productStock.ProductId)).Update(
-		db.Product.Stock.Set(productStock.Stock),
-	).Exec(ctx)
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(productStock.ProductId), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+	if response := helpers.CheckETag(productStock.IfMatch, productExist.UpdatedAt, "Product"); response != nil {
+		return response
+	}
+	if productExist.Type == db.ProductTypeBundle {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Bundle stock is derived from its components, update the components instead",
+			Data:    nil,
+		}
+	}
+	if response := checkWarehouse(ctx, p.Db, productStock.WarehouseId); response != nil {
+		return response
+	}
+	if response := checkVariant(ctx, p.Db, productStock.ProductId, productStock.VariantId); response != nil {
+		return response
+	}
+
+	// The new count is booked as an adjustment for the difference, the ledger stays the source of the quantity.
+	current := 0
+	inventory, _ := p.Db.Inventory.FindFirst(repository.InventoryOf(productStock.ProductId, productStock.VariantId, productStock.WarehouseId)...).Exec(ctx)
+	if inventory != nil {
+		current = inventory.Quantity
+	}
+	reason := productStock.Reason
+	if reason == "" {
+		reason = "Stock updated"
+	}
+	// The guard fails the batch when another request changed the quantity since it was read.
+	transactions := []db.PrismaTransaction{
+		repository.EnsureInventory(p.Db, productStock.ProductId, productStock.VariantId, productStock.WarehouseId),
+		repository.InventoryGuard(p.Db, productStock.ProductId, productStock.VariantId, productStock.WarehouseId, current),
+	}
+	delta := productStock.Stock - current
+	if delta > 0 {
+		transactions = append(transactions, repository.StockMovement(p.Db, productStock.ProductId, productStock.VariantId, productStock.WarehouseId, db.StockMovementTypeAdjustment, delta, reason, nil, &productStock.UserId, nil)...)
+	} else if delta < 0 {
+		outbound, _, err := repository.Outbound(ctx, p.Db, productStock.ProductId, productStock.VariantId, productStock.WarehouseId, db.StockMovementTypeAdjustment, -delta, reason, nil, &productStock.UserId, true)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		transactions = append(transactions, outbound...)
+	}
+	if productStock.Bin != nil {
+		transactions = append(transactions,
+			p.Db.Inventory.FindMany(repository.InventoryOf(productStock.ProductId, productStock.VariantId, productStock.WarehouseId)...).Update(
+				db.Inventory.Bin.Set(*productStock.Bin),
+			).Tx(),
+		)
+	}
+	if delta == 0 && productStock.Bin == nil {
+		return &data.WebResponse{
+			Code:    http.StatusOK,
+			Message: "Product stock unchanged",
+			Data:    nil,
+		}
+	}
+
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if repository.StaleInventory(err) {
+		return helpers.PreconditionFailed("Product stock")
+	}
+	if repository.InsufficientStock(err) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Stock is reserved or its lots changed concurrently, the warehouse would go below what it holds",
+			Data:    nil,
+		}
+	}
 	if err != nil {
 		return &data.WebResponse{
 			Code:    http.StatusInternalServerError,
@@ -219,7 +556,9 @@ func (p *ProductService) UpdateProductStock(ctx context.Context, productStock *m
 			Data:    nil,
 		}
 	}
-	err = repository.AuditLogs(ctx, p.Db, productStock.UserId, "Product Deleted", "This action was performed by ")
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, productStock.UserId, "Product Stock Updated", "This action was performed by ")
 	if err != nil {
 		return &data.WebResponse{
 			Code:    http.StatusInternalServerError,
@@ -234,3 +573,124 @@ func (p *ProductService) UpdateProductStock(ctx context.Context, productStock *m
 		Data:    nil,
 	}
 }
+
+func (p *ProductService) RestoreProduct(ctx context.Context, productId int, userId int) *data.WebResponse {
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(productId), db.Product.Not(db.Product.DeletedAt.IsNull())).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Deleted product not found",
+			Data:    nil,
+		}
+	}
+	if repository.ExistingProductByName(ctx, p.Db, productExist.Name) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "An active product with the same name already exists",
+			Data:    nil,
+		}
+	}
+
+	_, err := p.Db.Product.FindUnique(db.Product.ID.Equals(productId)).Update(
+		db.Product.DeletedAt.SetOptional(nil),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Product Restored", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Product restored",
+		Data:    nil,
+	}
+}
+
+func (p *ProductService) GetDeletedProducts(ctx context.Context) *data.WebResponse {
+	products, err := p.Db.Product.FindMany(db.Product.Not(db.Product.DeletedAt.IsNull())).OrderBy(
+		db.Product.DeletedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	var ProductResponses []model.ProductResponse
+	for _, product := range products {
+		description, _ := product.Description()
+		deletedAt, _ := product.DeletedAt()
+		ProductResponses = append(ProductResponses, model.ProductResponse{
+			Id:          product.ID,
+			Name:        product.Name,
+			Description: description,
+			Price:       float32(product.Price),
+			Stock:       product.Stock,
+			CreatedAt:   product.CreatedAt,
+			DeletedAt:   &deletedAt,
+		})
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Deleted products",
+		Data:    ProductResponses,
+	}
+}
+
+// checkBarcode validates the EAN-13 check digit and makes sure no other product already carries the barcode.
+func (p *ProductService) checkBarcode(ctx context.Context, barcode string, productId int) *data.WebResponse {
+	if barcode == "" {
+		return nil
+	}
+	if !labels.ValidEAN13(barcode) {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Barcode is not a valid EAN-13 code, check the last digit",
+			Data:    nil,
+		}
+	}
+	owner, _ := p.Db.Product.FindUnique(db.Product.Barcode.Equals(barcode)).Exec(ctx)
+	if owner != nil && owner.ID != productId {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Barcode is already used by another product",
+			Data:    nil,
+		}
+	}
+	return nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// uniqueIds drops repeated ids, keeping the first of each.
+func uniqueIds(ids []int) []int {
+	seen := map[int]bool{}
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
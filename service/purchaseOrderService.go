@@ -0,0 +1,581 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"fmt"
+	"golang.org/x/net/context"
+	"net/http"
+	"strings"
+)
+
+type PurchaseOrderService struct {
+	Db    *db.PrismaClient
+	Cache *cache.Cache
+}
+
+func NewPurchaseOrderService(db *db.PrismaClient, catalogCache *cache.Cache) *PurchaseOrderService {
+	return &PurchaseOrderService{Db: db, Cache: catalogCache}
+}
+
+func (p *PurchaseOrderService) CreatePurchaseOrder(ctx context.Context, purchaseOrderDto *model.PurchaseOrderModel) *data.WebResponse {
+	validator := helpers.RequestValidators(purchaseOrderDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	if response := p.checkPurchaseOrder(ctx, purchaseOrderDto); response != nil {
+		return response
+	}
+
+	purchaseOrderId, err := repository.NextId(ctx, p.Db, "PurchaseOrder")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	transactions := []db.PrismaTransaction{
+		p.Db.PurchaseOrder.CreateOne(
+			db.PurchaseOrder.Supplier.Link(db.Supplier.ID.Equals(purchaseOrderDto.SupplierId)),
+			db.PurchaseOrder.Warehouse.Link(db.Warehouse.ID.Equals(purchaseOrderDto.WarehouseId)),
+			db.PurchaseOrder.User.Link(db.User.ID.Equals(purchaseOrderDto.UserId)),
+			db.PurchaseOrder.ID.Set(purchaseOrderId),
+			db.PurchaseOrder.ExpectedAt.SetIfPresent(purchaseOrderDto.ExpectedAt),
+			db.PurchaseOrder.Note.SetIfPresent(optionalString(purchaseOrderDto.Note)),
+		).Tx(),
+	}
+	transactions = append(transactions, p.purchaseOrderItemWrites(purchaseOrderId, purchaseOrderDto.Items)...)
+	err = p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, purchaseOrderDto.UserId, "Purchase Order Created", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Purchase order created",
+		Data: struct {
+			Id int `json:"id"`
+		}{
+			Id: purchaseOrderId,
+		},
+	}
+}
+
+// UpdatePurchaseOrder replaces the supplier, warehouse, expected date, note and lines of a draft purchase order.
+func (p *PurchaseOrderService) UpdatePurchaseOrder(ctx context.Context, purchaseOrderDto *model.PurchaseOrderModel) *data.WebResponse {
+	validator := helpers.RequestValidators(purchaseOrderDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	purchaseOrder, _ := p.Db.PurchaseOrder.FindUnique(db.PurchaseOrder.ID.Equals(purchaseOrderDto.PurchaseOrderId)).Exec(ctx)
+	if purchaseOrder == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Purchase order not found",
+			Data:    nil,
+		}
+	}
+	if purchaseOrder.Status != db.PurchaseOrderStatusDraft {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Only draft purchase orders can be changed",
+			Data:    nil,
+		}
+	}
+	if response := p.checkPurchaseOrder(ctx, purchaseOrderDto); response != nil {
+		return response
+	}
+
+	transactions := []db.PrismaTransaction{
+		p.Db.PurchaseOrder.FindUnique(db.PurchaseOrder.ID.Equals(purchaseOrderDto.PurchaseOrderId)).Update(
+			db.PurchaseOrder.Supplier.Link(db.Supplier.ID.Equals(purchaseOrderDto.SupplierId)),
+			db.PurchaseOrder.Warehouse.Link(db.Warehouse.ID.Equals(purchaseOrderDto.WarehouseId)),
+			db.PurchaseOrder.ExpectedAt.SetOptional(purchaseOrderDto.ExpectedAt),
+			db.PurchaseOrder.Note.SetOptional(optionalString(purchaseOrderDto.Note)),
+		).Tx(),
+		p.Db.PurchaseOrderItem.FindMany(db.PurchaseOrderItem.PurchaseOrderID.Equals(purchaseOrderDto.PurchaseOrderId)).Delete().Tx(),
+	}
+	transactions = append(transactions, p.purchaseOrderItemWrites(purchaseOrderDto.PurchaseOrderId, purchaseOrderDto.Items)...)
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, purchaseOrderDto.UserId, "Purchase Order Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Purchase order updated",
+		Data:    nil,
+	}
+}
+
+// SendPurchaseOrder marks a draft purchase order as sent to its supplier, after which goods can be received
+// against it. Without an expected date one is taken from the supplier's lead time.
+func (p *PurchaseOrderService) SendPurchaseOrder(ctx context.Context, purchaseOrderId int, userId int) *data.WebResponse {
+	purchaseOrder, _ := p.Db.PurchaseOrder.FindUnique(db.PurchaseOrder.ID.Equals(purchaseOrderId)).With(
+		db.PurchaseOrder.Supplier.Fetch(),
+	).Exec(ctx)
+	if purchaseOrder == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Purchase order not found",
+			Data:    nil,
+		}
+	}
+	if !purchaseOrder.Supplier().Active {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Supplier is not active",
+			Data:    nil,
+		}
+	}
+	leadTimeDays, ok := purchaseOrder.Supplier().LeadTimeDays()
+	if !ok {
+		leadTimeDays = -1
+	}
+
+	result, err := p.Db.Prisma.ExecuteRaw(
+		`UPDATE "PurchaseOrder" SET "status" = 'SENT', "sentAt" = CURRENT_TIMESTAMP, "updatedAt" = CURRENT_TIMESTAMP,
+			"expectedAt" = COALESCE("expectedAt", CASE WHEN $2 >= 0 THEN CURRENT_TIMESTAMP + $2 * INTERVAL '1 day' END)
+		WHERE "id" = $1 AND "status" = 'DRAFT'`,
+		purchaseOrderId, leadTimeDays,
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if result.Count == 0 {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Only draft purchase orders can be sent",
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Purchase Order Sent", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Purchase order sent",
+		Data:    nil,
+	}
+}
+
+// ReceiveGoods books a delivery against a sent purchase order. Each line is stocked into the order's warehouse
+// through the stock ledger and counted against what was ordered. The order is received once every line arrived
+// in full, partially received before that.
+func (p *PurchaseOrderService) ReceiveGoods(ctx context.Context, receiptDto *model.GoodsReceiptModel) *data.WebResponse {
+	validator := helpers.RequestValidators(receiptDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	purchaseOrder, _ := p.Db.PurchaseOrder.FindUnique(db.PurchaseOrder.ID.Equals(receiptDto.PurchaseOrderId)).With(
+		db.PurchaseOrder.Items.Fetch().With(db.PurchaseOrderItem.Product.Fetch()),
+	).Exec(ctx)
+	if purchaseOrder == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Purchase order not found",
+			Data:    nil,
+		}
+	}
+	if purchaseOrder.Status != db.PurchaseOrderStatusSent && purchaseOrder.Status != db.PurchaseOrderStatusPartiallyReceived {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Only sent purchase orders can be received",
+			Data:    nil,
+		}
+	}
+	if response := checkWarehouse(ctx, p.Db, purchaseOrder.WarehouseID); response != nil {
+		return response
+	}
+
+	items := map[int]db.PurchaseOrderItemModel{}
+	for _, item := range purchaseOrder.Items() {
+		items[item.ProductID] = item
+	}
+	reference := purchaseOrderReference(purchaseOrder.ID)
+	var transactions []db.PrismaTransaction
+	seen := map[int]bool{}
+	for _, line := range receiptDto.Items {
+		item, ok := items[line.ProductId]
+		if !ok {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Product %d is not part of this purchase order", line.ProductId),
+				Data:    nil,
+			}
+		}
+		if seen[line.ProductId] {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Product %d is listed more than once", line.ProductId),
+				Data:    nil,
+			}
+		}
+		seen[line.ProductId] = true
+		outstanding := item.Quantity - item.ReceivedQuantity
+		if line.Quantity > outstanding {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Product %d has %d units outstanding", line.ProductId, outstanding),
+				Data:    nil,
+			}
+		}
+
+		lot, response := lotDetails(item.Product().LotTracked, true, line.LotNumber, line.ManufacturedAt, line.ExpiresAt)
+		if response != nil {
+			return response
+		}
+
+		transactions = append(transactions, p.Db.PurchaseOrderItem.FindUnique(db.PurchaseOrderItem.ID.Equals(item.ID)).Update(
+			db.PurchaseOrderItem.ReceivedQuantity.Increment(line.Quantity),
+		).Tx())
+		transactions = append(transactions, p.Db.GoodsReceipt.CreateOne(
+			db.GoodsReceipt.PurchaseOrder.Link(db.PurchaseOrder.ID.Equals(purchaseOrder.ID)),
+			db.GoodsReceipt.Product.Link(db.Product.ID.Equals(line.ProductId)),
+			db.GoodsReceipt.Quantity.Set(line.Quantity),
+			db.GoodsReceipt.UnitCost.Set(item.UnitCost),
+			db.GoodsReceipt.User.Link(db.User.ID.Equals(receiptDto.UserId)),
+			db.GoodsReceipt.Reference.SetIfPresent(optionalString(receiptDto.Reference)),
+			db.GoodsReceipt.Note.SetIfPresent(optionalString(line.Note)),
+			db.GoodsReceipt.LotNumber.SetIfPresent(optionalString(line.LotNumber)),
+		).Tx())
+		transactions = append(transactions, repository.LotStockMovement(p.Db, line.ProductId, purchaseOrder.WarehouseID, lot, db.StockMovementTypeReceipt, line.Quantity, "Goods received", &reference, &receiptDto.UserId, &item.UnitCost)...)
+	}
+	transactions = append(transactions,
+		p.Db.Prisma.ExecuteRaw(
+			`UPDATE "PurchaseOrder" SET "status" = 'PARTIALLY_RECEIVED', "updatedAt" = CURRENT_TIMESTAMP
+			WHERE "id" = $1 AND "status" IN ('SENT', 'PARTIALLY_RECEIVED')`,
+			purchaseOrder.ID,
+		).Tx(),
+		p.Db.Prisma.ExecuteRaw(
+			`UPDATE "PurchaseOrder" SET "status" = 'RECEIVED', "receivedAt" = CURRENT_TIMESTAMP, "updatedAt" = CURRENT_TIMESTAMP
+			WHERE "id" = $1 AND "status" = 'PARTIALLY_RECEIVED' AND NOT EXISTS (
+				SELECT 1 FROM "PurchaseOrderItem" WHERE "purchaseOrderId" = $1 AND "receivedQuantity" < "quantity"
+			)`,
+			purchaseOrder.ID,
+		).Tx(),
+	)
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if response := purchaseOrderWriteError(err); response != nil {
+		return response
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, receiptDto.UserId, "Goods Received", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Goods receipt recorded",
+		Data:    nil,
+	}
+}
+
+// ClosePurchaseOrder ends a purchase order, whatever is still outstanding will not be received anymore.
+func (p *PurchaseOrderService) ClosePurchaseOrder(ctx context.Context, purchaseOrderId int, userId int) *data.WebResponse {
+	purchaseOrder, _ := p.Db.PurchaseOrder.FindUnique(db.PurchaseOrder.ID.Equals(purchaseOrderId)).Exec(ctx)
+	if purchaseOrder == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Purchase order not found",
+			Data:    nil,
+		}
+	}
+	result, err := p.Db.Prisma.ExecuteRaw(
+		`UPDATE "PurchaseOrder" SET "status" = 'CLOSED', "closedAt" = CURRENT_TIMESTAMP, "updatedAt" = CURRENT_TIMESTAMP WHERE "id" = $1 AND "status" <> 'CLOSED'`,
+		purchaseOrderId,
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if result.Count == 0 {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Purchase order is already closed",
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Purchase Order Closed", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Purchase order closed",
+		Data:    nil,
+	}
+}
+
+func (p *PurchaseOrderService) GetPurchaseOrderById(ctx context.Context, purchaseOrderId int) *data.WebResponse {
+	purchaseOrder, _ := p.Db.PurchaseOrder.FindUnique(db.PurchaseOrder.ID.Equals(purchaseOrderId)).With(
+		db.PurchaseOrder.Items.Fetch().With(db.PurchaseOrderItem.Product.Fetch()),
+		db.PurchaseOrder.Receipts.Fetch().OrderBy(db.GoodsReceipt.CreatedAt.Order(db.SortOrderAsc)),
+	).Exec(ctx)
+	if purchaseOrder == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Purchase order not found",
+			Data:    nil,
+		}
+	}
+
+	response := purchaseOrderResponse(*purchaseOrder)
+	for _, item := range purchaseOrder.Items() {
+		response.Items = append(response.Items, model.PurchaseOrderItemResponse{
+			ProductId:   item.ProductID,
+			ProductName: item.Product().Name,
+			Ordered:     item.Quantity,
+			Received:    item.ReceivedQuantity,
+			Outstanding: item.Quantity - item.ReceivedQuantity,
+			UnitCost:    item.UnitCost,
+			Total:       roundMoney(item.UnitCost * float64(item.Quantity)),
+		})
+	}
+	for _, receipt := range purchaseOrder.Receipts() {
+		reference, _ := receipt.Reference()
+		note, _ := receipt.Note()
+		lotNumber, _ := receipt.LotNumber()
+		response.Receipts = append(response.Receipts, model.GoodsReceiptResponse{
+			Id:        receipt.ID,
+			ProductId: receipt.ProductID,
+			Quantity:  receipt.Quantity,
+			UnitCost:  receipt.UnitCost,
+			LotNumber: lotNumber,
+			Reference: reference,
+			Note:      note,
+			UserId:    receipt.UserID,
+			CreatedAt: receipt.CreatedAt,
+		})
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Purchase order found",
+		Data:    response,
+	}
+}
+
+// GetPurchaseOrders lists purchase orders, newest first, optionally by status, supplier and warehouse.
+func (p *PurchaseOrderService) GetPurchaseOrders(ctx context.Context, status string, supplierId int, warehouseId int) *data.WebResponse {
+	var filters []db.PurchaseOrderWhereParam
+	if status != "" {
+		filters = append(filters, db.PurchaseOrder.Status.Equals(db.PurchaseOrderStatus(strings.ToUpper(status))))
+	}
+	if supplierId != 0 {
+		filters = append(filters, db.PurchaseOrder.SupplierID.Equals(supplierId))
+	}
+	if warehouseId != 0 {
+		filters = append(filters, db.PurchaseOrder.WarehouseID.Equals(warehouseId))
+	}
+	purchaseOrders, err := p.Db.PurchaseOrder.FindMany(filters...).With(
+		db.PurchaseOrder.Items.Fetch(),
+	).OrderBy(
+		db.PurchaseOrder.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var PurchaseOrderResponses []model.PurchaseOrderResponse
+	for _, purchaseOrder := range purchaseOrders {
+		PurchaseOrderResponses = append(PurchaseOrderResponses, purchaseOrderResponse(purchaseOrder))
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Purchase orders",
+		Data:    PurchaseOrderResponses,
+	}
+}
+
+// checkPurchaseOrder makes sure a purchase order buys standard products, each once, from an active supplier
+// into an active warehouse.
+func (p *PurchaseOrderService) checkPurchaseOrder(ctx context.Context, purchaseOrderDto *model.PurchaseOrderModel) *data.WebResponse {
+	supplier, _ := p.Db.Supplier.FindUnique(db.Supplier.ID.Equals(purchaseOrderDto.SupplierId)).Exec(ctx)
+	if supplier == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Supplier not found",
+			Data:    nil,
+		}
+	}
+	if !supplier.Active {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Supplier is not active",
+			Data:    nil,
+		}
+	}
+	if response := checkWarehouse(ctx, p.Db, purchaseOrderDto.WarehouseId); response != nil {
+		return response
+	}
+
+	seen := map[int]bool{}
+	var productIds []int
+	for _, item := range purchaseOrderDto.Items {
+		if seen[item.ProductId] {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Product %d is listed more than once", item.ProductId),
+				Data:    nil,
+			}
+		}
+		seen[item.ProductId] = true
+		productIds = append(productIds, item.ProductId)
+	}
+	products, err := p.Db.Product.FindMany(
+		db.Product.ID.In(productIds),
+		db.Product.DeletedAt.IsNull(),
+		db.Product.Type.Equals(db.ProductTypeStandard),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if len(products) != len(productIds) {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found, bundles cannot be purchased",
+			Data:    nil,
+		}
+	}
+	return nil
+}
+
+func (p *PurchaseOrderService) purchaseOrderItemWrites(purchaseOrderId int, items []model.PurchaseOrderItemModel) []db.PrismaTransaction {
+	var transactions []db.PrismaTransaction
+	for _, item := range items {
+		transactions = append(transactions, p.Db.PurchaseOrderItem.CreateOne(
+			db.PurchaseOrderItem.PurchaseOrder.Link(db.PurchaseOrder.ID.Equals(purchaseOrderId)),
+			db.PurchaseOrderItem.Product.Link(db.Product.ID.Equals(item.ProductId)),
+			db.PurchaseOrderItem.Quantity.Set(item.Quantity),
+			db.PurchaseOrderItem.UnitCost.Set(roundMoney(item.UnitCost)),
+		).Tx())
+	}
+	return transactions
+}
+
+// purchaseOrderWriteError turns the database refusing a goods receipt into a response.
+func purchaseOrderWriteError(err error) *data.WebResponse {
+	switch {
+	case err == nil:
+		return nil
+	case strings.Contains(err.Error(), "PurchaseOrderItem_quantity_check"), strings.Contains(err.Error(), "is not open for receipt"):
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Purchase order was changed by another request, reload it and try again",
+			Data:    nil,
+		}
+	default:
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+}
+
+func purchaseOrderReference(purchaseOrderId int) string {
+	return fmt.Sprintf("PO-%d", purchaseOrderId)
+}
+
+// purchaseOrderResponse summarises a purchase order. Its total needs the Items relation fetched.
+func purchaseOrderResponse(purchaseOrder db.PurchaseOrderModel) model.PurchaseOrderResponse {
+	note, _ := purchaseOrder.Note()
+	response := model.PurchaseOrderResponse{
+		Id:          purchaseOrder.ID,
+		SupplierId:  purchaseOrder.SupplierID,
+		WarehouseId: purchaseOrder.WarehouseID,
+		Status:      string(purchaseOrder.Status),
+		Note:        note,
+		UserId:      purchaseOrder.UserID,
+		CreatedAt:   purchaseOrder.CreatedAt,
+	}
+	for _, item := range purchaseOrder.Items() {
+		response.Total += item.UnitCost * float64(item.Quantity)
+	}
+	response.Total = roundMoney(response.Total)
+	if expectedAt, ok := purchaseOrder.ExpectedAt(); ok {
+		response.ExpectedAt = &expectedAt
+	}
+	if sentAt, ok := purchaseOrder.SentAt(); ok {
+		response.SentAt = &sentAt
+	}
+	if receivedAt, ok := purchaseOrder.ReceivedAt(); ok {
+		response.ReceivedAt = &receivedAt
+	}
+	if closedAt, ok := purchaseOrder.ClosedAt(); ok {
+		response.ClosedAt = &closedAt
+	}
+	return response
+}
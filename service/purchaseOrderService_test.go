@@ -0,0 +1,32 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestPurchaseOrderWriteError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int // Response code, 0 for no response
+	}{
+		{"no error", nil, 0},
+		{"line received beyond what was ordered", errors.New(`violates check constraint "PurchaseOrderItem_quantity_check"`), http.StatusConflict},
+		{"order closed or cancelled meanwhile", errors.New("PurchaseOrder 3 is not open for receipt"), http.StatusConflict},
+		{"anything else", errors.New("connection reset"), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := purchaseOrderWriteError(test.err)
+			code := 0
+			if response != nil {
+				code = response.Code
+			}
+			if code != test.want {
+				t.Errorf("purchaseOrderWriteError(%v) answers %d, want %d", test.err, code, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/mail"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/context"
+	"net/http"
+	"time"
+)
+
+// lowStockQuery selects inventory at or below its reorder point with the quantity to reorder: the reorder
+// quantity, or twice the reorder point less what is available when none is set, and never less than what
+// brings the item back above its reorder point. The product is bought again from the supplier of its last
+// purchase order at that order's unit cost, or at its standard cost when it was never ordered.
+const lowStockQuery = `
+	SELECT i."productId", p."name" AS "productName", i."variantId", v."sku", i."warehouseId", w."name" AS "warehouseName",
+	       i."quantity", i."reserved", i."quantity" - i."reserved" AS "available",
+	       i."reorderPoint", COALESCE(i."reorderQuantity", 0) AS "reorderQuantity",
+	       GREATEST(COALESCE(i."reorderQuantity", 2 * i."reorderPoint" - (i."quantity" - i."reserved")),
+	                i."reorderPoint" - (i."quantity" - i."reserved") + 1) AS "suggested",
+	       COALESCE(lp."unitCost", p."standardCost") AS "unitCost", lp."supplierId", lp."supplierName"
+	FROM "Inventory" i
+	JOIN "Product" p ON p."id" = i."productId" AND p."deletedAt" IS NULL
+	LEFT JOIN "ProductVariant" v ON v."id" = i."variantId"
+	JOIN "Warehouse" w ON w."id" = i."warehouseId" AND w."active"
+	LEFT JOIN LATERAL (
+		SELECT po."supplierId", s."name" AS "supplierName", poi."unitCost"
+		FROM "PurchaseOrderItem" poi
+		JOIN "PurchaseOrder" po ON po."id" = poi."purchaseOrderId" AND po."status" <> 'DRAFT'
+		JOIN "Supplier" s ON s."id" = po."supplierId"
+		WHERE poi."productId" = i."productId"
+		ORDER BY po."sentAt" DESC NULLS LAST, po."id" DESC
+		LIMIT 1
+	) lp ON TRUE`
+
+var managerRoles = []string{"ADMIN", "MANAGER"}
+
+type ReorderService struct {
+	Db *db.PrismaClient
+}
+
+func NewReorderService(db *db.PrismaClient) *ReorderService {
+	return &ReorderService{Db: db}
+}
+
+// SetReorderPoint sets or clears the reorder point and quantity of a product, or one of its variants, in a warehouse. Changing them
+// re-arms the low stock alert.
+func (p *ReorderService) SetReorderPoint(ctx context.Context, reorderDto *model.ReorderPointModel) *data.WebResponse {
+	validator := helpers.RequestValidators(reorderDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	if reorderDto.ReorderQuantity != nil && reorderDto.ReorderPoint == nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "A reorder quantity needs a reorder point",
+			Data:    nil,
+		}
+	}
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(reorderDto.ProductId), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+	if productExist.Type == db.ProductTypeBundle {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Bundle stock is derived from its components, set reorder points on the components instead",
+			Data:    nil,
+		}
+	}
+	if response := checkWarehouse(ctx, p.Db, reorderDto.WarehouseId); response != nil {
+		return response
+	}
+	if response := checkVariant(ctx, p.Db, reorderDto.ProductId, reorderDto.VariantId); response != nil {
+		return response
+	}
+
+	err := p.Db.Prisma.Transaction(
+		repository.EnsureInventory(p.Db, reorderDto.ProductId, reorderDto.VariantId, reorderDto.WarehouseId),
+		p.Db.Inventory.FindMany(repository.InventoryOf(reorderDto.ProductId, reorderDto.VariantId, reorderDto.WarehouseId)...).Update(
+			db.Inventory.ReorderPoint.SetOptional(reorderDto.ReorderPoint),
+			db.Inventory.ReorderQuantity.SetOptional(reorderDto.ReorderQuantity),
+			db.Inventory.LowStockAlertedAt.SetOptional(nil),
+		).Tx(),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, reorderDto.UserId, "Reorder Point Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Reorder point updated",
+		Data:    nil,
+	}
+}
+
+// GetLowStock reports the items at or below their reorder point, optionally for one warehouse.
+func (p *ReorderService) GetLowStock(ctx context.Context, warehouseId int) *data.WebResponse {
+	items, err := lowStockItems(ctx, p.Db, warehouseId)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Low stock",
+		Data:    items,
+	}
+}
+
+// GetPurchaseSuggestions groups the low stock items into one suggested purchase per warehouse and supplier, the
+// supplier the product was last bought from. Items never bought before are grouped without a supplier, and
+// items without a known cost are left out of the total cost.
+func (p *ReorderService) GetPurchaseSuggestions(ctx context.Context, warehouseId int) *data.WebResponse {
+	items, err := lowStockItems(ctx, p.Db, warehouseId)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Purchase suggestions",
+		Data:    purchaseSuggestions(items),
+	}
+}
+
+// purchaseSuggestions does the grouping for GetPurchaseSuggestions, keeping the purchases in the order their
+// first item comes.
+func purchaseSuggestions(items []model.LowStockItemResponse) []model.PurchaseSuggestionResponse {
+	type purchase struct {
+		warehouseId int
+		supplierId  int
+	}
+	var suggestions []model.PurchaseSuggestionResponse
+	positions := map[purchase]int{}
+	for _, item := range items {
+		key := purchase{warehouseId: item.WarehouseId}
+		if item.SupplierId != nil {
+			key.supplierId = *item.SupplierId
+		}
+		position, ok := positions[key]
+		if !ok {
+			position = len(suggestions)
+			positions[key] = position
+			suggestions = append(suggestions, model.PurchaseSuggestionResponse{
+				WarehouseId:   item.WarehouseId,
+				WarehouseName: item.WarehouseName,
+				SupplierId:    item.SupplierId,
+				SupplierName:  item.SupplierName,
+			})
+		}
+		suggestion := &suggestions[position]
+		suggestion.TotalQuantity += item.Suggested
+		if item.UnitCost != nil {
+			suggestion.TotalCost = roundMoney(suggestion.TotalCost + *item.UnitCost*float64(item.Suggested))
+		}
+		suggestion.Items = append(suggestion.Items, item)
+	}
+	return suggestions
+}
+
+// CheckLowStock alerts managers, in their feed and by email, about items that fell to their reorder point
+// since the last check. Each item is alerted once until its stock recovers above the reorder point.
+func (p *ReorderService) CheckLowStock(ctx context.Context) error {
+	_, err := p.Db.Prisma.ExecuteRaw(`
+		UPDATE "Inventory" SET "lowStockAlertedAt" = NULL
+		WHERE "lowStockAlertedAt" IS NOT NULL AND ("reorderPoint" IS NULL OR "quantity" - "reserved" > "reorderPoint")`,
+	).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	var items []model.LowStockItemResponse
+	err = p.Db.Prisma.QueryRaw(`
+		WITH claimed AS (
+			UPDATE "Inventory" SET "lowStockAlertedAt" = CURRENT_TIMESTAMP
+			WHERE "lowStockAlertedAt" IS NULL AND "reorderPoint" IS NOT NULL AND "quantity" - "reserved" <= "reorderPoint"
+			RETURNING "id"
+		)`+lowStockQuery+`
+		JOIN claimed c ON c."id" = i."id"
+		ORDER BY w."name", p."name", v."sku" NULLS FIRST`,
+	).Exec(ctx, &items)
+	if err != nil || len(items) == 0 {
+		return err
+	}
+
+	managers, err := p.Db.User.FindMany(db.User.Role.Where(db.Role.Name.In(managerRoles))).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	var transactions []db.PrismaTransaction
+	for _, manager := range managers {
+		for _, item := range items {
+			transactions = append(transactions, p.Db.Notification.CreateOne(
+				db.Notification.User.Link(db.User.ID.Equals(manager.ID)),
+				db.Notification.Type.Set(db.NotificationTypeLowStock),
+				db.Notification.Title.Set("Low stock: "+item.ProductName),
+				db.Notification.Message.Set(fmt.Sprintf("%s in %s is down to %d available, reorder point %d. Suggested reorder: %d.",
+					item.ProductName, item.WarehouseName, item.Available, item.ReorderPoint, item.Suggested)),
+				db.Notification.Link.Set(fmt.Sprintf("/api/inventory-low-stock?warehouseId=%d", item.WarehouseId)),
+			).Tx())
+		}
+	}
+	if len(transactions) > 0 {
+		if err = p.Db.Prisma.Transaction(transactions...).Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, manager := range managers {
+		err = mail.LowStockAlert(&data.MailInputs{Email: manager.Email, Username: manager.FirstName}, items)
+		if err != nil {
+			log.Error().Err(err).Str("email", manager.Email).Msg("Sending low stock alert failed")
+		}
+	}
+	return nil
+}
+
+// RunLowStockMonitor checks for low stock every interval until the context is cancelled.
+func (p *ReorderService) RunLowStockMonitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := p.CheckLowStock(ctx); err != nil {
+			log.Error().Err(err).Msg("Checking low stock failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lowStockItems lists the items at or below their reorder point, all warehouses when warehouseId is 0.
+func lowStockItems(ctx context.Context, dbClient *db.PrismaClient, warehouseId int) ([]model.LowStockItemResponse, error) {
+	var items []model.LowStockItemResponse
+	err := dbClient.Prisma.QueryRaw(lowStockQuery+`
+		WHERE i."reorderPoint" IS NOT NULL AND i."quantity" - i."reserved" <= i."reorderPoint"
+		  AND ($1 = 0 OR i."warehouseId" = $1)
+		ORDER BY w."name", p."name", v."sku" NULLS FIRST`,
+		warehouseId,
+	).Exec(ctx, &items)
+	return items, err
+}
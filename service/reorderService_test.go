@@ -0,0 +1,47 @@
+package service
+
+import (
+	"Enterprise/model"
+	"testing"
+)
+
+func TestPurchaseSuggestions(t *testing.T) {
+	supplier := func(id int) *int {
+		return &id
+	}
+	items := []model.LowStockItemResponse{
+		{ProductId: 1, WarehouseId: 1, Suggested: 10, UnitCost: unitCost(2), SupplierId: supplier(5)},
+		{ProductId: 2, WarehouseId: 1, Suggested: 4},
+		{ProductId: 3, WarehouseId: 1, Suggested: 3, UnitCost: unitCost(1.5), SupplierId: supplier(5)},
+		{ProductId: 4, WarehouseId: 2, Suggested: 6, UnitCost: unitCost(1), SupplierId: supplier(5)},
+		{ProductId: 5, WarehouseId: 1, Suggested: 2, SupplierId: supplier(8)},
+	}
+	want := []struct {
+		warehouseId int
+		supplierId  int // 0 for items never bought before
+		products    int
+		quantity    int
+		cost        float64
+	}{
+		{warehouseId: 1, supplierId: 5, products: 2, quantity: 13, cost: 24.5},
+		{warehouseId: 1, products: 1, quantity: 4},
+		{warehouseId: 2, supplierId: 5, products: 1, quantity: 6, cost: 6},
+		{warehouseId: 1, supplierId: 8, products: 1, quantity: 2},
+	}
+
+	suggestions := purchaseSuggestions(items)
+	if len(suggestions) != len(want) {
+		t.Fatalf("got %d purchases, want %d", len(suggestions), len(want))
+	}
+	for i, suggestion := range suggestions {
+		supplierId := 0
+		if suggestion.SupplierId != nil {
+			supplierId = *suggestion.SupplierId
+		}
+		if suggestion.WarehouseId != want[i].warehouseId || supplierId != want[i].supplierId || len(suggestion.Items) != want[i].products ||
+			suggestion.TotalQuantity != want[i].quantity || suggestion.TotalCost != want[i].cost {
+			t.Errorf("purchase %d is warehouse %d supplier %d with %d products, %d units for %v, want %+v",
+				i, suggestion.WarehouseId, supplierId, len(suggestion.Items), suggestion.TotalQuantity, suggestion.TotalCost, want[i])
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/context"
+	"net/http"
+	"os"
+	"time"
+)
+
+var errInsufficientStock = errors.New("insufficient stock")
+
+type ReservationService struct {
+	Db    *db.PrismaClient
+	Cache *cache.Cache
+}
+
+func NewReservationService(db *db.PrismaClient, catalogCache *cache.Cache) *ReservationService {
+	return &ReservationService{Db: db, Cache: catalogCache}
+}
+
+// CreateReservation holds stock of a product for the reservation TTL, taken from one warehouse or spread over
+// the active warehouses with the most available. Reserving a bundle reserves its components.
+func (p *ReservationService) CreateReservation(ctx context.Context, reservationDto *model.ReservationModel) *data.WebResponse {
+	validator := helpers.RequestValidators(reservationDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	product, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(reservationDto.ProductId), db.Product.DeletedAt.IsNull()).With(
+		db.Product.BundleComponents.Fetch().With(db.BundleComponent.Component.Fetch()),
+	).Exec(ctx)
+	if product == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+
+	if response := checkVariant(ctx, p.Db, product.ID, reservationDto.VariantId); response != nil {
+		return response
+	}
+	if reservationDto.VariantId == nil && repository.ProductHasVariants(ctx, p.Db, product.ID) {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Product has variants, choose one to reserve",
+			Data:    nil,
+		}
+	}
+
+	expiresAt := time.Now().Add(reservationTTL())
+	transactions, err := reserveStock(ctx, p.Db, *product, product.BundleComponents(), reservationDto.VariantId, reservationDto.Quantity, reservationDto.WarehouseId, nil, optionalString(reservationDto.Reference), reservationDto.UserId, &expiresAt)
+	if err == nil {
+		err = p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	}
+	if errors.Is(err, errInsufficientStock) || repository.InsufficientStock(err) {
+		message := "Stock was reserved by another request, try again"
+		if errors.Is(err, errInsufficientStock) {
+			message = err.Error()
+		}
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: message,
+			Data:    nil,
+		}
+	}
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, reservationDto.UserId, "Stock Reserved", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Stock reserved",
+		Data: struct {
+			ExpiresAt time.Time `json:"expiresAt"`
+		}{
+			ExpiresAt: expiresAt,
+		},
+	}
+}
+
+func (p *ReservationService) ReleaseReservation(ctx context.Context, reservationId int, userId int) *data.WebResponse {
+	reservation, _ := p.Db.StockReservation.FindUnique(db.StockReservation.ID.Equals(reservationId)).Exec(ctx)
+	if reservation == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Reservation not found",
+			Data:    nil,
+		}
+	}
+	if reservation.Status != db.ReservationStatusActive {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Reservation is no longer active",
+			Data:    nil,
+		}
+	}
+	if orderId, ok := reservation.OrderID(); ok {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("Reservation holds stock for order %d, cancel the order instead", orderId),
+			Data:    nil,
+		}
+	}
+
+	err := p.Db.Prisma.Transaction(repository.ReleaseReservation(p.Db, reservationId, db.ReservationStatusReleased)).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Stock Reservation Released", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Reservation released",
+		Data:    nil,
+	}
+}
+
+// GetProductReservations lists the reservations of a product, newest first, only the active ones when activeOnly is set.
+func (p *ReservationService) GetProductReservations(ctx context.Context, productId int, activeOnly bool) *data.WebResponse {
+	filters := []db.StockReservationWhereParam{db.StockReservation.ProductID.Equals(productId)}
+	if activeOnly {
+		filters = append(filters, db.StockReservation.Status.Equals(db.ReservationStatusActive))
+	}
+	reservations, err := p.Db.StockReservation.FindMany(filters...).OrderBy(
+		db.StockReservation.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var ReservationResponses []model.ReservationResponse
+	for _, reservation := range reservations {
+		ReservationResponses = append(ReservationResponses, reservationResponse(reservation))
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock reservations",
+		Data:    ReservationResponses,
+	}
+}
+
+// ExpireReservations releases the reservations whose TTL has passed.
+func (p *ReservationService) ExpireReservations(ctx context.Context) error {
+	freed, err := repository.ExpireReservations(ctx, p.Db)
+	if err != nil {
+		return err
+	}
+	if freed > 0 {
+		log.Info().Int("inventory", freed).Msg("Expired stock reservations released")
+		p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+	}
+	return nil
+}
+
+// RunReservationExpiry releases expired reservations every interval until the context is cancelled.
+func (p *ReservationService) RunReservationExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := p.ExpireReservations(ctx); err != nil {
+			log.Error().Err(err).Msg("Releasing expired stock reservations failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reserveStock returns the writes that reserve quantity units of a product, a bundle through its components,
+// or of one of its variants when variantId is set. Each product is taken as reserveLine takes it.
+func reserveStock(ctx context.Context, dbClient *db.PrismaClient, product db.ProductModel, components []db.BundleComponentModel, variantId *int, quantity int, warehouseId *int, orderId *int, reference *string, userId int, expiresAt *time.Time) ([]db.PrismaTransaction, error) {
+	demand := map[stockLine]int{}
+	stockDemand(demand, product, components, variantId, quantity)
+	names := map[int]string{product.ID: product.Name}
+	for _, component := range components {
+		names[component.ComponentID] = component.Component().Name
+	}
+
+	var transactions []db.PrismaTransaction
+	for _, line := range sortedStockLines(demand) {
+		reservations, err := reserveLine(ctx, dbClient, line, names[line.productId], demand[line], warehouseId, orderId, reference, userId, expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, reservations...)
+	}
+	return transactions, nil
+}
+
+// reserveLine returns the writes that reserve quantity units of a product or variant, taken from the given
+// warehouse, or else from the active warehouses with the most available first. The allocation is read outside
+// the transaction, the Inventory reserved check keeps it from overselling when another request got there first.
+func reserveLine(ctx context.Context, dbClient *db.PrismaClient, line stockLine, name string, quantity int, warehouseId *int, orderId *int, reference *string, userId int, expiresAt *time.Time) ([]db.PrismaTransaction, error) {
+	filters := []db.InventoryWhereParam{
+		db.Inventory.ProductID.Equals(line.productId),
+		db.Inventory.VariantID.EqualsOptional(line.variant()),
+		db.Inventory.Warehouse.Where(db.Warehouse.Active.Equals(true)),
+	}
+	if warehouseId != nil {
+		filters = append(filters, db.Inventory.WarehouseID.Equals(*warehouseId))
+	}
+	inventory, err := dbClient.Inventory.FindMany(filters...).OrderBy(
+		db.Inventory.Quantity.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []db.PrismaTransaction
+	remaining := quantity
+	for _, item := range inventory {
+		take := min(item.Quantity-item.Reserved, remaining)
+		if take <= 0 {
+			continue
+		}
+		transactions = append(transactions, repository.Reserve(dbClient, line.productId, line.variant(), item.WarehouseID, take, orderId, reference, userId, expiresAt)...)
+		remaining -= take
+		if remaining == 0 {
+			break
+		}
+	}
+	if remaining > 0 {
+		return nil, fmt.Errorf("%w: only %d of %s available", errInsufficientStock, quantity-remaining, name)
+	}
+	return transactions, nil
+}
+
+// reservationTTL is how long a reservation holds stock, from RESERVATION_TTL (default 30m).
+func reservationTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("RESERVATION_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 30 * time.Minute
+}
+
+func reservationResponse(reservation db.StockReservationModel) model.ReservationResponse {
+	reference, _ := reservation.Reference()
+	response := model.ReservationResponse{
+		Id:          reservation.ID,
+		ProductId:   reservation.ProductID,
+		WarehouseId: reservation.WarehouseID,
+		Quantity:    reservation.Quantity,
+		Status:      string(reservation.Status),
+		Reference:   reference,
+		UserId:      reservation.UserID,
+		CreatedAt:   reservation.CreatedAt,
+	}
+	if variantId, ok := reservation.VariantID(); ok {
+		response.VariantId = &variantId
+	}
+	if orderId, ok := reservation.OrderID(); ok {
+		response.OrderId = &orderId
+	}
+	if expiresAt, ok := reservation.ExpiresAt(); ok {
+		response.ExpiresAt = &expiresAt
+	}
+	if releasedAt, ok := reservation.ReleasedAt(); ok {
+		response.ReleasedAt = &releasedAt
+	}
+	return response
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"Enterprise/prisma/db"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortedStockLines(t *testing.T) {
+	demand := map[stockLine]int{
+		{productId: 2, variantId: 5}: 1,
+		{productId: 1}:               1,
+		{productId: 2}:               1,
+		{productId: 1, variantId: 9}: 1,
+	}
+	want := []stockLine{{productId: 1}, {productId: 1, variantId: 9}, {productId: 2}, {productId: 2, variantId: 5}}
+	// Concurrent reservations must lock inventory in the same order whatever order the map gives.
+	for i := 0; i < 10; i++ {
+		if got := sortedStockLines(demand); !reflect.DeepEqual(got, want) {
+			t.Fatalf("sortedStockLines() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestStockDemand(t *testing.T) {
+	standard := db.ProductModel{InnerProduct: db.InnerProduct{ID: 1, Type: db.ProductTypeStandard}}
+	bundle := db.ProductModel{InnerProduct: db.InnerProduct{ID: 10, Type: db.ProductTypeBundle}}
+	components := []db.BundleComponentModel{
+		{InnerBundleComponent: db.InnerBundleComponent{BundleID: 10, ComponentID: 1, Quantity: 2}},
+		{InnerBundleComponent: db.InnerBundleComponent{BundleID: 10, ComponentID: 3, Quantity: 1}},
+	}
+	variantId := 7
+
+	demand := map[stockLine]int{}
+	stockDemand(demand, standard, nil, nil, 1)
+	stockDemand(demand, standard, nil, &variantId, 4)
+	stockDemand(demand, bundle, components, nil, 3)
+	want := map[stockLine]int{
+		{productId: 1}:               7,
+		{productId: 1, variantId: 7}: 4,
+		{productId: 3}:               3,
+	}
+	if !reflect.DeepEqual(demand, want) {
+		t.Errorf("stockDemand() = %v, want %v", demand, want)
+	}
+}
+
+func TestHeldStock(t *testing.T) {
+	variantId := 7
+	reservation := func(productId int, variantId *int, warehouseId int, quantity int) db.StockReservationModel {
+		return db.StockReservationModel{InnerStockReservation: db.InnerStockReservation{
+			ProductID: productId, VariantID: variantId, WarehouseID: warehouseId, Quantity: quantity,
+		}}
+	}
+	held := heldStock([]db.StockReservationModel{
+		reservation(1, nil, 1, 2),
+		reservation(1, nil, 1, 3),
+		reservation(1, nil, 2, 1),
+		reservation(1, &variantId, 1, 4),
+	})
+	want := map[stockLine]map[int]int{
+		{productId: 1}:               {1: 5, 2: 1},
+		{productId: 1, variantId: 7}: {1: 4},
+	}
+	if !reflect.DeepEqual(held, want) {
+		t.Errorf("heldStock() = %v, want %v", held, want)
+	}
+}
+
+func TestReservationTTL(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 30 * time.Minute},
+		{"45m", 45 * time.Minute},
+		{"-5m", 30 * time.Minute},
+		{"soon", 30 * time.Minute},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			t.Setenv("RESERVATION_TTL", test.value)
+			if got := reservationTTL(); got != test.want {
+				t.Errorf("reservationTTL() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"errors"
+	"golang.org/x/net/context"
+	"net/http"
+)
+
+type StockMovementService struct {
+	Db    *db.PrismaClient
+	Cache *cache.Cache
+}
+
+func NewStockMovementService(db *db.PrismaClient, catalogCache *cache.Cache) *StockMovementService {
+	return &StockMovementService{Db: db, Cache: catalogCache}
+}
+
+// RecordStockMovement books a receipt, shipment, adjustment or return against a warehouse. Receipts and returns
+// must add stock, shipments must take it out. Transfers are only recorded by transfer orders.
+func (p *StockMovementService) RecordStockMovement(ctx context.Context, movementDto *model.StockMovementModel) *data.WebResponse {
+	validator := helpers.RequestValidators(movementDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	movementType := db.StockMovementType(movementDto.Type)
+	if (movementType == db.StockMovementTypeReceipt || movementType == db.StockMovementTypeReturn) && movementDto.Quantity < 0 ||
+		movementType == db.StockMovementTypeShipment && movementDto.Quantity > 0 {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Receipts and returns must have a positive quantity, shipments a negative one",
+			Data:    nil,
+		}
+	}
+
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(movementDto.ProductId), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+	if productExist.Type == db.ProductTypeBundle {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Bundle stock is derived from its components, move the components instead",
+			Data:    nil,
+		}
+	}
+	if response := checkWarehouse(ctx, p.Db, movementDto.WarehouseId); response != nil {
+		return response
+	}
+	if response := checkVariant(ctx, p.Db, movementDto.ProductId, movementDto.VariantId); response != nil {
+		return response
+	}
+	if movementDto.VariantId != nil && movementDto.LotNumber != "" {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Lots hold product stock, variant stock is not kept in lots",
+			Data:    nil,
+		}
+	}
+
+	if movementDto.UnitCost != nil && movementDto.Quantity < 0 {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Only stock coming in has a unit cost",
+			Data:    nil,
+		}
+	}
+	// Stock received or adjusted in is valued at its own cost, or the product's standard cost when it has none.
+	_, standardCost := productExist.StandardCost()
+	if movementDto.UnitCost == nil && !standardCost && movementDto.Quantity > 0 &&
+		(movementType == db.StockMovementTypeReceipt || movementType == db.StockMovementTypeAdjustment) {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "unitCost is required, the product has no standard cost",
+			Data:    nil,
+		}
+	}
+	lot, response := lotDetails(productExist.LotTracked && movementDto.VariantId == nil, movementType == db.StockMovementTypeReceipt, movementDto.LotNumber, movementDto.ManufacturedAt, movementDto.ExpiresAt)
+	if response != nil {
+		return response
+	}
+
+	// Stock going out of a named lot leaves that lot, any other outbound stock is picked first-expired-first-out.
+	// Only shipments skip expired lots, adjustments are how they are written off.
+	var transactions []db.PrismaTransaction
+	if movementDto.Quantity > 0 && movementDto.VariantId != nil {
+		transactions = repository.StockMovement(p.Db, movementDto.ProductId, movementDto.VariantId, movementDto.WarehouseId, movementType,
+			movementDto.Quantity, movementDto.Reason, optionalString(movementDto.Reference), &movementDto.UserId, movementDto.UnitCost)
+	} else if movementDto.Quantity > 0 || lot != nil {
+		transactions = repository.LotStockMovement(p.Db, movementDto.ProductId, movementDto.WarehouseId, lot, movementType,
+			movementDto.Quantity, movementDto.Reason, optionalString(movementDto.Reference), &movementDto.UserId, movementDto.UnitCost)
+	} else {
+		var err error
+		transactions, _, err = repository.Outbound(ctx, p.Db, movementDto.ProductId, movementDto.VariantId, movementDto.WarehouseId, movementType,
+			-movementDto.Quantity, movementDto.Reason, optionalString(movementDto.Reference), &movementDto.UserId,
+			movementType != db.StockMovementTypeShipment)
+		if errors.Is(err, repository.ErrExpiredStock) {
+			return &data.WebResponse{
+				Code:    http.StatusConflict,
+				Message: "The remaining stock in warehouse has expired",
+				Data:    nil,
+			}
+		}
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+	}
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if repository.InsufficientStock(err) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Insufficient stock in warehouse",
+			Data:    nil,
+		}
+	}
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, movementDto.UserId, "Stock Movement Recorded", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Stock movement recorded",
+		Data:    nil,
+	}
+}
+
+// GetStockMovements is the movement history of a product, newest first, optionally for one warehouse.
+func (p *StockMovementService) GetStockMovements(ctx context.Context, productId int, warehouseId int) *data.WebResponse {
+	productExist, _ := p.Db.Product.FindUnique(db.Product.ID.Equals(productId)).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+
+	filters := []db.StockMovementWhereParam{db.StockMovement.ProductID.Equals(productId)}
+	if warehouseId != 0 {
+		filters = append(filters, db.StockMovement.WarehouseID.Equals(warehouseId))
+	}
+	movements, err := p.Db.StockMovement.FindMany(filters...).With(
+		db.StockMovement.Warehouse.Fetch(),
+		db.StockMovement.Lot.Fetch(),
+		db.StockMovement.Cost.Fetch(),
+	).OrderBy(
+		db.StockMovement.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var MovementResponses []model.StockMovementResponse
+	for _, movement := range movements {
+		reference, _ := movement.Reference()
+		response := model.StockMovementResponse{
+			Id:            movement.ID,
+			ProductId:     movement.ProductID,
+			WarehouseId:   movement.WarehouseID,
+			WarehouseName: movement.Warehouse().Name,
+			Type:          string(movement.Type),
+			Quantity:      movement.Quantity,
+			Reason:        movement.Reason,
+			Reference:     reference,
+			CreatedAt:     movement.CreatedAt,
+		}
+		if variantId, ok := movement.VariantID(); ok {
+			response.VariantId = &variantId
+		}
+		if userId, ok := movement.UserID(); ok {
+			response.UserId = &userId
+		}
+		if lot, ok := movement.Lot(); ok {
+			response.LotNumber = &lot.LotNumber
+		}
+		if cost, ok := movement.Cost(); ok {
+			response.UnitCost = &cost.UnitCost
+		}
+		MovementResponses = append(MovementResponses, response)
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock movements",
+		Data:    MovementResponses,
+	}
+}
+
+// ReconcileStock compares every inventory quantity with the sum of its ledger movements. With apply set the
+// inventory is corrected to the ledger, which is the record of truth.
+func (p *StockMovementService) ReconcileStock(ctx context.Context, userId int, apply bool) *data.WebResponse {
+	var discrepancies []model.StockDiscrepancyResponse
+	err := p.Db.Prisma.QueryRaw(`
+		SELECT COALESCE(i."productId", l."productId") AS "productId",
+		       CASE WHEN i."id" IS NULL THEN l."variantId" ELSE i."variantId" END AS "variantId",
+		       COALESCE(i."warehouseId", l."warehouseId") AS "warehouseId",
+		       COALESCE(i."quantity", 0) AS "inventory",
+		       COALESCE(l."quantity", 0) AS "ledger"
+		FROM "Inventory" i
+		FULL OUTER JOIN (
+			SELECT "productId", "variantId", "warehouseId", CAST(SUM("quantity") AS INTEGER) AS "quantity"
+			FROM "StockMovement"
+			GROUP BY "productId", "variantId", "warehouseId"
+		) l ON l."productId" = i."productId" AND l."variantId" IS NOT DISTINCT FROM i."variantId" AND l."warehouseId" = i."warehouseId"
+		WHERE COALESCE(i."quantity", 0) <> COALESCE(l."quantity", 0)
+		ORDER BY 1, 3, 2`,
+	).Exec(ctx, &discrepancies)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if !apply || len(discrepancies) == 0 {
+		return &data.WebResponse{
+			Code:    http.StatusOK,
+			Message: "Stock discrepancies",
+			Data:    discrepancies,
+		}
+	}
+
+	for _, discrepancy := range discrepancies {
+		transactions := []db.PrismaTransaction{
+			repository.EnsureInventory(p.Db, discrepancy.ProductId, discrepancy.VariantId, discrepancy.WarehouseId),
+			p.Db.Inventory.FindMany(repository.InventoryOf(discrepancy.ProductId, discrepancy.VariantId, discrepancy.WarehouseId)...).Update(
+				db.Inventory.Quantity.Set(discrepancy.Ledger),
+			).Tx(),
+			repository.SyncProductStock(p.Db, discrepancy.ProductId),
+		}
+		if discrepancy.VariantId != nil {
+			transactions = append(transactions, repository.SyncVariantStock(p.Db, *discrepancy.VariantId))
+		}
+		err = p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    discrepancies,
+			}
+		}
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Stock Reconciled", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock reconciled with the ledger",
+		Data:    discrepancies,
+	}
+}
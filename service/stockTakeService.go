@@ -0,0 +1,552 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"fmt"
+	"golang.org/x/net/context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type StockTakeService struct {
+	Db    *db.PrismaClient
+	Cache *cache.Cache
+}
+
+func NewStockTakeService(db *db.PrismaClient, catalogCache *cache.Cache) *StockTakeService {
+	return &StockTakeService{Db: db, Cache: catalogCache}
+}
+
+// CreateStockTake opens a stock take of a warehouse, or of its bins starting with a prefix for a cycle count.
+func (p *StockTakeService) CreateStockTake(ctx context.Context, stockTakeDto *model.StockTakeModel) *data.WebResponse {
+	validator := helpers.RequestValidators(stockTakeDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	if response := checkWarehouse(ctx, p.Db, stockTakeDto.WarehouseId); response != nil {
+		return response
+	}
+
+	stockTake, err := p.Db.StockTake.CreateOne(
+		db.StockTake.Warehouse.Link(db.Warehouse.ID.Equals(stockTakeDto.WarehouseId)),
+		db.StockTake.User.Link(db.User.ID.Equals(stockTakeDto.UserId)),
+		db.StockTake.BinPrefix.SetIfPresent(optionalString(stockTakeDto.BinPrefix)),
+		db.StockTake.Note.SetIfPresent(optionalString(stockTakeDto.Note)),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, stockTakeDto.UserId, "Stock Take Created", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Stock take created",
+		Data: struct {
+			Id int `json:"id"`
+		}{
+			Id: stockTake.ID,
+		},
+	}
+}
+
+// SubmitCounts records counted quantities while the stock take is open, a recount replaces the earlier count.
+// The book quantity is captured with each count and kept from the counter, and approval posts the difference,
+// so stock moving between counting and approval is not lost.
+func (p *StockTakeService) SubmitCounts(ctx context.Context, countDto *model.StockTakeCountModel) *data.WebResponse {
+	validator := helpers.RequestValidators(countDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	stockTake, _ := p.Db.StockTake.FindUnique(db.StockTake.ID.Equals(countDto.StockTakeId)).Exec(ctx)
+	if stockTake == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Stock take not found",
+			Data:    nil,
+		}
+	}
+	if stockTake.Status != db.StockTakeStatusOpen {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Stock take is not open for counting",
+			Data:    nil,
+		}
+	}
+
+	seen := map[int]bool{}
+	var productIds []int
+	for _, item := range countDto.Items {
+		if seen[item.ProductId] {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Product %d is listed more than once", item.ProductId),
+				Data:    nil,
+			}
+		}
+		seen[item.ProductId] = true
+		productIds = append(productIds, item.ProductId)
+	}
+	products, err := p.Db.Product.FindMany(
+		db.Product.ID.In(productIds),
+		db.Product.DeletedAt.IsNull(),
+		db.Product.Type.Equals(db.ProductTypeStandard),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if len(products) != len(productIds) {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found, bundles cannot be counted",
+			Data:    nil,
+		}
+	}
+	inventory, err := p.Db.Inventory.FindMany(
+		db.Inventory.WarehouseID.Equals(stockTake.WarehouseID),
+		db.Inventory.ProductID.In(productIds),
+		db.Inventory.VariantID.IsNull(),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	book := map[int]int{}
+	for _, item := range inventory {
+		book[item.ProductID] = item.Quantity
+	}
+
+	var transactions []db.PrismaTransaction
+	for _, item := range countDto.Items {
+		transactions = append(transactions, p.Db.StockTakeCount.UpsertOne(db.StockTakeCount.StockTakeIDProductID(
+			db.StockTakeCount.StockTakeID.Equals(stockTake.ID),
+			db.StockTakeCount.ProductID.Equals(item.ProductId),
+		)).Create(
+			db.StockTakeCount.StockTake.Link(db.StockTake.ID.Equals(stockTake.ID)),
+			db.StockTakeCount.Product.Link(db.Product.ID.Equals(item.ProductId)),
+			db.StockTakeCount.CountedQuantity.Set(*item.Counted),
+			db.StockTakeCount.BookQuantity.Set(book[item.ProductId]),
+			db.StockTakeCount.User.Link(db.User.ID.Equals(countDto.UserId)),
+			db.StockTakeCount.Bin.SetIfPresent(item.Bin),
+		).Update(
+			db.StockTakeCount.CountedQuantity.Set(*item.Counted),
+			db.StockTakeCount.BookQuantity.Set(book[item.ProductId]),
+			db.StockTakeCount.User.Link(db.User.ID.Equals(countDto.UserId)),
+			db.StockTakeCount.Bin.SetOptional(item.Bin),
+			db.StockTakeCount.CountedAt.Set(time.Now()),
+		).Tx())
+	}
+	err = p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if response := stockTakeWriteError(err); response != nil {
+		return response
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, countDto.UserId, "Stock Counted", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Counts recorded",
+		Data:    nil,
+	}
+}
+
+// GetStockTakeSheet is the counter's list of what to count with what they counted so far. It is blind: book
+// quantities are never part of it.
+func (p *StockTakeService) GetStockTakeSheet(ctx context.Context, stockTakeId int) *data.WebResponse {
+	stockTake, _ := p.Db.StockTake.FindUnique(db.StockTake.ID.Equals(stockTakeId)).With(
+		db.StockTake.Counts.Fetch().With(db.StockTakeCount.Product.Fetch()),
+	).Exec(ctx)
+	if stockTake == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Stock take not found",
+			Data:    nil,
+		}
+	}
+	inventory, err := stockTakeInventory(ctx, p.Db, *stockTake)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	counts := map[int]db.StockTakeCountModel{}
+	for _, count := range stockTake.Counts() {
+		counts[count.ProductID] = count
+	}
+	response := model.StockTakeSheetResponse{
+		Id:          stockTake.ID,
+		WarehouseId: stockTake.WarehouseID,
+		Status:      string(stockTake.Status),
+		Items:       []model.StockTakeSheetItemResponse{},
+	}
+	for _, item := range inventory {
+		sheetItem := model.StockTakeSheetItemResponse{
+			ProductId:   item.ProductID,
+			ProductName: item.Product().Name,
+		}
+		if bin, ok := item.Bin(); ok {
+			sheetItem.Bin = &bin
+		}
+		if count, ok := counts[item.ProductID]; ok {
+			sheetItem.Counted = &count.CountedQuantity
+			if bin, ok := count.Bin(); ok {
+				sheetItem.Bin = &bin
+			}
+			delete(counts, item.ProductID)
+		}
+		response.Items = append(response.Items, sheetItem)
+	}
+	// Products found that were not on record in the warehouse
+	for _, count := range stockTake.Counts() {
+		if _, ok := counts[count.ProductID]; !ok {
+			continue
+		}
+		sheetItem := model.StockTakeSheetItemResponse{
+			ProductId:   count.ProductID,
+			ProductName: count.Product().Name,
+			Counted:     &count.CountedQuantity,
+		}
+		if bin, ok := count.Bin(); ok {
+			sheetItem.Bin = &bin
+		}
+		response.Items = append(response.Items, sheetItem)
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock take sheet",
+		Data:    response,
+	}
+}
+
+// CloseCounting ends counting so the variances can be reviewed and approved.
+func (p *StockTakeService) CloseCounting(ctx context.Context, stockTakeId int, userId int) *data.WebResponse {
+	return p.transition(ctx, stockTakeId, userId,
+		`UPDATE "StockTake" SET "status" = 'COUNTED', "closedAt" = CURRENT_TIMESTAMP, "updatedAt" = CURRENT_TIMESTAMP WHERE "id" = $1 AND "status" = 'OPEN'`,
+		"Only open stock takes can be closed", "Stock Take Closed", "Stock take closed for review",
+	)
+}
+
+func (p *StockTakeService) CancelStockTake(ctx context.Context, stockTakeId int, userId int) *data.WebResponse {
+	return p.transition(ctx, stockTakeId, userId,
+		`UPDATE "StockTake" SET "status" = 'CANCELLED', "updatedAt" = CURRENT_TIMESTAMP WHERE "id" = $1 AND "status" IN ('OPEN', 'COUNTED')`,
+		"Approved stock takes cannot be cancelled", "Stock Take Cancelled", "Stock take cancelled",
+	)
+}
+
+// ApproveStockTake posts every counted variance as a ledger adjustment and moves stock to the counted bins.
+// Approving twice, or a stock take reopened or cancelled meanwhile, fails in the database.
+func (p *StockTakeService) ApproveStockTake(ctx context.Context, stockTakeId int, userId int) *data.WebResponse {
+	stockTake, _ := p.Db.StockTake.FindUnique(db.StockTake.ID.Equals(stockTakeId)).With(
+		db.StockTake.Counts.Fetch(),
+	).Exec(ctx)
+	if stockTake == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Stock take not found",
+			Data:    nil,
+		}
+	}
+	if stockTake.Status != db.StockTakeStatusCounted {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Only stock takes closed for review can be approved",
+			Data:    nil,
+		}
+	}
+	if len(stockTake.Counts()) == 0 {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Stock take has no counts",
+			Data:    nil,
+		}
+	}
+
+	reference := stockTakeReference(stockTake.ID)
+	var transactions []db.PrismaTransaction
+	for _, count := range stockTake.Counts() {
+		transactions = append(transactions, p.Db.StockTakeCount.FindUnique(db.StockTakeCount.ID.Equals(count.ID)).Update(
+			db.StockTakeCount.PostedAt.Set(time.Now()),
+		).Tx())
+		if variance := count.CountedQuantity - count.BookQuantity; variance > 0 {
+			transactions = append(transactions, repository.StockMovement(p.Db, count.ProductID, nil, stockTake.WarehouseID, db.StockMovementTypeAdjustment, variance, "Stock take", &reference, &userId, nil)...)
+		} else if variance < 0 {
+			outbound, _, err := repository.Outbound(ctx, p.Db, count.ProductID, nil, stockTake.WarehouseID, db.StockMovementTypeAdjustment, -variance, "Stock take", &reference, &userId, true)
+			if err != nil {
+				return &data.WebResponse{
+					Code:    http.StatusInternalServerError,
+					Message: err.Error(),
+					Data:    nil,
+				}
+			}
+			transactions = append(transactions, outbound...)
+		}
+		if bin, ok := count.Bin(); ok {
+			transactions = append(transactions,
+				repository.EnsureInventory(p.Db, count.ProductID, nil, stockTake.WarehouseID),
+				p.Db.Inventory.FindMany(repository.InventoryOf(count.ProductID, nil, stockTake.WarehouseID)...).Update(
+					db.Inventory.Bin.Set(bin),
+				).Tx(),
+			)
+		}
+	}
+	transactions = append(transactions, p.Db.Prisma.ExecuteRaw(
+		`UPDATE "StockTake" SET "status" = 'APPROVED', "approvedAt" = CURRENT_TIMESTAMP, "updatedAt" = CURRENT_TIMESTAMP WHERE "id" = $1 AND "status" = 'COUNTED'`,
+		stockTake.ID,
+	).Tx())
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if response := stockTakeWriteError(err); response != nil {
+		return response
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Stock Take Approved", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock take approved",
+		Data:    nil,
+	}
+}
+
+// GetStockTakeById is the manager's review of a stock take: every count with its book quantity and variance,
+// and the products on the sheet that were not counted.
+func (p *StockTakeService) GetStockTakeById(ctx context.Context, stockTakeId int) *data.WebResponse {
+	stockTake, _ := p.Db.StockTake.FindUnique(db.StockTake.ID.Equals(stockTakeId)).With(
+		db.StockTake.Counts.Fetch().With(db.StockTakeCount.Product.Fetch()),
+	).Exec(ctx)
+	if stockTake == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Stock take not found",
+			Data:    nil,
+		}
+	}
+	inventory, err := stockTakeInventory(ctx, p.Db, *stockTake)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	response := stockTakeResponse(*stockTake)
+	counted := map[int]bool{}
+	for _, count := range stockTake.Counts() {
+		countResponse := model.StockTakeCountResponse{
+			ProductId:   count.ProductID,
+			ProductName: count.Product().Name,
+			Counted:     count.CountedQuantity,
+			Book:        count.BookQuantity,
+			Variance:    count.CountedQuantity - count.BookQuantity,
+			UserId:      count.UserID,
+			CountedAt:   count.CountedAt,
+		}
+		if bin, ok := count.Bin(); ok {
+			countResponse.Bin = &bin
+		}
+		if postedAt, ok := count.PostedAt(); ok {
+			countResponse.PostedAt = &postedAt
+		}
+		counted[count.ProductID] = true
+		response.Counts = append(response.Counts, countResponse)
+	}
+	for _, item := range inventory {
+		if !counted[item.ProductID] {
+			response.Uncounted = append(response.Uncounted, item.ProductID)
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock take found",
+		Data:    response,
+	}
+}
+
+// GetStockTakes lists stock takes, newest first, optionally by status and warehouse.
+func (p *StockTakeService) GetStockTakes(ctx context.Context, status string, warehouseId int) *data.WebResponse {
+	var filters []db.StockTakeWhereParam
+	if status != "" {
+		filters = append(filters, db.StockTake.Status.Equals(db.StockTakeStatus(strings.ToUpper(status))))
+	}
+	if warehouseId != 0 {
+		filters = append(filters, db.StockTake.WarehouseID.Equals(warehouseId))
+	}
+	stockTakes, err := p.Db.StockTake.FindMany(filters...).OrderBy(
+		db.StockTake.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var StockTakeResponses []model.StockTakeResponse
+	for _, stockTake := range stockTakes {
+		StockTakeResponses = append(StockTakeResponses, stockTakeResponse(stockTake))
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock takes",
+		Data:    StockTakeResponses,
+	}
+}
+
+// transition applies a conditional status change and reports 404 or 409 when it did not match.
+func (p *StockTakeService) transition(ctx context.Context, stockTakeId int, userId int, query string, conflict string, action string, message string) *data.WebResponse {
+	stockTake, _ := p.Db.StockTake.FindUnique(db.StockTake.ID.Equals(stockTakeId)).Exec(ctx)
+	if stockTake == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Stock take not found",
+			Data:    nil,
+		}
+	}
+	result, err := p.Db.Prisma.ExecuteRaw(query, stockTakeId).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if result.Count == 0 {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: conflict,
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, userId, action, "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    nil,
+	}
+}
+
+// stockTakeInventory is what a stock take has to count: the warehouse's inventory of live products, only the
+// bins starting with the stock take's prefix for a cycle count. Counts are per product, so variant stock is not
+// part of a stock take and is corrected through stock movements.
+func stockTakeInventory(ctx context.Context, dbClient *db.PrismaClient, stockTake db.StockTakeModel) ([]db.InventoryModel, error) {
+	filters := []db.InventoryWhereParam{
+		db.Inventory.WarehouseID.Equals(stockTake.WarehouseID),
+		db.Inventory.Product.Where(db.Product.DeletedAt.IsNull()),
+		db.Inventory.VariantID.IsNull(),
+	}
+	if binPrefix, ok := stockTake.BinPrefix(); ok {
+		filters = append(filters, db.Inventory.Bin.StartsWith(binPrefix))
+	}
+	return dbClient.Inventory.FindMany(filters...).With(
+		db.Inventory.Product.Fetch(),
+	).OrderBy(
+		db.Inventory.Bin.Order(db.SortOrderAsc),
+	).Exec(ctx)
+}
+
+// stockTakeWriteError turns the database refusing a count or an approval into a response.
+func stockTakeWriteError(err error) *data.WebResponse {
+	switch {
+	case err == nil:
+		return nil
+	case repository.InsufficientStock(err):
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Stock left the warehouse since it was counted, recount the affected products",
+			Data:    nil,
+		}
+	case strings.Contains(err.Error(), "is not open for counting"), strings.Contains(err.Error(), "is not awaiting approval"):
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Stock take was changed by another request, reload it and try again",
+			Data:    nil,
+		}
+	default:
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+}
+
+func stockTakeReference(stockTakeId int) string {
+	return fmt.Sprintf("ST-%d", stockTakeId)
+}
+
+func stockTakeResponse(stockTake db.StockTakeModel) model.StockTakeResponse {
+	binPrefix, _ := stockTake.BinPrefix()
+	note, _ := stockTake.Note()
+	response := model.StockTakeResponse{
+		Id:          stockTake.ID,
+		WarehouseId: stockTake.WarehouseID,
+		Status:      string(stockTake.Status),
+		BinPrefix:   binPrefix,
+		Note:        note,
+		UserId:      stockTake.UserID,
+		CreatedAt:   stockTake.CreatedAt,
+	}
+	if closedAt, ok := stockTake.ClosedAt(); ok {
+		response.ClosedAt = &closedAt
+	}
+	if approvedAt, ok := stockTake.ApprovedAt(); ok {
+		response.ApprovedAt = &approvedAt
+	}
+	return response
+}
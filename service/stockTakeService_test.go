@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestStockTakeWriteError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    int // Response code, 0 for no response
+		message string
+	}{
+		{name: "no error", err: nil},
+		{
+			name:    "stock shipped since it was counted",
+			err:     errors.New(`violates check constraint "Inventory_quantity_check"`),
+			want:    http.StatusConflict,
+			message: "Stock left the warehouse since it was counted, recount the affected products",
+		},
+		{
+			name:    "count after the session closed",
+			err:     errors.New("StockTake 4 is not open for counting"),
+			want:    http.StatusConflict,
+			message: "Stock take was changed by another request, reload it and try again",
+		},
+		{
+			name:    "approved twice",
+			err:     errors.New("StockTake 4 is not awaiting approval"),
+			want:    http.StatusConflict,
+			message: "Stock take was changed by another request, reload it and try again",
+		},
+		{name: "anything else", err: errors.New("connection reset"), want: http.StatusInternalServerError, message: "connection reset"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := stockTakeWriteError(test.err)
+			code, message := 0, ""
+			if response != nil {
+				code, message = response.Code, response.Message
+			}
+			if code != test.want || message != test.message {
+				t.Errorf("stockTakeWriteError(%v) answers %d %q, want %d %q", test.err, code, message, test.want, test.message)
+			}
+		})
+	}
+}
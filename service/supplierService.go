@@ -0,0 +1,246 @@
+package service
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"golang.org/x/net/context"
+	"net/http"
+)
+
+type SupplierService struct {
+	Db *db.PrismaClient
+}
+
+func NewSupplierService(db *db.PrismaClient) *SupplierService {
+	return &SupplierService{Db: db}
+}
+
+func (p *SupplierService) CreateSupplier(ctx context.Context, supplierDto *model.SupplierModel) *data.WebResponse {
+	validator := helpers.RequestValidators(supplierDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	existing, _ := p.Db.Supplier.FindUnique(db.Supplier.Name.Equals(supplierDto.Name)).Exec(ctx)
+	if existing != nil {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Supplier name already exists",
+			Data:    nil,
+		}
+	}
+
+	supplier, err := p.Db.Supplier.CreateOne(
+		db.Supplier.Name.Set(supplierDto.Name),
+		db.Supplier.ContactName.SetIfPresent(supplierDto.ContactName),
+		db.Supplier.Email.SetIfPresent(supplierDto.Email),
+		db.Supplier.Phone.SetIfPresent(supplierDto.Phone),
+		db.Supplier.Address.SetIfPresent(supplierDto.Address),
+		db.Supplier.LeadTimeDays.SetIfPresent(supplierDto.LeadTimeDays),
+		db.Supplier.Active.SetIfPresent(supplierDto.Active),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, supplierDto.UserId, "Supplier Created", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Supplier created",
+		Data: struct {
+			Id int `json:"id"`
+		}{
+			Id: supplier.ID,
+		},
+	}
+}
+
+// UpdateSupplier replaces the details of a supplier. An inactive supplier keeps its purchase orders but
+// cannot be sent new ones.
+func (p *SupplierService) UpdateSupplier(ctx context.Context, supplierDto *model.SupplierModel) *data.WebResponse {
+	validator := helpers.RequestValidators(supplierDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	supplierExist, _ := p.Db.Supplier.FindUnique(db.Supplier.ID.Equals(supplierDto.SupplierId)).Exec(ctx)
+	if supplierExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Supplier not found",
+			Data:    nil,
+		}
+	}
+	existing, _ := p.Db.Supplier.FindUnique(db.Supplier.Name.Equals(supplierDto.Name)).Exec(ctx)
+	if existing != nil && existing.ID != supplierDto.SupplierId {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Supplier name already exists",
+			Data:    nil,
+		}
+	}
+
+	_, err := p.Db.Supplier.FindUnique(db.Supplier.ID.Equals(supplierDto.SupplierId)).Update(
+		db.Supplier.Name.Set(supplierDto.Name),
+		db.Supplier.ContactName.SetOptional(supplierDto.ContactName),
+		db.Supplier.Email.SetOptional(supplierDto.Email),
+		db.Supplier.Phone.SetOptional(supplierDto.Phone),
+		db.Supplier.Address.SetOptional(supplierDto.Address),
+		db.Supplier.LeadTimeDays.SetOptional(supplierDto.LeadTimeDays),
+		db.Supplier.Active.SetIfPresent(supplierDto.Active),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, supplierDto.UserId, "Supplier Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Supplier updated",
+		Data:    nil,
+	}
+}
+
+// DeleteSupplier removes a supplier that was never sent a purchase order. One with orders must be deactivated instead.
+func (p *SupplierService) DeleteSupplier(ctx context.Context, supplierId int, userId int) *data.WebResponse {
+	supplierExist, _ := p.Db.Supplier.FindUnique(db.Supplier.ID.Equals(supplierId)).Exec(ctx)
+	if supplierExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Supplier not found",
+			Data:    nil,
+		}
+	}
+	purchaseOrder, _ := p.Db.PurchaseOrder.FindFirst(db.PurchaseOrder.SupplierID.Equals(supplierId)).Exec(ctx)
+	if purchaseOrder != nil {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Supplier has purchase orders, deactivate it instead",
+			Data:    nil,
+		}
+	}
+
+	_, err := p.Db.Supplier.FindUnique(db.Supplier.ID.Equals(supplierId)).Delete().Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Supplier Deleted", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Supplier deleted",
+		Data:    nil,
+	}
+}
+
+func (p *SupplierService) GetSupplierById(ctx context.Context, supplierId int) *data.WebResponse {
+	supplier, _ := p.Db.Supplier.FindUnique(db.Supplier.ID.Equals(supplierId)).Exec(ctx)
+	if supplier == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Supplier not found",
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Supplier found",
+		Data:    supplierResponse(*supplier),
+	}
+}
+
+// GetAllSuppliers lists suppliers, only the active ones when activeOnly is set.
+func (p *SupplierService) GetAllSuppliers(ctx context.Context, activeOnly bool) *data.WebResponse {
+	var filters []db.SupplierWhereParam
+	if activeOnly {
+		filters = append(filters, db.Supplier.Active.Equals(true))
+	}
+	suppliers, err := p.Db.Supplier.FindMany(filters...).OrderBy(
+		db.Supplier.Name.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var SupplierResponses []model.SupplierResponse
+	for _, supplier := range suppliers {
+		SupplierResponses = append(SupplierResponses, supplierResponse(supplier))
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Suppliers",
+		Data:    SupplierResponses,
+	}
+}
+
+func supplierResponse(supplier db.SupplierModel) model.SupplierResponse {
+	response := model.SupplierResponse{
+		Id:        supplier.ID,
+		Name:      supplier.Name,
+		Active:    supplier.Active,
+		CreatedAt: supplier.CreatedAt,
+		UpdatedAt: supplier.UpdatedAt,
+	}
+	if contactName, ok := supplier.ContactName(); ok {
+		response.ContactName = &contactName
+	}
+	if email, ok := supplier.Email(); ok {
+		response.Email = &email
+	}
+	if phone, ok := supplier.Phone(); ok {
+		response.Phone = &phone
+	}
+	if address, ok := supplier.Address(); ok {
+		response.Address = &address
+	}
+	if leadTimeDays, ok := supplier.LeadTimeDays(); ok {
+		response.LeadTimeDays = &leadTimeDays
+	}
+	return response
+}
@@ -0,0 +1,633 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"errors"
+	"fmt"
+	"golang.org/x/net/context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type TransferService struct {
+	Db    *db.PrismaClient
+	Cache *cache.Cache
+}
+
+func NewTransferService(db *db.PrismaClient, catalogCache *cache.Cache) *TransferService {
+	return &TransferService{Db: db, Cache: catalogCache}
+}
+
+func (p *TransferService) CreateTransfer(ctx context.Context, transferDto *model.TransferModel) *data.WebResponse {
+	validator := helpers.RequestValidators(transferDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	if response := checkWarehouse(ctx, p.Db, transferDto.SourceWarehouseId); response != nil {
+		return response
+	}
+	if response := checkWarehouse(ctx, p.Db, transferDto.DestinationWarehouseId); response != nil {
+		return response
+	}
+	if response := p.checkTransferItems(ctx, transferDto.Items); response != nil {
+		return response
+	}
+
+	transferId, err := repository.NextId(ctx, p.Db, "TransferOrder")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	transactions := []db.PrismaTransaction{
+		p.Db.TransferOrder.CreateOne(
+			db.TransferOrder.SourceWarehouse.Link(db.Warehouse.ID.Equals(transferDto.SourceWarehouseId)),
+			db.TransferOrder.DestinationWarehouse.Link(db.Warehouse.ID.Equals(transferDto.DestinationWarehouseId)),
+			db.TransferOrder.User.Link(db.User.ID.Equals(transferDto.UserId)),
+			db.TransferOrder.ID.Set(transferId),
+			db.TransferOrder.Note.SetIfPresent(optionalString(transferDto.Note)),
+		).Tx(),
+	}
+	transactions = append(transactions, p.transferItemWrites(transferId, transferDto.Items)...)
+	err = p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, transferDto.UserId, "Stock Transfer Created", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Stock transfer created",
+		Data: struct {
+			Id int `json:"id"`
+		}{
+			Id: transferId,
+		},
+	}
+}
+
+// UpdateTransferItems replaces the lines of a transfer that has not been dispatched yet.
+func (p *TransferService) UpdateTransferItems(ctx context.Context, transferDto *model.TransferItemsModel) *data.WebResponse {
+	validator := helpers.RequestValidators(transferDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	transfer, _ := p.Db.TransferOrder.FindUnique(db.TransferOrder.ID.Equals(transferDto.TransferId)).Exec(ctx)
+	if transfer == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Stock transfer not found",
+			Data:    nil,
+		}
+	}
+	if transfer.Status != db.TransferStatusDraft {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Only draft transfers can be changed",
+			Data:    nil,
+		}
+	}
+	if response := p.checkTransferItems(ctx, transferDto.Items); response != nil {
+		return response
+	}
+
+	transactions := []db.PrismaTransaction{
+		p.Db.TransferOrderItem.FindMany(db.TransferOrderItem.TransferOrderID.Equals(transferDto.TransferId)).Delete().Tx(),
+	}
+	transactions = append(transactions, p.transferItemWrites(transferDto.TransferId, transferDto.Items)...)
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, transferDto.UserId, "Stock Transfer Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock transfer updated",
+		Data:    nil,
+	}
+}
+
+// DispatchTransfer takes the transfer's items out of the source warehouse and puts the transfer in transit.
+// Shipping a line twice or dispatching a transfer that was cancelled meanwhile fails in the database.
+func (p *TransferService) DispatchTransfer(ctx context.Context, transferId int, userId int) *data.WebResponse {
+	transfer, _ := p.Db.TransferOrder.FindUnique(db.TransferOrder.ID.Equals(transferId)).With(
+		db.TransferOrder.Items.Fetch(),
+	).Exec(ctx)
+	if transfer == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Stock transfer not found",
+			Data:    nil,
+		}
+	}
+	if transfer.Status != db.TransferStatusDraft {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Only draft transfers can be dispatched",
+			Data:    nil,
+		}
+	}
+	if response := checkWarehouse(ctx, p.Db, transfer.SourceWarehouseID); response != nil {
+		return response
+	}
+
+	reference := transferReference(transferId)
+	transactions := []db.PrismaTransaction{
+		p.Db.Prisma.ExecuteRaw(
+			`UPDATE "TransferOrder" SET "status" = 'IN_TRANSIT', "dispatchedAt" = CURRENT_TIMESTAMP, "updatedAt" = CURRENT_TIMESTAMP WHERE "id" = $1 AND "status" = 'DRAFT'`,
+			transferId,
+		).Tx(),
+	}
+	for _, item := range transfer.Items() {
+		transactions = append(transactions, p.Db.TransferOrderItem.FindUnique(db.TransferOrderItem.ID.Equals(item.ID)).Update(
+			db.TransferOrderItem.ShippedQuantity.Increment(item.Quantity),
+		).Tx())
+		variantId := transferItemVariant(item)
+		outbound, _, err := repository.Outbound(ctx, p.Db, item.ProductID, variantId, transfer.SourceWarehouseID, db.StockMovementTypeTransfer, item.Quantity, "Transfer dispatched", &reference, &userId, false)
+		if errors.Is(err, repository.ErrExpiredStock) {
+			return &data.WebResponse{
+				Code:    http.StatusConflict,
+				Message: fmt.Sprintf("The remaining stock of product %d in the source warehouse has expired", item.ProductID),
+				Data:    nil,
+			}
+		}
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		transactions = append(transactions, outbound...)
+	}
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if response := transferWriteError(err); response != nil {
+		return response
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Stock Transfer Dispatched", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock transfer dispatched",
+		Data:    nil,
+	}
+}
+
+// ReceiveTransfer books a (partial) receipt into the destination warehouse. Quantities reported as a
+// discrepancy were shipped but will never arrive, they are recorded against the transfer instead of stocked.
+// The transfer is received once every shipped unit is accounted for.
+func (p *TransferService) ReceiveTransfer(ctx context.Context, receiptDto *model.TransferReceiptModel) *data.WebResponse {
+	validator := helpers.RequestValidators(receiptDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	transfer, _ := p.Db.TransferOrder.FindUnique(db.TransferOrder.ID.Equals(receiptDto.TransferId)).With(
+		db.TransferOrder.Items.Fetch(),
+	).Exec(ctx)
+	if transfer == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Stock transfer not found",
+			Data:    nil,
+		}
+	}
+	if transfer.Status != db.TransferStatusInTransit {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Only transfers in transit can be received",
+			Data:    nil,
+		}
+	}
+
+	items := map[stockLine]db.TransferOrderItemModel{}
+	for _, item := range transfer.Items() {
+		items[newStockLine(item.ProductID, transferItemVariant(item))] = item
+	}
+	reference := transferReference(transfer.ID)
+	var transactions []db.PrismaTransaction
+	seen := map[stockLine]bool{}
+	for _, line := range receiptDto.Items {
+		stock := newStockLine(line.ProductId, line.VariantId)
+		item, ok := items[stock]
+		if !ok {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("%s is not part of this transfer", stock.label()),
+				Data:    nil,
+			}
+		}
+		if seen[stock] {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("%s is listed more than once", stock.label()),
+				Data:    nil,
+			}
+		}
+		seen[stock] = true
+		outstanding := item.ShippedQuantity - item.ReceivedQuantity - item.DiscrepancyQuantity
+		if line.Received+line.Discrepancy == 0 || line.Received+line.Discrepancy > outstanding {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("%s has %d units outstanding", stock.label(), outstanding),
+				Data:    nil,
+			}
+		}
+
+		transactions = append(transactions, p.Db.TransferOrderItem.FindUnique(db.TransferOrderItem.ID.Equals(item.ID)).Update(
+			db.TransferOrderItem.ReceivedQuantity.Increment(line.Received),
+			db.TransferOrderItem.DiscrepancyQuantity.Increment(line.Discrepancy),
+		).Tx())
+		if line.Received > 0 {
+			received, err := p.receiveTransferLots(ctx, transfer, stock, line.Received, reference, receiptDto.UserId)
+			if err != nil {
+				return &data.WebResponse{
+					Code:    http.StatusInternalServerError,
+					Message: err.Error(),
+					Data:    nil,
+				}
+			}
+			transactions = append(transactions, received...)
+		}
+		if line.Discrepancy > 0 {
+			note := line.Note
+			if note == "" {
+				note = "Not received"
+			}
+			discrepancy := []db.TransferDiscrepancySetParam{}
+			if line.VariantId != nil {
+				discrepancy = append(discrepancy, db.TransferDiscrepancy.Variant.Link(db.ProductVariant.ID.Equals(*line.VariantId)))
+			}
+			transactions = append(transactions, p.Db.TransferDiscrepancy.CreateOne(
+				db.TransferDiscrepancy.TransferOrder.Link(db.TransferOrder.ID.Equals(transfer.ID)),
+				db.TransferDiscrepancy.Product.Link(db.Product.ID.Equals(line.ProductId)),
+				db.TransferDiscrepancy.Quantity.Set(line.Discrepancy),
+				db.TransferDiscrepancy.Note.Set(note),
+				db.TransferDiscrepancy.User.Link(db.User.ID.Equals(receiptDto.UserId)),
+				discrepancy...,
+			).Tx())
+		}
+	}
+	transactions = append(transactions, p.Db.Prisma.ExecuteRaw(
+		`UPDATE "TransferOrder" SET "status" = 'RECEIVED', "receivedAt" = CURRENT_TIMESTAMP, "updatedAt" = CURRENT_TIMESTAMP
+		WHERE "id" = $1 AND "status" = 'IN_TRANSIT' AND NOT EXISTS (
+			SELECT 1 FROM "TransferOrderItem"
+			WHERE "transferOrderId" = $1 AND "receivedQuantity" + "discrepancyQuantity" < "shippedQuantity"
+		)`,
+		transfer.ID,
+	).Tx())
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if response := transferWriteError(err); response != nil {
+		return response
+	}
+	p.Cache.Invalidate(ctx, cache.Products, cache.Categories)
+
+	err = repository.AuditLogs(ctx, p.Db, receiptDto.UserId, "Stock Transfer Received", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock transfer receipt recorded",
+		Data:    nil,
+	}
+}
+
+func (p *TransferService) CancelTransfer(ctx context.Context, transferId int, userId int) *data.WebResponse {
+	result, err := p.Db.Prisma.ExecuteRaw(
+		`UPDATE "TransferOrder" SET "status" = 'CANCELLED', "updatedAt" = CURRENT_TIMESTAMP WHERE "id" = $1 AND "status" = 'DRAFT'`,
+		transferId,
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if result.Count == 0 {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Only draft transfers can be cancelled",
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Stock Transfer Cancelled", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock transfer cancelled",
+		Data:    nil,
+	}
+}
+
+func (p *TransferService) GetTransferById(ctx context.Context, transferId int) *data.WebResponse {
+	transfer, _ := p.Db.TransferOrder.FindUnique(db.TransferOrder.ID.Equals(transferId)).With(
+		db.TransferOrder.Items.Fetch().With(db.TransferOrderItem.Product.Fetch()),
+		db.TransferOrder.Discrepancies.Fetch(),
+	).Exec(ctx)
+	if transfer == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Stock transfer not found",
+			Data:    nil,
+		}
+	}
+
+	response := transferResponse(*transfer)
+	for _, item := range transfer.Items() {
+		response.Items = append(response.Items, model.TransferItemResponse{
+			ProductId:   item.ProductID,
+			VariantId:   transferItemVariant(item),
+			ProductName: item.Product().Name,
+			Quantity:    item.Quantity,
+			Shipped:     item.ShippedQuantity,
+			Received:    item.ReceivedQuantity,
+			Discrepancy: item.DiscrepancyQuantity,
+			Outstanding: item.ShippedQuantity - item.ReceivedQuantity - item.DiscrepancyQuantity,
+		})
+	}
+	for _, discrepancy := range transfer.Discrepancies() {
+		discrepancyResponse := model.TransferDiscrepancyResponse{
+			ProductId: discrepancy.ProductID,
+			Quantity:  discrepancy.Quantity,
+			Note:      discrepancy.Note,
+			UserId:    discrepancy.UserID,
+			CreatedAt: discrepancy.CreatedAt,
+		}
+		if variantId, ok := discrepancy.VariantID(); ok {
+			discrepancyResponse.VariantId = &variantId
+		}
+		response.Discrepancies = append(response.Discrepancies, discrepancyResponse)
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock transfer found",
+		Data:    response,
+	}
+}
+
+// GetTransfers lists transfers, newest first, optionally by status and by a warehouse on either end.
+func (p *TransferService) GetTransfers(ctx context.Context, status string, warehouseId int) *data.WebResponse {
+	var filters []db.TransferOrderWhereParam
+	if status != "" {
+		filters = append(filters, db.TransferOrder.Status.Equals(db.TransferStatus(strings.ToUpper(status))))
+	}
+	if warehouseId != 0 {
+		filters = append(filters, db.TransferOrder.Or(
+			db.TransferOrder.SourceWarehouseID.Equals(warehouseId),
+			db.TransferOrder.DestinationWarehouseID.Equals(warehouseId),
+		))
+	}
+	transfers, err := p.Db.TransferOrder.FindMany(filters...).OrderBy(
+		db.TransferOrder.CreatedAt.Order(db.SortOrderDesc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var TransferResponses []model.TransferResponse
+	for _, transfer := range transfers {
+		TransferResponses = append(TransferResponses, transferResponse(transfer))
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Stock transfers",
+		Data:    TransferResponses,
+	}
+}
+
+func (p *TransferService) checkTransferItems(ctx context.Context, items []model.TransferItemModel) *data.WebResponse {
+	seen := map[stockLine]bool{}
+	products := map[int]bool{}
+	var productIds []int
+	for _, item := range items {
+		line := newStockLine(item.ProductId, item.VariantId)
+		if seen[line] {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("%s is listed more than once", line.label()),
+				Data:    nil,
+			}
+		}
+		seen[line] = true
+		if !products[item.ProductId] {
+			products[item.ProductId] = true
+			productIds = append(productIds, item.ProductId)
+		}
+	}
+	found, err := p.Db.Product.FindMany(
+		db.Product.ID.In(productIds),
+		db.Product.DeletedAt.IsNull(),
+		db.Product.Type.Equals(db.ProductTypeStandard),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if len(found) != len(productIds) {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found, bundles cannot be transferred",
+			Data:    nil,
+		}
+	}
+	for _, item := range items {
+		if response := checkVariant(ctx, p.Db, item.ProductId, item.VariantId); response != nil {
+			return response
+		}
+	}
+	return nil
+}
+
+func (p *TransferService) transferItemWrites(transferId int, items []model.TransferItemModel) []db.PrismaTransaction {
+	var transactions []db.PrismaTransaction
+	for _, item := range items {
+		variant := []db.TransferOrderItemSetParam{}
+		if item.VariantId != nil {
+			variant = append(variant, db.TransferOrderItem.Variant.Link(db.ProductVariant.ID.Equals(*item.VariantId)))
+		}
+		transactions = append(transactions, p.Db.TransferOrderItem.CreateOne(
+			db.TransferOrderItem.TransferOrder.Link(db.TransferOrder.ID.Equals(transferId)),
+			db.TransferOrderItem.Product.Link(db.Product.ID.Equals(item.ProductId)),
+			db.TransferOrderItem.Quantity.Set(item.Quantity),
+			variant...,
+		).Tx())
+	}
+	return transactions
+}
+
+// transferItemVariant is the variant whose stock a transfer line moves, nil for the product's own stock.
+func transferItemVariant(item db.TransferOrderItemModel) *int {
+	if variantId, ok := item.VariantID(); ok {
+		return &variantId
+	}
+	return nil
+}
+
+// transferWriteError turns the database refusing a dispatch or receipt into a response.
+func transferWriteError(err error) *data.WebResponse {
+	switch {
+	case err == nil:
+		return nil
+	case repository.InsufficientStock(err):
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Insufficient stock in the source warehouse",
+			Data:    nil,
+		}
+	case strings.Contains(err.Error(), "TransferOrderItem_quantity_check"), strings.Contains(err.Error(), "is not in transit"):
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Stock transfer was changed by another request, reload it and try again",
+			Data:    nil,
+		}
+	default:
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+}
+
+func transferReference(transferId int) string {
+	return fmt.Sprintf("TR-%d", transferId)
+}
+
+func transferResponse(transfer db.TransferOrderModel) model.TransferResponse {
+	note, _ := transfer.Note()
+	response := model.TransferResponse{
+		Id:                     transfer.ID,
+		SourceWarehouseId:      transfer.SourceWarehouseID,
+		DestinationWarehouseId: transfer.DestinationWarehouseID,
+		Status:                 string(transfer.Status),
+		Note:                   note,
+		UserId:                 transfer.UserID,
+		CreatedAt:              transfer.CreatedAt,
+	}
+	if dispatchedAt, ok := transfer.DispatchedAt(); ok {
+		response.DispatchedAt = &dispatchedAt
+	}
+	if receivedAt, ok := transfer.ReceivedAt(); ok {
+		response.ReceivedAt = &receivedAt
+	}
+	return response
+}
+
+// lotInTransit is a lot shipped on a transfer that has not all arrived yet.
+type lotInTransit struct {
+	LotNumber      string     `json:"lotNumber"`
+	ManufacturedAt *time.Time `json:"manufacturedAt"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+	Quantity       int        `json:"quantity"`
+}
+
+// receiveTransferLots returns the writes that stock received units of a product in the destination warehouse in
+// the lots they were shipped from, soonest expiring first. Units shipped from unlotted stock arrive unlotted, and
+// variant stock, which has no lots, arrives as it is.
+func (p *TransferService) receiveTransferLots(ctx context.Context, transfer *db.TransferOrderModel, line stockLine, received int, reference string, userId int) ([]db.PrismaTransaction, error) {
+	if line.variant() != nil {
+		return repository.StockMovement(p.Db, line.productId, line.variant(), transfer.DestinationWarehouseID, db.StockMovementTypeTransfer, received, "Transfer received", &reference, &userId, nil), nil
+	}
+	productId := line.productId
+	var lots []lotInTransit
+	err := p.Db.Prisma.QueryRaw(`
+		SELECT l."lotNumber", MAX(l."manufacturedAt") AS "manufacturedAt", MAX(l."expiresAt") AS "expiresAt",
+		       CAST(-SUM(m."quantity") AS INTEGER) AS "quantity"
+		FROM "StockMovement" m
+		JOIN "InventoryLot" l ON l."id" = m."lotId"
+		WHERE m."reference" = $1 AND m."productId" = $2 AND m."type" = 'TRANSFER'
+		GROUP BY l."lotNumber"
+		HAVING SUM(m."quantity") < 0
+		ORDER BY 3 NULLS LAST, 1`,
+		reference, productId,
+	).Exec(ctx, &lots)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []db.PrismaTransaction
+	for _, lot := range lots {
+		take := min(lot.Quantity, received)
+		if take == 0 {
+			break
+		}
+		transactions = append(transactions, repository.LotStockMovement(p.Db, productId, transfer.DestinationWarehouseID,
+			&repository.Lot{Number: lot.LotNumber, ManufacturedAt: lot.ManufacturedAt, ExpiresAt: lot.ExpiresAt},
+			db.StockMovementTypeTransfer, take, "Transfer received", &reference, &userId, nil)...)
+		received -= take
+	}
+	if received > 0 {
+		transactions = append(transactions, repository.StockMovement(p.Db, productId, nil, transfer.DestinationWarehouseID, db.StockMovementTypeTransfer, received, "Transfer received", &reference, &userId, nil)...)
+	}
+	return transactions, nil
+}
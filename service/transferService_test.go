@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestTransferWriteError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int // Response code, 0 for no response
+	}{
+		{"no error", nil, 0},
+		{"source warehouse ran out", errors.New(`new row for relation "Inventory" violates check constraint "Inventory_quantity_check"`), http.StatusConflict},
+		{"stock reserved meanwhile", errors.New(`violates check constraint "Inventory_reserved_check"`), http.StatusConflict},
+		{"line received twice", errors.New(`violates check constraint "TransferOrderItem_quantity_check"`), http.StatusConflict},
+		{"transfer cancelled meanwhile", errors.New("TransferOrder 7 is not in transit"), http.StatusConflict},
+		{"anything else", errors.New("connection reset"), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := transferWriteError(test.err)
+			code := 0
+			if response != nil {
+				code = response.Code
+			}
+			if code != test.want {
+				t.Errorf("transferWriteError(%v) answers %d, want %d", test.err, code, test.want)
+			}
+		})
+	}
+}
+
+func TestStockLineLabel(t *testing.T) {
+	variantId := 4
+	tests := []struct {
+		line stockLine
+		want string
+	}{
+		{newStockLine(3, nil), "Product 3"},
+		{newStockLine(3, &variantId), "Variant 4 of product 3"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := test.line.label(); got != test.want {
+				t.Errorf("label() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
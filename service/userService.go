@@ -109,6 +109,7 @@ func (p *UserService) CreateUserByAdmin(ctx context.Context, userModel *model.Us
 		db.User.Email.Set(userModel.Email),
 		db.User.FirstName.Set(userModel.Name),
 		db.User.Role.Link(db.Role.ID.Equals(userModel.RoleId)),
+		db.User.CustomerGroup.SetIfPresent(customerGroup(userModel.CustomerGroup)),
 	).Exec(ctx)
 	if err != nil {
 		return &data.WebResponse{
@@ -224,8 +225,19 @@ func (p *UserService) UpdateUserInfo(ctx context.Context, userDto *model.UserCre
 		}
 	}
 
-	existingUser, _ := p.Db.User.FindUnique(db.User.Email.Equals(userDto.Email)).Exec(ctx)
-	if existingUser != nil {
+	existingUser, _ := p.Db.User.FindUnique(db.User.ID.Equals(userDto.UserId)).Exec(ctx)
+	if existingUser == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+		}
+	}
+	if response := helpers.CheckETag(userDto.IfMatch, existingUser.UpdatedAt, "User"); response != nil {
+		return response
+	}
+	emailOwner, _ := p.Db.User.FindUnique(db.User.Email.Equals(userDto.Email)).Exec(ctx)
+	if emailOwner != nil && emailOwner.ID != userDto.UserId {
 		return &data.WebResponse{
 			Code:    http.StatusBadRequest,
 			Message: "User email already in use",
@@ -237,6 +249,7 @@ func (p *UserService) UpdateUserInfo(ctx context.Context, userDto *model.UserCre
 		db.User.Email.Set(userDto.Email),
 		db.User.Role.Link(db.Role.ID.Equals(userDto.RoleId)),
 		db.User.FirstName.Set(userDto.Name),
+		db.User.CustomerGroup.SetIfPresent(customerGroup(userDto.CustomerGroup)),
 	).Exec(ctx)
 	if err != nil {
 		return &data.WebResponse{
@@ -253,8 +266,22 @@ func (p *UserService) UpdateUserInfo(ctx context.Context, userDto *model.UserCre
 	}
 }
 
-func (p *UserService) DeactivateUser(ctx context.Context, userId int) *data.WebResponse {
-	_, err := p.Db.User.FindUnique(db.User.ID.Equals(userId)).Update(db.User.State.Set(db.StateEnumDisabled)).Exec(ctx)
+func (p *UserService) DeactivateUser(ctx context.Context, userId int, ifMatch string) *data.WebResponse {
+	existingUser, _ := p.Db.User.FindUnique(db.User.ID.Equals(userId)).Exec(ctx)
+	if existingUser == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+		}
+	}
+	if response := helpers.CheckETag(ifMatch, existingUser.UpdatedAt, "User"); response != nil {
+		return response
+	}
+	result, err := p.Db.User.FindMany(
+		db.User.ID.Equals(userId),
+		db.User.UpdatedAt.Equals(existingUser.UpdatedAt),
+	).Update(db.User.State.Set(db.StateEnumDisabled)).Exec(ctx)
 	if err != nil {
 		return &data.WebResponse{
 			Code:    http.StatusBadRequest,
@@ -262,6 +289,9 @@ func (p *UserService) DeactivateUser(ctx context.Context, userId int) *data.WebR
 			Data:    nil,
 		}
 	}
+	if result.Count == 0 {
+		return helpers.PreconditionFailed("User")
+	}
 	return &data.WebResponse{
 		Code:    http.StatusOK,
 		Message: "User deactivated",
@@ -269,8 +299,22 @@ func (p *UserService) DeactivateUser(ctx context.Context, userId int) *data.WebR
 	}
 }
 
-func (p *UserService) DeleteUser(ctx context.Context, userId int) *data.WebResponse {
-	_, err := p.Db.User.FindUnique(db.User.ID.Equals(userId)).Update(db.User.State.Set(db.StateEnumDeleted)).Exec(ctx)
+func (p *UserService) DeleteUser(ctx context.Context, userId int, ifMatch string) *data.WebResponse {
+	existingUser, _ := p.Db.User.FindUnique(db.User.ID.Equals(userId)).Exec(ctx)
+	if existingUser == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+		}
+	}
+	if response := helpers.CheckETag(ifMatch, existingUser.UpdatedAt, "User"); response != nil {
+		return response
+	}
+	result, err := p.Db.User.FindMany(
+		db.User.ID.Equals(userId),
+		db.User.UpdatedAt.Equals(existingUser.UpdatedAt),
+	).Update(db.User.State.Set(db.StateEnumDeleted)).Exec(ctx)
 	if err != nil {
 		return &data.WebResponse{
 			Code:    http.StatusBadRequest,
@@ -278,6 +322,9 @@ func (p *UserService) DeleteUser(ctx context.Context, userId int) *data.WebRespo
 			Data:    nil,
 		}
 	}
+	if result.Count == 0 {
+		return helpers.PreconditionFailed("User")
+	}
 	return &data.WebResponse{
 		Code:    http.StatusOK,
 		Message: "User deleted!",
@@ -396,10 +443,26 @@ func (p *UserService) ChangeUserInfo(ctx context.Context, userDto *model.UpdateU
 			Data:    nil,
 		}
 	}
-	_, _ = p.Db.User.FindUnique(db.User.ID.Equals(userDto.UserId)).Update(
+	if response := helpers.CheckETag(userDto.IfMatch, existingUser.UpdatedAt, "User"); response != nil {
+		return response
+	}
+	result, err := p.Db.User.FindMany(
+		db.User.ID.Equals(userDto.UserId),
+		db.User.UpdatedAt.Equals(existingUser.UpdatedAt),
+	).Update(
 		db.User.FirstName.Set(userDto.FirstName),
 		db.User.LastName.Set(userDto.LastName),
 	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if result.Count == 0 {
+		return helpers.PreconditionFailed("User")
+	}
 	return &data.WebResponse{
 		Code:    http.StatusOK,
 		Message: "Data updated",
@@ -407,12 +470,44 @@ func (p *UserService) ChangeUserInfo(ctx context.Context, userDto *model.UpdateU
 	}
 }
 
+func (p *UserService) GetUserById(ctx context.Context, userId int) *data.WebResponse {
+	user, _ := p.Db.User.FindUnique(db.User.ID.Equals(userId)).With(db.User.Role.Fetch()).Exec(ctx)
+	if user == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "User not found",
+			Data:    nil,
+		}
+	}
+
+	lastName, _ := user.LastName()
+	response := model.UserResponse{
+		Id:            user.ID,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      lastName,
+		CustomerGroup: string(user.CustomerGroup),
+		CreatedAt:     user.CreatedAt,
+	}
+	response.Role.Id = user.Role().ID
+	response.Role.Name = user.Role().Name
+	response.Role.Permissions = string(user.Role().Permissions)
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "User found",
+		Data:    response,
+		ETag:    helpers.ETag(user.UpdatedAt),
+	}
+}
+
 func (p *UserService) GetAllUsers(ctx context.Context) *data.WebResponse {
 	users, err := p.Db.User.FindMany().Select(
 		db.User.Email.Field(),
 		db.User.ID.Field(),
 		db.User.FirstName.Field(),
 		db.User.LastName.Field(),
+		db.User.CustomerGroup.Field(),
 	).With(
 		db.User.Role.Fetch(),
 	).Exec(ctx)
@@ -428,11 +523,12 @@ func (p *UserService) GetAllUsers(ctx context.Context) *data.WebResponse {
 	for _, user := range users {
 		lastName, _ := user.LastName()
 		UserResponses = append(UserResponses, model.UserResponse{
-			Id:        user.ID,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  lastName,
-			CreatedAt: user.CreatedAt,
+			Id:            user.ID,
+			Email:         user.Email,
+			FirstName:     user.FirstName,
+			LastName:      lastName,
+			CustomerGroup: string(user.CustomerGroup),
+			CreatedAt:     user.CreatedAt,
 			Role: struct {
 				Id          int    `json:"id"`
 				Name        string `json:"name"`
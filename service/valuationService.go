@@ -0,0 +1,495 @@
+package service
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"errors"
+	"golang.org/x/net/context"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// costEvent is a change to the quantity of a product. Transfers move stock within the company and leave its
+// value alone, units lost on a transfer leave as a discrepancy, which belongs to no warehouse.
+type costEvent struct {
+	Type        string
+	Quantity    int
+	UnitCost    *float64
+	At          time.Time
+	WarehouseId int
+}
+
+// valuationBatch is how many products' ledgers are read at a time.
+const valuationBatch = 200
+
+// costLayer is a receipt under FIFO, UnitCost is nil for units that came in before the product had any cost.
+type costLayer struct {
+	Quantity int
+	UnitCost *float64
+}
+
+// costPool values the stock of one product. Under FIFO it keeps a layer per receipt and issues the oldest
+// first, under weighted average a single layer whose cost is re-averaged on every receipt. Units that came in
+// without a cost while the product had none are counted as Uncosted instead of being valued at zero.
+type costPool struct {
+	Method   db.ValuationMethod
+	Layers   []costLayer
+	Quantity int // Units with a cost
+	Value    float64
+	LastCost float64
+	Costed   bool // Whether any unit had a cost yet, LastCost is only known once it did
+	Uncosted int
+}
+
+type ValuationService struct {
+	Db *db.PrismaClient
+}
+
+func NewValuationService(db *db.PrismaClient) *ValuationService {
+	return &ValuationService{Db: db}
+}
+
+func (p *ValuationService) GetValuationMethod(ctx context.Context) *data.WebResponse {
+	method, err := valuationMethod(ctx, p.Db)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Valuation method",
+		Data: struct {
+			Method string `json:"method"`
+		}{
+			Method: string(method),
+		},
+	}
+}
+
+// SetValuationMethod changes how the company values its stock. Valuations are computed from the ledger, so
+// the new method applies to past dates as well.
+func (p *ValuationService) SetValuationMethod(ctx context.Context, methodDto *model.ValuationMethodModel) *data.WebResponse {
+	validator := helpers.RequestValidators(methodDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+
+	method := db.ValuationMethod(methodDto.Method)
+	_, err := p.Db.CompanySetting.UpsertOne(db.CompanySetting.ID.Equals(1)).Create(
+		db.CompanySetting.ValuationMethod.Set(method),
+	).Update(
+		db.CompanySetting.ValuationMethod.Set(method),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, methodDto.UserId, "Valuation Method Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Valuation method updated",
+		Data:    nil,
+	}
+}
+
+// GetInventoryValuation values the stock the company held at asOf, including stock in transit between its
+// warehouses, or only the stock held in filter's warehouse. A warehouse's units are valued at the cost of all
+// the product's units. method overrides the company's valuation method when set. Units without a cost are
+// reported as uncosted and left out of the value.
+func (p *ValuationService) GetInventoryValuation(ctx context.Context, asOf time.Time, method string, filter model.ValuationFilterModel) *data.WebResponse {
+	valuation, response := p.method(ctx, method)
+	if response != nil {
+		return response
+	}
+	productIds, err := p.valuationProducts(ctx, asOf, filter)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	report := model.ValuationResponse{Method: string(valuation), AsOf: asOf, Items: []model.ValuationItemResponse{}}
+	for start := 0; start < len(productIds); start += valuationBatch {
+		batch := productIds[start:min(start+valuationBatch, len(productIds))]
+		events, names, err := p.costEvents(ctx, asOf, batch)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		for _, productId := range batch {
+			item, value := stockValue(valuation, events[productId], filter.WarehouseId)
+			if item.Quantity == 0 {
+				continue
+			}
+			item.ProductId = productId
+			item.ProductName = names[productId]
+			report.Items = append(report.Items, item)
+			report.TotalValue += value
+			report.UncostedQuantity += item.UncostedQuantity
+		}
+	}
+	report.TotalValue = roundMoney(report.TotalValue)
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Inventory valuation",
+		Data:    report,
+	}
+}
+
+// GetCostOfGoodsSold is the cost of the units shipped between from and to, less the cost of the units returned,
+// optionally only those shipped from or returned to filter's warehouse. Units shipped or returned without a cost
+// are reported as uncosted.
+func (p *ValuationService) GetCostOfGoodsSold(ctx context.Context, from time.Time, to time.Time, method string, filter model.ValuationFilterModel) *data.WebResponse {
+	if !from.Before(to) {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "from must be before to",
+			Data:    nil,
+		}
+	}
+	valuation, response := p.method(ctx, method)
+	if response != nil {
+		return response
+	}
+	productIds, err := p.valuationProducts(ctx, to, filter)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	report := model.CostOfGoodsSoldResponse{Method: string(valuation), From: from, To: to, Items: []model.CostOfGoodsSoldItemResponse{}}
+	for start := 0; start < len(productIds); start += valuationBatch {
+		batch := productIds[start:min(start+valuationBatch, len(productIds))]
+		events, names, err := p.costEvents(ctx, to, batch)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+		for _, productId := range batch {
+			item, sold := costOfGoodsSold(valuation, events[productId], from, filter.WarehouseId)
+			if !sold {
+				continue
+			}
+			item.ProductId = productId
+			item.ProductName = names[productId]
+			report.Items = append(report.Items, item)
+			report.TotalCost += item.Cost
+			report.UncostedQuantity += item.UncostedQuantity
+		}
+	}
+	report.TotalCost = roundMoney(report.TotalCost)
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Cost of goods sold",
+		Data:    report,
+	}
+}
+
+// method is the valuation method asked for, or the company's when none is.
+func (p *ValuationService) method(ctx context.Context, method string) (db.ValuationMethod, *data.WebResponse) {
+	switch db.ValuationMethod(method) {
+	case db.ValuationMethodFifo, db.ValuationMethodWeightedAverage:
+		return db.ValuationMethod(method), nil
+	case "":
+	default:
+		return "", &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "method must be FIFO or WEIGHTED_AVERAGE",
+			Data:    nil,
+		}
+	}
+	valuation, err := valuationMethod(ctx, p.Db)
+	if err != nil {
+		return "", &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return valuation, nil
+}
+
+// valuationProducts lists, in order, the products with ledger entries up to and including to that filter lets
+// through: one product, or those that moved through one warehouse.
+func (p *ValuationService) valuationProducts(ctx context.Context, to time.Time, filter model.ValuationFilterModel) ([]int, error) {
+	var rows []struct {
+		ProductId int `json:"productId"`
+	}
+	err := p.Db.Prisma.QueryRaw(`
+		SELECT DISTINCT "productId"
+		FROM "StockMovement"
+		WHERE "createdAt" <= CAST($1 AS TIMESTAMP(3))
+		  AND ($2 = 0 OR "productId" = $2)
+		  AND ($3 = 0 OR "warehouseId" = $3)
+		ORDER BY "productId"`,
+		to.UTC().Format("2006-01-02T15:04:05.000"), filter.ProductId, filter.WarehouseId,
+	).Exec(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+	productIds := make([]int, 0, len(rows))
+	for _, row := range rows {
+		productIds = append(productIds, row.ProductId)
+	}
+	return productIds, nil
+}
+
+// costEvents reads the ledger of productIds up to and including to, per product in the order it happened, with
+// the names of the products.
+func (p *ValuationService) costEvents(ctx context.Context, to time.Time, productIds []int) (map[int][]costEvent, map[int]string, error) {
+	movements, err := p.Db.StockMovement.FindMany(
+		db.StockMovement.ProductID.In(productIds),
+		db.StockMovement.CreatedAt.Lte(to),
+	).With(
+		db.StockMovement.Cost.Fetch(),
+	).OrderBy(
+		db.StockMovement.ID.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	discrepancies, err := p.Db.TransferDiscrepancy.FindMany(
+		db.TransferDiscrepancy.ProductID.In(productIds),
+		db.TransferDiscrepancy.CreatedAt.Lte(to),
+	).OrderBy(
+		db.TransferDiscrepancy.ID.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := map[int][]costEvent{}
+	for _, movement := range movements {
+		event := costEvent{Type: string(movement.Type), Quantity: movement.Quantity, At: movement.CreatedAt, WarehouseId: movement.WarehouseID}
+		if cost, ok := movement.Cost(); ok {
+			event.UnitCost = &cost.UnitCost
+		}
+		events[movement.ProductID] = append(events[movement.ProductID], event)
+	}
+	for _, discrepancy := range discrepancies {
+		events[discrepancy.ProductID] = append(events[discrepancy.ProductID], costEvent{
+			Type:     "DISCREPANCY",
+			Quantity: -discrepancy.Quantity,
+			At:       discrepancy.CreatedAt,
+		})
+	}
+	for _, productEvents := range events {
+		sort.SliceStable(productEvents, func(i, j int) bool {
+			return productEvents[i].At.Before(productEvents[j].At)
+		})
+	}
+
+	names := map[int]string{}
+	products, err := p.Db.Product.FindMany(db.Product.ID.In(productIds)).Exec(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, product := range products {
+		names[product.ID] = product.Name
+	}
+	return events, names, nil
+}
+
+// stockValue values what one product's events leave in stock, or only what they leave in warehouseId when it is
+// set. The value is returned unrounded for the report's total.
+func stockValue(method db.ValuationMethod, events []costEvent, warehouseId int) (model.ValuationItemResponse, float64) {
+	pool := &costPool{Method: method}
+	held := 0
+	for _, event := range events {
+		pool.apply(event)
+		if event.WarehouseId == warehouseId {
+			held += event.Quantity
+		}
+	}
+	item := model.ValuationItemResponse{Quantity: pool.Quantity + pool.Uncosted, UncostedQuantity: pool.Uncosted}
+	value := pool.Value
+	if warehouseId != 0 {
+		item.Quantity = held
+		value, item.UncostedQuantity = pool.portion(held)
+	}
+	item.Value = roundMoney(value)
+	if costed := item.Quantity - item.UncostedQuantity; costed > 0 {
+		item.UnitCost = roundMoney(value / float64(costed))
+	}
+	return item, value
+}
+
+// costOfGoodsSold values the shipments and returns of one product from from on, only those of warehouseId when
+// it is set. The events before from and those of other warehouses only build up the pool the others are costed
+// from. sold is false when nothing was shipped or returned.
+func costOfGoodsSold(method db.ValuationMethod, events []costEvent, from time.Time, warehouseId int) (model.CostOfGoodsSoldItemResponse, bool) {
+	pool := &costPool{Method: method}
+	item := model.CostOfGoodsSoldItemResponse{}
+	sold := false
+	for _, event := range events {
+		cost, uncosted := pool.apply(event)
+		if event.At.Before(from) || (warehouseId != 0 && event.WarehouseId != warehouseId) {
+			continue
+		}
+		switch db.StockMovementType(event.Type) {
+		case db.StockMovementTypeShipment:
+			item.QuantitySold -= event.Quantity
+			item.UncostedQuantity += uncosted
+			item.Cost += cost
+			sold = true
+		case db.StockMovementTypeReturn:
+			item.QuantitySold -= event.Quantity
+			item.UncostedQuantity -= uncosted
+			item.Cost -= cost
+			sold = true
+		}
+	}
+	item.Cost = roundMoney(item.Cost)
+	return item, sold
+}
+
+// apply books an event in the pool and returns the cost of the units it brought in or took out, and how many
+// of them had no cost.
+func (pool *costPool) apply(event costEvent) (float64, int) {
+	if event.Type == string(db.StockMovementTypeTransfer) {
+		return 0, 0
+	}
+	if event.Quantity > 0 {
+		return pool.receive(event.Quantity, event.UnitCost)
+	}
+	return pool.issue(-event.Quantity)
+}
+
+// receive adds units at their unit cost, or at the current cost when they came in without one. Units without a
+// cost that come in before the product had any stay uncosted.
+func (pool *costPool) receive(quantity int, unitCost *float64) (float64, int) {
+	if unitCost == nil && !pool.Costed {
+		if pool.Method == db.ValuationMethodFifo {
+			pool.Layers = append(pool.Layers, costLayer{Quantity: quantity})
+		}
+		pool.Uncosted += quantity
+		return 0, quantity
+	}
+	cost := pool.currentCost()
+	if unitCost != nil {
+		cost = *unitCost
+	}
+	if pool.Method == db.ValuationMethodFifo {
+		pool.Layers = append(pool.Layers, costLayer{Quantity: quantity, UnitCost: &cost})
+	}
+	pool.Quantity += quantity
+	pool.Value += float64(quantity) * cost
+	pool.LastCost = cost
+	pool.Costed = true
+	return float64(quantity) * cost, 0
+}
+
+// issue takes units out and returns their cost and how many of them had none. FIFO takes the oldest layers
+// first, weighted average takes the units with a cost before the uncosted ones.
+func (pool *costPool) issue(quantity int) (float64, int) {
+	var cost float64
+	uncosted := 0
+	remaining := quantity
+	if pool.Method == db.ValuationMethodFifo {
+		for remaining > 0 && len(pool.Layers) > 0 {
+			layer := &pool.Layers[0]
+			take := min(layer.Quantity, remaining)
+			if layer.UnitCost == nil {
+				uncosted += take
+				pool.Uncosted -= take
+			} else {
+				cost += float64(take) * *layer.UnitCost
+				pool.Quantity -= take
+			}
+			layer.Quantity -= take
+			if layer.Quantity == 0 {
+				pool.Layers = pool.Layers[1:]
+			}
+			remaining -= take
+		}
+	} else {
+		take := min(max(pool.Quantity, 0), remaining)
+		cost = float64(take) * pool.currentCost()
+		pool.Quantity -= take
+		remaining -= take
+		take = min(max(pool.Uncosted, 0), remaining)
+		uncosted += take
+		pool.Uncosted -= take
+		remaining -= take
+	}
+	// Units taken beyond what the pool holds leave at the last known cost.
+	if remaining > 0 && pool.Costed {
+		cost += float64(remaining) * pool.LastCost
+		pool.Quantity -= remaining
+	} else if remaining > 0 {
+		uncosted += remaining
+		pool.Uncosted -= remaining
+	}
+	pool.Value -= cost
+	if pool.Quantity <= 0 {
+		pool.Value = 0
+	}
+	return cost, uncosted
+}
+
+// portion values quantity of the pool's units, e.g. those held in one warehouse, and returns how many of them
+// have no cost. Units with a cost are counted first and valued at the current cost.
+func (pool *costPool) portion(quantity int) (float64, int) {
+	costed := min(quantity, max(pool.Quantity, 0))
+	uncosted := min(quantity-costed, max(pool.Uncosted, 0))
+	// Units beyond what the pool holds are valued like the pool issues them, at the last known cost.
+	if rest := quantity - costed - uncosted; rest > 0 && pool.Costed {
+		costed += rest
+	} else if rest > 0 {
+		uncosted += rest
+	}
+	return float64(costed) * pool.currentCost(), uncosted
+}
+
+// currentCost is the average cost of the units in the pool, or the last known cost when it is empty.
+func (pool *costPool) currentCost() float64 {
+	if pool.Quantity > 0 {
+		return pool.Value / float64(pool.Quantity)
+	}
+	return pool.LastCost
+}
+
+// valuationMethod is the company's valuation method, FIFO until one is set.
+func valuationMethod(ctx context.Context, dbClient *db.PrismaClient) (db.ValuationMethod, error) {
+	setting, err := dbClient.CompanySetting.FindUnique(db.CompanySetting.ID.Equals(1)).Exec(ctx)
+	if errors.Is(err, db.ErrNotFound) {
+		return db.ValuationMethodFifo, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return setting.ValuationMethod, nil
+}
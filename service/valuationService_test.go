@@ -0,0 +1,287 @@
+package service
+
+import (
+	"Enterprise/prisma/db"
+	"testing"
+	"time"
+)
+
+func unitCost(cost float64) *float64 {
+	return &cost
+}
+
+func TestCostPool(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       db.ValuationMethod
+		events       []costEvent
+		wantCost     float64 // Cost of the last event
+		wantUncosted int     // Uncosted units of the last event
+		wantQuantity int
+		wantValue    float64
+		wantInPool   int // Uncosted units left in the pool
+	}{
+		{
+			name:   "fifo issues the oldest layer first",
+			method: db.ValuationMethodFifo,
+			events: []costEvent{
+				{Quantity: 10, UnitCost: unitCost(1)},
+				{Quantity: 10, UnitCost: unitCost(2)},
+				{Quantity: -15},
+			},
+			wantCost:     20,
+			wantQuantity: 5,
+			wantValue:    10,
+		},
+		{
+			name:   "weighted average issues at the average cost",
+			method: db.ValuationMethodWeightedAverage,
+			events: []costEvent{
+				{Quantity: 10, UnitCost: unitCost(1)},
+				{Quantity: 10, UnitCost: unitCost(2)},
+				{Quantity: -15},
+			},
+			wantCost:     22.5,
+			wantQuantity: 5,
+			wantValue:    7.5,
+		},
+		{
+			name:   "receipt without a cost comes in at the current cost",
+			method: db.ValuationMethodWeightedAverage,
+			events: []costEvent{
+				{Quantity: 4, UnitCost: unitCost(3)},
+				{Quantity: 2},
+			},
+			wantCost:     6,
+			wantQuantity: 6,
+			wantValue:    18,
+		},
+		{
+			name:   "fifo issues uncosted units in the order they came",
+			method: db.ValuationMethodFifo,
+			events: []costEvent{
+				{Quantity: 5},
+				{Quantity: 5, UnitCost: unitCost(2)},
+				{Quantity: -6},
+			},
+			wantCost:     2,
+			wantUncosted: 5,
+			wantQuantity: 4,
+			wantValue:    8,
+		},
+		{
+			name:   "weighted average issues costed units before uncosted ones",
+			method: db.ValuationMethodWeightedAverage,
+			events: []costEvent{
+				{Quantity: 5},
+				{Quantity: 5, UnitCost: unitCost(2)},
+				{Quantity: -6},
+			},
+			wantCost:     10,
+			wantUncosted: 1,
+			wantInPool:   4,
+		},
+		{
+			name:   "units without any cost stay uncosted",
+			method: db.ValuationMethodFifo,
+			events: []costEvent{
+				{Quantity: 3},
+				{Quantity: -5},
+			},
+			wantUncosted: 5,
+			wantInPool:   -2,
+		},
+		{
+			name:   "overdrawn stock leaves at the last cost",
+			method: db.ValuationMethodFifo,
+			events: []costEvent{
+				{Quantity: 2, UnitCost: unitCost(4)},
+				{Quantity: -3},
+			},
+			wantCost:     12,
+			wantQuantity: -1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pool := &costPool{Method: test.method}
+			var cost float64
+			var uncosted int
+			for _, event := range test.events {
+				cost, uncosted = pool.apply(event)
+			}
+			if cost != test.wantCost || uncosted != test.wantUncosted {
+				t.Errorf("last event cost %v with %d uncosted, want %v with %d", cost, uncosted, test.wantCost, test.wantUncosted)
+			}
+			if pool.Quantity != test.wantQuantity || pool.Value != test.wantValue || pool.Uncosted != test.wantInPool {
+				t.Errorf("pool holds %d worth %v with %d uncosted, want %d worth %v with %d",
+					pool.Quantity, pool.Value, pool.Uncosted, test.wantQuantity, test.wantValue, test.wantInPool)
+			}
+		})
+	}
+}
+
+func TestCostOfGoodsSold(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+	receipt := string(db.StockMovementTypeReceipt)
+	shipment := string(db.StockMovementTypeShipment)
+	ret := string(db.StockMovementTypeReturn)
+	tests := []struct {
+		name      string
+		method    db.ValuationMethod
+		events    []costEvent
+		from      time.Time
+		warehouse int
+		want      int // Quantity sold
+		cost      float64
+		uncost    int
+		sold      bool
+	}{
+		{
+			name:   "returns are taken off at the current cost",
+			method: db.ValuationMethodFifo,
+			events: []costEvent{
+				{Type: receipt, Quantity: 10, UnitCost: unitCost(2), At: day(1)},
+				{Type: shipment, Quantity: -4, At: day(2)},
+				{Type: ret, Quantity: 1, At: day(3)},
+			},
+			from: day(1),
+			want: 3,
+			cost: 6,
+			sold: true,
+		},
+		{
+			name:   "shipments before from only shape the pool",
+			method: db.ValuationMethodFifo,
+			events: []costEvent{
+				{Type: receipt, Quantity: 5, UnitCost: unitCost(1), At: day(1)},
+				{Type: receipt, Quantity: 5, UnitCost: unitCost(3), At: day(2)},
+				{Type: shipment, Quantity: -5, At: day(3)},
+				{Type: shipment, Quantity: -2, At: day(10)},
+			},
+			from: day(5),
+			want: 2,
+			cost: 6,
+			sold: true,
+		},
+		{
+			name:   "weighted average sells at the average of what came before",
+			method: db.ValuationMethodWeightedAverage,
+			events: []costEvent{
+				{Type: receipt, Quantity: 5, UnitCost: unitCost(1), At: day(1)},
+				{Type: receipt, Quantity: 5, UnitCost: unitCost(3), At: day(2)},
+				{Type: shipment, Quantity: -5, At: day(3)},
+				{Type: shipment, Quantity: -2, At: day(10)},
+			},
+			from: day(5),
+			want: 2,
+			cost: 4,
+			sold: true,
+		},
+		{
+			name:   "uncosted units sold and returned cancel out",
+			method: db.ValuationMethodFifo,
+			events: []costEvent{
+				{Type: receipt, Quantity: 3, At: day(1)},
+				{Type: shipment, Quantity: -3, At: day(2)},
+				{Type: ret, Quantity: 1, At: day(3)},
+			},
+			from:   day(1),
+			want:   2,
+			uncost: 2,
+			sold:   true,
+		},
+		{
+			name:   "a warehouse's shipments are costed from the company's pool",
+			method: db.ValuationMethodFifo,
+			events: []costEvent{
+				{Type: receipt, Quantity: 5, UnitCost: unitCost(1), At: day(1), WarehouseId: 1},
+				{Type: receipt, Quantity: 5, UnitCost: unitCost(3), At: day(2), WarehouseId: 2},
+				{Type: shipment, Quantity: -5, At: day(3), WarehouseId: 1},
+				{Type: shipment, Quantity: -2, At: day(4), WarehouseId: 2},
+			},
+			from:      day(1),
+			warehouse: 2,
+			want:      2,
+			cost:      6,
+			sold:      true,
+		},
+		{
+			name:   "nothing shipped in the period",
+			method: db.ValuationMethodFifo,
+			events: []costEvent{
+				{Type: receipt, Quantity: 3, UnitCost: unitCost(1), At: day(1)},
+				{Type: shipment, Quantity: -1, At: day(2)},
+			},
+			from: day(5),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item, sold := costOfGoodsSold(test.method, test.events, test.from, test.warehouse)
+			if sold != test.sold || item.QuantitySold != test.want || item.Cost != test.cost || item.UncostedQuantity != test.uncost {
+				t.Errorf("got %d sold for %v with %d uncosted (sold %v), want %d for %v with %d (sold %v)",
+					item.QuantitySold, item.Cost, item.UncostedQuantity, sold, test.want, test.cost, test.uncost, test.sold)
+			}
+		})
+	}
+}
+
+func TestStockValue(t *testing.T) {
+	receipt := string(db.StockMovementTypeReceipt)
+	shipment := string(db.StockMovementTypeShipment)
+	transfer := string(db.StockMovementTypeTransfer)
+	tests := []struct {
+		name      string
+		events    []costEvent
+		warehouse int
+		want      int // Quantity held
+		value     float64
+		unitCost  float64
+		uncost    int
+	}{
+		{
+			name: "the company's stock across warehouses",
+			events: []costEvent{
+				{Type: receipt, Quantity: 5, UnitCost: unitCost(1), WarehouseId: 1},
+				{Type: receipt, Quantity: 5, UnitCost: unitCost(3), WarehouseId: 2},
+				{Type: shipment, Quantity: -4, WarehouseId: 1},
+			},
+			want:     6,
+			value:    16,
+			unitCost: 2.67,
+		},
+		{
+			name: "transfers move units between warehouses at the company's cost",
+			events: []costEvent{
+				{Type: receipt, Quantity: 4, UnitCost: unitCost(2), WarehouseId: 1},
+				{Type: transfer, Quantity: -1, WarehouseId: 1},
+				{Type: transfer, Quantity: 1, WarehouseId: 2},
+			},
+			warehouse: 2,
+			want:      1,
+			value:     2,
+			unitCost:  2,
+		},
+		{
+			name: "a warehouse's units without a cost stay uncosted",
+			events: []costEvent{
+				{Type: receipt, Quantity: 3, WarehouseId: 1},
+			},
+			warehouse: 1,
+			want:      3,
+			uncost:    3,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item, _ := stockValue(db.ValuationMethodFifo, test.events, test.warehouse)
+			if item.Quantity != test.want || item.Value != test.value || item.UnitCost != test.unitCost || item.UncostedQuantity != test.uncost {
+				t.Errorf("got %d worth %v at %v with %d uncosted, want %d worth %v at %v with %d",
+					item.Quantity, item.Value, item.UnitCost, item.UncostedQuantity, test.want, test.value, test.unitCost, test.uncost)
+			}
+		})
+	}
+}
@@ -0,0 +1,508 @@
+package service
+
+import (
+	"Enterprise/cache"
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/net/context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type VariantService struct {
+	Db    *db.PrismaClient
+	Cache *cache.Cache
+}
+
+func NewVariantService(db *db.PrismaClient, catalogCache *cache.Cache) *VariantService {
+	return &VariantService{Db: db, Cache: catalogCache}
+}
+
+func (p *VariantService) SetProductOptions(ctx context.Context, optionsDto *model.ProductOptionsModel) *data.WebResponse {
+	validator := helpers.RequestValidators(optionsDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+
+	productExist, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(optionsDto.ProductId), db.Product.DeletedAt.IsNull()).Exec(ctx)
+	if productExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+	if response := checkVariantsAllowed(ctx, p.Db, *productExist); response != nil {
+		return response
+	}
+	if repository.ProductHasVariants(ctx, p.Db, optionsDto.ProductId) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Product options cannot change while the product has variants",
+			Data:    nil,
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, option := range optionsDto.Options {
+		name := strings.ToLower(option.Name)
+		if seen[name] {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Option %s is listed more than once", option.Name),
+				Data:    nil,
+			}
+		}
+		seen[name] = true
+	}
+
+	transactions := []db.PrismaTransaction{
+		p.Db.ProductOption.FindMany(db.ProductOption.ProductID.Equals(optionsDto.ProductId)).Delete().Tx(),
+	}
+	for _, option := range optionsDto.Options {
+		transactions = append(transactions, p.Db.ProductOption.CreateOne(
+			db.ProductOption.Product.Link(db.Product.ID.Equals(optionsDto.ProductId)),
+			db.ProductOption.Name.Set(strings.ToLower(option.Name)),
+			db.ProductOption.Values.Set(option.Values),
+		).Tx())
+	}
+	err := p.Db.Prisma.Transaction(transactions...).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	repository.TouchProduct(ctx, p.Db, optionsDto.ProductId)
+	p.Cache.Invalidate(ctx, cache.Products)
+
+	err = repository.AuditLogs(ctx, p.Db, optionsDto.UserId, "Product Options Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Product options updated",
+		Data:    nil,
+	}
+}
+
+func (p *VariantService) CreateVariant(ctx context.Context, variantDto *model.ProductVariantModel) *data.WebResponse {
+	validator := helpers.RequestValidators(variantDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+
+	product, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(variantDto.ProductId), db.Product.DeletedAt.IsNull()).With(
+		db.Product.Options.Fetch(),
+		db.Product.Variants.Fetch(),
+	).Exec(ctx)
+	if product == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+	if response := checkVariantsAllowed(ctx, p.Db, *product); response != nil {
+		return response
+	}
+
+	if repository.ExistingVariantBySku(ctx, p.Db, variantDto.Sku) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Variant SKU already exists",
+			Data:    nil,
+		}
+	}
+
+	options, err := normaliseVariantOptions(product.Options(), variantDto.Options)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	key := variantKey(options)
+	for _, variant := range product.Variants() {
+		if variantKey(decodeVariantOptions(variant.Options)) == key {
+			return &data.WebResponse{
+				Code:    http.StatusConflict,
+				Message: fmt.Sprintf("Variant %s already exists for this product", key),
+				Data:    nil,
+			}
+		}
+	}
+
+	// Opening stock is booked into a warehouse like any other stock.
+	if variantDto.Stock > 0 {
+		if variantDto.WarehouseId == nil {
+			return &data.WebResponse{
+				Code:    http.StatusBadRequest,
+				Message: "warehouseId is required when stock is given",
+				Data:    nil,
+			}
+		}
+		if response := checkWarehouse(ctx, p.Db, *variantDto.WarehouseId); response != nil {
+			return response
+		}
+	}
+
+	optionsJson, _ := json.Marshal(options)
+	variant, err := p.Db.ProductVariant.CreateOne(
+		db.ProductVariant.Product.Link(db.Product.ID.Equals(variantDto.ProductId)),
+		db.ProductVariant.Sku.Set(variantDto.Sku),
+		db.ProductVariant.Options.Set(optionsJson),
+		db.ProductVariant.Price.SetIfPresent(variantPrice(variantDto.Price)),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	if variantDto.Stock > 0 {
+		err = p.Db.Prisma.Transaction(repository.StockMovement(p.Db, variantDto.ProductId, &variant.ID, *variantDto.WarehouseId,
+			db.StockMovementTypeReceipt, variantDto.Stock, "Opening stock", nil, &variantDto.UserId, nil)...).Exec(ctx)
+		if err != nil {
+			return &data.WebResponse{
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+				Data:    nil,
+			}
+		}
+	}
+
+	repository.TouchProduct(ctx, p.Db, variantDto.ProductId)
+	p.Cache.Invalidate(ctx, cache.Products)
+
+	err = repository.AuditLogs(ctx, p.Db, variantDto.UserId, "Product Variant Created", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Product variant created",
+		Data:    nil,
+	}
+}
+
+// UpdateVariant changes a variant's SKU, options and price. Its stock is moved through stock movements.
+func (p *VariantService) UpdateVariant(ctx context.Context, variantDto *model.ProductVariantModel) *data.WebResponse {
+	validator := helpers.RequestValidators(variantDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+
+	existingVariant, _ := p.Db.ProductVariant.FindUnique(db.ProductVariant.ID.Equals(variantDto.VariantId)).Exec(ctx)
+	if existingVariant == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product variant not found",
+			Data:    nil,
+		}
+	}
+	if existingVariant.Sku != variantDto.Sku && repository.ExistingVariantBySku(ctx, p.Db, variantDto.Sku) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Variant SKU already exists",
+			Data:    nil,
+		}
+	}
+
+	product, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(existingVariant.ProductID), db.Product.DeletedAt.IsNull()).With(
+		db.Product.Options.Fetch(),
+		db.Product.Variants.Fetch(),
+	).Exec(ctx)
+	if product == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+	options, err := normaliseVariantOptions(product.Options(), variantDto.Options)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	key := variantKey(options)
+	for _, variant := range product.Variants() {
+		if variant.ID != existingVariant.ID && variantKey(decodeVariantOptions(variant.Options)) == key {
+			return &data.WebResponse{
+				Code:    http.StatusConflict,
+				Message: fmt.Sprintf("Variant %s already exists for this product", key),
+				Data:    nil,
+			}
+		}
+	}
+
+	optionsJson, _ := json.Marshal(options)
+	_, err = p.Db.ProductVariant.FindUnique(db.ProductVariant.ID.Equals(variantDto.VariantId)).Update(
+		db.ProductVariant.Sku.Set(variantDto.Sku),
+		db.ProductVariant.Options.Set(optionsJson),
+		db.ProductVariant.Price.SetOptional(variantPrice(variantDto.Price)),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	repository.TouchProduct(ctx, p.Db, existingVariant.ProductID)
+	p.Cache.Invalidate(ctx, cache.Products)
+
+	err = repository.AuditLogs(ctx, p.Db, variantDto.UserId, "Product Variant Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Product variant updated",
+		Data:    nil,
+	}
+}
+
+func (p *VariantService) DeleteVariant(ctx context.Context, variantId int, userId int) *data.WebResponse {
+	existingVariant, _ := p.Db.ProductVariant.FindUnique(db.ProductVariant.ID.Equals(variantId)).Exec(ctx)
+	if existingVariant == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product variant not found",
+			Data:    nil,
+		}
+	}
+
+	orderItem, _ := p.Db.OrderItem.FindFirst(db.OrderItem.VariantID.Equals(variantId)).Exec(ctx)
+	if orderItem != nil {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Product variant has been ordered and cannot be deleted",
+			Data:    nil,
+		}
+	}
+	movement, _ := p.Db.StockMovement.FindFirst(db.StockMovement.VariantID.Equals(variantId)).Exec(ctx)
+	if movement != nil {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Product variant has stock history and cannot be deleted",
+			Data:    nil,
+		}
+	}
+	transferItem, _ := p.Db.TransferOrderItem.FindFirst(db.TransferOrderItem.VariantID.Equals(variantId)).Exec(ctx)
+	if transferItem != nil {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Product variant is on a stock transfer and cannot be deleted",
+			Data:    nil,
+		}
+	}
+	if _, err := p.Db.Inventory.FindMany(db.Inventory.VariantID.Equals(variantId)).Delete().Exec(ctx); err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	_, err := p.Db.ProductVariant.FindUnique(db.ProductVariant.ID.Equals(variantId)).Delete().Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	repository.TouchProduct(ctx, p.Db, existingVariant.ProductID)
+	p.Cache.Invalidate(ctx, cache.Products)
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Product Variant Deleted", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Product variant deleted",
+		Data:    nil,
+	}
+}
+
+func (p *VariantService) GetVariantMatrix(ctx context.Context, productId int) *data.WebResponse {
+	product, _ := p.Db.Product.FindFirst(db.Product.ID.Equals(productId), db.Product.DeletedAt.IsNull()).With(
+		db.Product.Options.Fetch(),
+		db.Product.Variants.Fetch(),
+	).Exec(ctx)
+	if product == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Product not found",
+			Data:    nil,
+		}
+	}
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Product variants",
+		Data: model.VariantMatrixResponse{
+			ProductId: product.ID,
+			Options:   optionResponses(product.Options()),
+			Variants:  variantResponses(product.Price, product.Variants()),
+		},
+	}
+}
+
+// normaliseVariantOptions checks that a variant picks exactly one allowed value for every option axis of its product.
+// checkVariantsAllowed refuses variants for bundles and bundle components. A bundle's stock is derived from its
+// components' product stock, which a product with variants does not keep.
+func checkVariantsAllowed(ctx context.Context, dbClient *db.PrismaClient, product db.ProductModel) *data.WebResponse {
+	if product.Type == db.ProductTypeBundle {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "A bundle cannot have variants",
+			Data:    nil,
+		}
+	}
+	if repository.ProductIsBundleComponent(ctx, dbClient, product.ID) {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Product is a component of a bundle, bundle components cannot have variants",
+			Data:    nil,
+		}
+	}
+	return nil
+}
+
+func normaliseVariantOptions(productOptions []db.ProductOptionModel, options map[string]string) (map[string]string, error) {
+	if len(productOptions) == 0 {
+		return nil, fmt.Errorf("product has no options defined")
+	}
+	normalised := map[string]string{}
+	for name, value := range options {
+		normalised[strings.ToLower(name)] = value
+	}
+	if len(normalised) != len(productOptions) {
+		return nil, fmt.Errorf("variant must set a value for each of the %d product options", len(productOptions))
+	}
+	for _, option := range productOptions {
+		value, ok := normalised[option.Name]
+		if !ok {
+			return nil, fmt.Errorf("variant is missing a value for option %s", option.Name)
+		}
+		allowed := false
+		for _, optionValue := range option.Values {
+			if optionValue == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("%s is not an allowed value for option %s", value, option.Name)
+		}
+	}
+	return normalised, nil
+}
+
+func decodeVariantOptions(raw db.JSON) map[string]string {
+	options := map[string]string{}
+	_ = json.Unmarshal(raw, &options)
+	return options
+}
+
+// variantKey builds a stable identifier for an option combination, e.g. "colour=red/size=m".
+func variantKey(options map[string]string) string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+strings.ToLower(options[name]))
+	}
+	return strings.Join(parts, "/")
+}
+
+func variantPrice(price *float32) *float64 {
+	if price == nil {
+		return nil
+	}
+	value := float64(*price)
+	return &value
+}
+
+func optionResponses(options []db.ProductOptionModel) []model.ProductOptionResponse {
+	var OptionResponses []model.ProductOptionResponse
+	for _, option := range options {
+		OptionResponses = append(OptionResponses, model.ProductOptionResponse{
+			Name:   option.Name,
+			Values: option.Values,
+		})
+	}
+	return OptionResponses
+}
+
+func variantResponses(productPrice float64, variants []db.ProductVariantModel) []model.ProductVariantResponse {
+	var VariantResponses []model.ProductVariantResponse
+	for _, variant := range variants {
+		price := float32(productPrice)
+		var priceOverride *float32
+		if override, ok := variant.Price(); ok {
+			price = float32(override)
+			priceOverride = &price
+		}
+		VariantResponses = append(VariantResponses, model.ProductVariantResponse{
+			Id:            variant.ID,
+			Sku:           variant.Sku,
+			Options:       decodeVariantOptions(variant.Options),
+			Price:         price,
+			PriceOverride: priceOverride,
+			Stock:         variant.Stock,
+			CreatedAt:     variant.CreatedAt,
+		})
+	}
+	return VariantResponses
+}
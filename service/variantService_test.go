@@ -0,0 +1,78 @@
+package service
+
+import (
+	"Enterprise/prisma/db"
+	"reflect"
+	"testing"
+)
+
+func TestNormaliseVariantOptions(t *testing.T) {
+	option := func(name string, values ...string) db.ProductOptionModel {
+		return db.ProductOptionModel{InnerProductOption: db.InnerProductOption{Name: name, Values: values}}
+	}
+	productOptions := []db.ProductOptionModel{
+		option("colour", "red", "blue"),
+		option("size", "S", "M"),
+	}
+	tests := []struct {
+		name           string
+		productOptions []db.ProductOptionModel
+		options        map[string]string
+		want           map[string]string
+		wantErr        string
+	}{
+		{
+			name:           "names are lowercased",
+			productOptions: productOptions,
+			options:        map[string]string{"Colour": "red", "SIZE": "M"},
+			want:           map[string]string{"colour": "red", "size": "M"},
+		},
+		{
+			name:           "product without options",
+			productOptions: nil,
+			options:        map[string]string{"colour": "red"},
+			wantErr:        "product has no options defined",
+		},
+		{
+			name:           "an option left out",
+			productOptions: productOptions,
+			options:        map[string]string{"colour": "red"},
+			wantErr:        "variant must set a value for each of the 2 product options",
+		},
+		{
+			name:           "an unknown option",
+			productOptions: productOptions,
+			options:        map[string]string{"colour": "red", "fit": "slim"},
+			wantErr:        "variant is missing a value for option size",
+		},
+		{
+			name:           "a value the option does not allow",
+			productOptions: productOptions,
+			options:        map[string]string{"colour": "green", "size": "M"},
+			wantErr:        "green is not an allowed value for option colour",
+		},
+		{
+			name:           "values are matched exactly",
+			productOptions: productOptions,
+			options:        map[string]string{"colour": "red", "size": "m"},
+			wantErr:        "m is not an allowed value for option size",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := normaliseVariantOptions(test.productOptions, test.options)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package service
+
+import (
+	"Enterprise/data"
+	"Enterprise/helpers"
+	"Enterprise/model"
+	"Enterprise/prisma/db"
+	"Enterprise/repository"
+	"fmt"
+	"golang.org/x/net/context"
+	"net/http"
+	"strings"
+)
+
+type WarehouseService struct {
+	Db *db.PrismaClient
+}
+
+func NewWarehouseService(db *db.PrismaClient) *WarehouseService {
+	return &WarehouseService{Db: db}
+}
+
+func (p *WarehouseService) CreateWarehouse(ctx context.Context, warehouseDto *model.WarehouseModel) *data.WebResponse {
+	validator := helpers.RequestValidators(warehouseDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	existing, _ := p.Db.Warehouse.FindUnique(db.Warehouse.Name.Equals(warehouseDto.Name)).Exec(ctx)
+	if existing != nil {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Warehouse name already exists",
+			Data:    nil,
+		}
+	}
+
+	warehouse, err := p.Db.Warehouse.CreateOne(
+		db.Warehouse.Name.Set(warehouseDto.Name),
+		db.Warehouse.Location.Set(warehouseDto.Location),
+		db.Warehouse.Address.SetIfPresent(warehouseDto.Address),
+		db.Warehouse.City.SetIfPresent(warehouseDto.City),
+		db.Warehouse.PostalCode.SetIfPresent(warehouseDto.PostalCode),
+		db.Warehouse.Country.SetIfPresent(warehouseDto.Country),
+		db.Warehouse.Active.SetIfPresent(warehouseDto.Active),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, warehouseDto.UserId, "Warehouse Created", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusCreated,
+		Message: "Warehouse created",
+		Data: struct {
+			Id int `json:"id"`
+		}{
+			Id: warehouse.ID,
+		},
+	}
+}
+
+// UpdateWarehouse replaces the details of a warehouse. Setting active to false stops it from
+// taking new stock without losing what it still holds.
+func (p *WarehouseService) UpdateWarehouse(ctx context.Context, warehouseDto *model.WarehouseModel) *data.WebResponse {
+	validator := helpers.RequestValidators(warehouseDto)
+	if validator != nil {
+		return &data.WebResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Validation error",
+			Data:    validator.Error(),
+		}
+	}
+	warehouseExist, _ := p.Db.Warehouse.FindUnique(db.Warehouse.ID.Equals(warehouseDto.WarehouseId)).Exec(ctx)
+	if warehouseExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Warehouse not found",
+			Data:    nil,
+		}
+	}
+	existing, _ := p.Db.Warehouse.FindUnique(db.Warehouse.Name.Equals(warehouseDto.Name)).Exec(ctx)
+	if existing != nil && existing.ID != warehouseDto.WarehouseId {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Warehouse name already exists",
+			Data:    nil,
+		}
+	}
+
+	_, err := p.Db.Warehouse.FindUnique(db.Warehouse.ID.Equals(warehouseDto.WarehouseId)).Update(
+		db.Warehouse.Name.Set(warehouseDto.Name),
+		db.Warehouse.Location.Set(warehouseDto.Location),
+		db.Warehouse.Address.SetOptional(warehouseDto.Address),
+		db.Warehouse.City.SetOptional(warehouseDto.City),
+		db.Warehouse.PostalCode.SetOptional(warehouseDto.PostalCode),
+		db.Warehouse.Country.SetOptional(warehouseDto.Country),
+		db.Warehouse.Active.SetIfPresent(warehouseDto.Active),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, warehouseDto.UserId, "Warehouse Updated", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Warehouse updated",
+		Data:    nil,
+	}
+}
+
+// DeleteWarehouse removes a warehouse that never held stock. One with a stock history must be deactivated instead.
+func (p *WarehouseService) DeleteWarehouse(ctx context.Context, warehouseId int, userId int) *data.WebResponse {
+	warehouseExist, _ := p.Db.Warehouse.FindUnique(db.Warehouse.ID.Equals(warehouseId)).Exec(ctx)
+	if warehouseExist == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Warehouse not found",
+			Data:    nil,
+		}
+	}
+	if reference := p.warehouseReference(ctx, warehouseId); reference != "" {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: fmt.Sprintf("Warehouse has %s, deactivate it instead", reference),
+			Data:    nil,
+		}
+	}
+
+	err := p.Db.Prisma.Transaction(
+		p.Db.Inventory.FindMany(db.Inventory.WarehouseID.Equals(warehouseId)).Delete().Tx(),
+		p.Db.Warehouse.FindUnique(db.Warehouse.ID.Equals(warehouseId)).Delete().Tx(),
+	).Exec(ctx)
+	if err != nil && strings.Contains(err.Error(), "Foreign key constraint") {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Warehouse was put to use by another request, deactivate it instead",
+			Data:    nil,
+		}
+	}
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	err = repository.AuditLogs(ctx, p.Db, userId, "Warehouse Deleted", "This action was performed by ")
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Warehouse deleted",
+		Data:    nil,
+	}
+}
+
+// warehouseReference names what still refers to a warehouse, empty when it can be deleted. Anything created
+// after the check makes the delete fail on the foreign keys instead.
+func (p *WarehouseService) warehouseReference(ctx context.Context, warehouseId int) string {
+	if movement, _ := p.Db.StockMovement.FindFirst(db.StockMovement.WarehouseID.Equals(warehouseId)).Exec(ctx); movement != nil {
+		return "stock history"
+	}
+	if purchaseOrder, _ := p.Db.PurchaseOrder.FindFirst(db.PurchaseOrder.WarehouseID.Equals(warehouseId)).Exec(ctx); purchaseOrder != nil {
+		return "purchase orders"
+	}
+	transfer, _ := p.Db.TransferOrder.FindFirst(db.TransferOrder.Or(
+		db.TransferOrder.SourceWarehouseID.Equals(warehouseId),
+		db.TransferOrder.DestinationWarehouseID.Equals(warehouseId),
+	)).Exec(ctx)
+	if transfer != nil {
+		return "stock transfers"
+	}
+	if stockTake, _ := p.Db.StockTake.FindFirst(db.StockTake.WarehouseID.Equals(warehouseId)).Exec(ctx); stockTake != nil {
+		return "stock takes"
+	}
+	if reservation, _ := p.Db.StockReservation.FindFirst(db.StockReservation.WarehouseID.Equals(warehouseId)).Exec(ctx); reservation != nil {
+		return "stock reservations"
+	}
+	return ""
+}
+
+func (p *WarehouseService) GetWarehouseById(ctx context.Context, warehouseId int) *data.WebResponse {
+	warehouse, _ := p.Db.Warehouse.FindUnique(db.Warehouse.ID.Equals(warehouseId)).Exec(ctx)
+	if warehouse == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Warehouse not found",
+			Data:    nil,
+		}
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Warehouse found",
+		Data:    warehouseResponse(*warehouse),
+	}
+}
+
+// GetAllWarehouses lists warehouses, only the active ones when activeOnly is set.
+func (p *WarehouseService) GetAllWarehouses(ctx context.Context, activeOnly bool) *data.WebResponse {
+	var filters []db.WarehouseWhereParam
+	if activeOnly {
+		filters = append(filters, db.Warehouse.Active.Equals(true))
+	}
+	warehouses, err := p.Db.Warehouse.FindMany(filters...).OrderBy(
+		db.Warehouse.Name.Order(db.SortOrderAsc),
+	).Exec(ctx)
+	if err != nil {
+		return &data.WebResponse{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+			Data:    nil,
+		}
+	}
+
+	var WarehouseResponses []model.WarehouseResponse
+	for _, warehouse := range warehouses {
+		WarehouseResponses = append(WarehouseResponses, warehouseResponse(warehouse))
+	}
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Warehouses",
+		Data:    WarehouseResponses,
+	}
+}
+
+// GetWarehouseStock summarises what a warehouse holds, valued at current product or variant prices.
+func (p *WarehouseService) GetWarehouseStock(ctx context.Context, warehouseId int) *data.WebResponse {
+	warehouse, _ := p.Db.Warehouse.FindUnique(db.Warehouse.ID.Equals(warehouseId)).With(
+		db.Warehouse.Inventory.Fetch().With(
+			db.Inventory.Product.Fetch(),
+			db.Inventory.Variant.Fetch(),
+		).OrderBy(db.Inventory.ProductID.Order(db.SortOrderAsc)),
+	).Exec(ctx)
+	if warehouse == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Warehouse not found",
+			Data:    nil,
+		}
+	}
+
+	response := model.WarehouseStockResponse{
+		WarehouseId: warehouse.ID,
+		Name:        warehouse.Name,
+		Items:       []model.WarehouseStockItemResponse{},
+	}
+	products := map[int]bool{}
+	for _, inventory := range warehouse.Inventory() {
+		price := inventory.Product().Price
+		item := model.WarehouseStockItemResponse{
+			ProductId:   inventory.ProductID,
+			ProductName: inventory.Product().Name,
+			Quantity:    inventory.Quantity,
+		}
+		if variant, ok := inventory.Variant(); ok {
+			item.VariantId = &variant.ID
+			item.Sku = &variant.Sku
+			if override, ok := variant.Price(); ok {
+				price = override
+			}
+		}
+		item.Value = roundMoney(price * float64(inventory.Quantity))
+		if bin, ok := inventory.Bin(); ok {
+			item.Bin = &bin
+		}
+
+		products[inventory.ProductID] = true
+		response.TotalQuantity += item.Quantity
+		response.TotalValue += item.Value
+		response.Items = append(response.Items, item)
+	}
+	response.ProductCount = len(products)
+	response.TotalValue = roundMoney(response.TotalValue)
+
+	return &data.WebResponse{
+		Code:    http.StatusOK,
+		Message: "Warehouse stock",
+		Data:    response,
+	}
+}
+
+func warehouseResponse(warehouse db.WarehouseModel) model.WarehouseResponse {
+	response := model.WarehouseResponse{
+		Id:        warehouse.ID,
+		Name:      warehouse.Name,
+		Location:  warehouse.Location,
+		Active:    warehouse.Active,
+		CreatedAt: warehouse.CreatedAt,
+		UpdatedAt: warehouse.UpdatedAt,
+	}
+	if address, ok := warehouse.Address(); ok {
+		response.Address = &address
+	}
+	if city, ok := warehouse.City(); ok {
+		response.City = &city
+	}
+	if postalCode, ok := warehouse.PostalCode(); ok {
+		response.PostalCode = &postalCode
+	}
+	if country, ok := warehouse.Country(); ok {
+		response.Country = &country
+	}
+	return response
+}
+
+// checkWarehouse makes sure stock can be booked into a warehouse: it must exist and be active.
+func checkWarehouse(ctx context.Context, dbClient *db.PrismaClient, warehouseId int) *data.WebResponse {
+	warehouse, _ := dbClient.Warehouse.FindUnique(db.Warehouse.ID.Equals(warehouseId)).Exec(ctx)
+	if warehouse == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Warehouse not found",
+			Data:    nil,
+		}
+	}
+	if !warehouse.Active {
+		return &data.WebResponse{
+			Code:    http.StatusConflict,
+			Message: "Warehouse is not active",
+			Data:    nil,
+		}
+	}
+	return nil
+}
+
+// checkVariant makes sure stock booked against a variant is booked against one of the product's own variants.
+func checkVariant(ctx context.Context, dbClient *db.PrismaClient, productId int, variantId *int) *data.WebResponse {
+	if variantId == nil {
+		return nil
+	}
+	variant, _ := dbClient.ProductVariant.FindFirst(
+		db.ProductVariant.ID.Equals(*variantId),
+		db.ProductVariant.ProductID.Equals(productId),
+	).Exec(ctx)
+	if variant == nil {
+		return &data.WebResponse{
+			Code:    http.StatusNotFound,
+			Message: "Variant not found",
+			Data:    nil,
+		}
+	}
+	return nil
+}
+
+// inventoryResponses lists a product's stock per warehouse. inventory must have been fetched with its Warehouse relation.
+func inventoryResponses(inventory []db.InventoryModel) []model.InventoryResponse {
+	var responses []model.InventoryResponse
+	for _, item := range inventory {
+		response := model.InventoryResponse{
+			WarehouseId:   item.WarehouseID,
+			WarehouseName: item.Warehouse().Name,
+			Quantity:      item.Quantity,
+			Reserved:      item.Reserved,
+			Available:     item.Quantity - item.Reserved,
+		}
+		if variantId, ok := item.VariantID(); ok {
+			response.VariantId = &variantId
+		}
+		if bin, ok := item.Bin(); ok {
+			response.Bin = &bin
+		}
+		if reorderPoint, ok := item.ReorderPoint(); ok {
+			response.ReorderPoint = &reorderPoint
+		}
+		if reorderQuantity, ok := item.ReorderQuantity(); ok {
+			response.ReorderQuantity = &reorderQuantity
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// inventoryAvailable is the stock across warehouses that is on hand and not reserved.
+func inventoryAvailable(inventory []db.InventoryModel) int {
+	available := 0
+	for _, item := range inventory {
+		available += item.Quantity - item.Reserved
+	}
+	return available
+}
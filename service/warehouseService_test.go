@@ -0,0 +1,33 @@
+package service
+
+import (
+	"Enterprise/prisma/db"
+	"testing"
+)
+
+func TestInventoryResponses(t *testing.T) {
+	variantId, bin := 7, "A-01"
+	warehouse := &db.WarehouseModel{InnerWarehouse: db.InnerWarehouse{ID: 2, Name: "Main"}}
+	inventory := []db.InventoryModel{
+		{
+			InnerInventory:     db.InnerInventory{ProductID: 1, WarehouseID: 2, Quantity: 10, Reserved: 4, Bin: &bin},
+			RelationsInventory: db.RelationsInventory{Warehouse: warehouse},
+		},
+		{
+			InnerInventory:     db.InnerInventory{ProductID: 1, VariantID: &variantId, WarehouseID: 2, Quantity: 3},
+			RelationsInventory: db.RelationsInventory{Warehouse: warehouse},
+		},
+	}
+
+	responses := inventoryResponses(inventory)
+	if len(responses) != 2 {
+		t.Fatalf("got %d records, want 2", len(responses))
+	}
+	own, variant := responses[0], responses[1]
+	if own.WarehouseName != "Main" || own.Available != 6 || own.VariantId != nil || own.Bin == nil || *own.Bin != bin {
+		t.Errorf("product's own stock = %+v, want 6 available in bin %s of Main", own, bin)
+	}
+	if variant.VariantId == nil || *variant.VariantId != variantId || variant.Available != 3 || variant.Bin != nil {
+		t.Errorf("variant stock = %+v, want 3 available of variant %d without a bin", variant, variantId)
+	}
+}